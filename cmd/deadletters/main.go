@@ -0,0 +1,69 @@
+// Command deadletters lists messages the signal engine could not deliver.
+//
+// Usage:
+//
+//	deadletters [-file deadletters.jsonl] [-symbol EURUSD] [-json]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/infrastructure"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "deadletters:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("deadletters", flag.ContinueOnError)
+	path := fs.String("file", "deadletters.jsonl", "dead-letter file written by the signal engine")
+	symbol := fs.String("symbol", "", "only show dead letters for this symbol")
+	asJSON := fs.Bool("json", false, "print dead letters as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	letters, err := infrastructure.NewFileDeadLetterStore(*path).List(context.Background())
+	if err != nil {
+		return err
+	}
+	if *symbol != "" {
+		kept := letters[:0]
+		for _, dl := range letters {
+			if entity.SymbolKey(dl.Symbol) == entity.SymbolKey(*symbol) {
+				kept = append(kept, dl)
+			}
+		}
+		letters = kept
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if letters == nil {
+			letters = []ports.DeadLetter{}
+		}
+		return enc.Encode(letters)
+	}
+	for _, dl := range letters {
+		fmt.Fprintf(w, "%s  %s  attempts=%d  error=%s\n", dl.Time.Format(time.RFC3339), dl.Symbol, dl.Attempts, dl.Error)
+		for _, m := range dl.Messages {
+			fmt.Fprintf(w, "    %s\n", strings.ReplaceAll(m, "\n", "\n    "))
+		}
+	}
+	fmt.Fprintf(w, "%d dead letter(s)\n", len(letters))
+	return nil
+}
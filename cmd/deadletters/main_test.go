@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/infrastructure"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deadletters.jsonl")
+	store := infrastructure.NewFileDeadLetterStore(path)
+	at := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	for _, dl := range []ports.DeadLetter{
+		{Time: at, Symbol: "EURUSD", Messages: []string{"EURUSD UP", "line one\nline two"}, Attempts: 3, Error: "telegram unavailable"},
+		{Time: at.Add(time.Minute), Symbol: "GBPUSD", Messages: []string{"GBPUSD DOWN"}, Attempts: 1, Error: "chat not found"},
+	} {
+		if err := store.Store(context.Background(), dl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	corrupt := filepath.Join(dir, "corrupt.jsonl")
+	if err := os.WriteFile(corrupt, []byte("{\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "all",
+			args: []string{"-file", path},
+			want: "2024-01-03T10:00:00Z  EURUSD  attempts=3  error=telegram unavailable\n" +
+				"    EURUSD UP\n" +
+				"    line one\n    line two\n" +
+				"2024-01-03T10:01:00Z  GBPUSD  attempts=1  error=chat not found\n" +
+				"    GBPUSD DOWN\n" +
+				"2 dead letter(s)\n",
+		},
+		{
+			name: "symbol in another spelling",
+			args: []string{"-file", path, "-symbol", "gbp/usd"},
+			want: "2024-01-03T10:01:00Z  GBPUSD  attempts=1  error=chat not found\n" +
+				"    GBPUSD DOWN\n" +
+				"1 dead letter(s)\n",
+		},
+		{
+			name: "json",
+			args: []string{"-file", path, "-symbol", "GBPUSD", "-json"},
+			want: "[\n  {\n" +
+				"    \"Time\": \"2024-01-03T10:01:00Z\",\n" +
+				"    \"Symbol\": \"GBPUSD\",\n" +
+				"    \"Messages\": [\n      \"GBPUSD DOWN\"\n    ],\n" +
+				"    \"Attempts\": 1,\n" +
+				"    \"Error\": \"chat not found\"\n" +
+				"  }\n]\n",
+		},
+		{name: "json without matches", args: []string{"-file", path, "-symbol", "USDJPY", "-json"}, want: "[]\n"},
+		{name: "missing file", args: []string{"-file", filepath.Join(dir, "missing.jsonl")}, want: "0 dead letter(s)\n"},
+		{name: "corrupt file", args: []string{"-file", corrupt}, wantErr: true},
+		{name: "unknown flag", args: []string{"-verbose"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(tt.args, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && out.String() != tt.want {
+				t.Fatalf("expected\n%s\ngot\n%s", tt.want, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Command livereport compares the signals the engine published live with the
+// signals a backtest raises on the candles it recorded over the same period.
+// Pass it the files and filter settings the engine runs with; each backtest
+// signal is settled after its own TTL, as the engine does.
+//
+// Usage:
+//
+//	livereport [-candles candles.jsonl] [-outcomes outcomes.jsonl]
+//	           [-scorers scorers.json] [-instruments instruments.json]
+//	           [-market-hours] [-sessions London,NewYork] [-skip-after-open 0]
+//	           [-news calendar.csv] [-news-before 15m] [-news-after 15m] [-news-tag]
+//	           [-min-atr 0] [-max-atr 0] [-max-spread 0]
+//	           [-cooldown 0] [-max-signals 0] [-signal-window 1h] [-suppress-within-ttl]
+//	           [-min-move-pips 0] [-from RFC3339] [-to RFC3339] [-json]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/infrastructure"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "livereport:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("livereport", flag.ContinueOnError)
+	candlesPath := fs.String("candles", "candles.jsonl", "candle file written by the signal engine")
+	outcomesPath := fs.String("outcomes", "outcomes.jsonl", "outcome file written by the signal engine")
+	scorers := fs.String("scorers", "", "scorer parameter file used live")
+	instruments := fs.String("instruments", "", "instrument file used for pips, sessions and news currencies")
+	marketHours := fs.Bool("market-hours", false, "skip bars outside market hours and on holidays, as with a live session filter")
+	sessions := fs.String("sessions", "", "comma-separated sessions allowed live; implies -market-hours")
+	skipAfterOpen := fs.Duration("skip-after-open", 0, "session filter warm-up after a session opens; implies -market-hours")
+	news := fs.String("news", "", "economic calendar file used for the live news blackout")
+	newsBefore := fs.Duration("news-before", 15*time.Minute, "news blackout before a release")
+	newsAfter := fs.Duration("news-after", 15*time.Minute, "news blackout after a release")
+	newsTag := fs.Bool("news-tag", false, "tag signals around releases instead of suppressing them")
+	minATR := fs.Float64("min-atr", 0, "regime filter minimum ATR, in pips for registered instruments")
+	maxATR := fs.Float64("max-atr", 0, "regime filter maximum ATR, in pips for registered instruments")
+	maxSpread := fs.Float64("max-spread", 0, "regime filter maximum spread, in pips for registered instruments")
+	cooldown := fs.Duration("cooldown", 0, "throttle cooldown per symbol and direction")
+	maxSignals := fs.Int("max-signals", 0, "throttle cap on signals across symbols per -signal-window")
+	signalWindow := fs.Duration("signal-window", time.Hour, "window for -max-signals")
+	suppressWithinTTL := fs.Bool("suppress-within-ttl", false, "throttle signals while an earlier one on the symbol is live")
+	minMovePips := fs.Float64("min-move-pips", 0, "settle smaller moves as NEUTRAL, as configured live")
+	fromFlag := fs.String("from", "", "first signal bar to compare (RFC 3339); defaults to the first bar the backtest can score")
+	toFlag := fs.String("to", "", "end of the period, exclusive (RFC 3339); defaults to the last bar the backtest can settle")
+	asJSON := fs.Bool("json", false, "print the comparison as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := infrastructure.LoadCandleFile(*candlesPath)
+	if err != nil {
+		return err
+	}
+	opts := usecase.BacktestOptions{MinMovePips: *minMovePips, SignalTTL: true}
+	if *instruments != "" {
+		if opts.Instruments, err = infrastructure.LoadInstruments(*instruments); err != nil {
+			return err
+		}
+	}
+	if *scorers != "" {
+		if opts.Scorers, err = infrastructure.LoadScorerParams(*scorers, opts.Instruments); err != nil {
+			return err
+		}
+	}
+	if *marketHours || *sessions != "" || *skipAfterOpen > 0 {
+		if *instruments == "" {
+			return fmt.Errorf("session filter requires -instruments")
+		}
+		if opts.Calendar, err = infrastructure.LoadSessionCalendar(*instruments); err != nil {
+			return err
+		}
+		opts.SessionFilter = entity.SessionFilter{Sessions: splitList(*sessions), SkipAfterOpen: *skipAfterOpen}
+	}
+	if *news != "" {
+		cal, err := infrastructure.NewFileEconomicCalendar(*news)
+		if err != nil {
+			return err
+		}
+		cfg := usecase.NewsBlackoutConfig{Before: *newsBefore, After: *newsAfter}
+		if *newsTag {
+			cfg.Mode = usecase.BlackoutTag
+		}
+		opts.NewsBlackout = usecase.NewNewsBlackout(cal, opts.Instruments, cfg)
+	}
+	if limits := (usecase.RegimeLimits{MinATR: *minATR, MaxATR: *maxATR, MaxSpread: *maxSpread}); limits != (usecase.RegimeLimits{}) {
+		if opts.RegimeFilter, err = usecase.NewRegimeFilter(usecase.RegimeFilterConfig{Default: limits, Instruments: opts.Instruments}); err != nil {
+			return err
+		}
+	}
+	if *cooldown > 0 || *maxSignals > 0 || *suppressWithinTTL {
+		opts.Throttle = usecase.NewSignalThrottle(usecase.ThrottleConfig{
+			Cooldown:          *cooldown,
+			MaxSignals:        *maxSignals,
+			Window:            *signalWindow,
+			SuppressWithinTTL: *suppressWithinTTL,
+		})
+	}
+
+	from, to := recordedPeriod(data, opts)
+	if from, err = parseTime(*fromFlag, from); err != nil {
+		return err
+	}
+	if to, err = parseTime(*toFlag, to); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	live, err := infrastructure.NewFileOutcomeStore(*outcomesPath).List(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	backtest := usecase.BacktestSignalsWithOptions(ctx, logger, data, 0, 0, opts)
+	cmp := usecase.CompareLive(live, backtest, from, to)
+
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cmp)
+	}
+	printComparison(w, cmp, from, to)
+	return nil
+}
+
+// recordedPeriod returns the signal bars the backtest can both score and
+// settle on every symbol in data, so that no symbol counts its unrecorded
+// bars as extra live signals. Symbols too short to score are ignored.
+func recordedPeriod(data map[string][]ports.Candle, opts usecase.BacktestOptions) (from, to time.Time) {
+	for symbol, candles := range data {
+		warmup := opts.WarmupBars(symbol)
+		if len(candles) < warmup {
+			continue
+		}
+		if first := candles[warmup-1].Time; first.After(from) {
+			from = first
+		}
+		if last := candles[len(candles)-1].Time.Add(time.Minute - longestTTL(opts.Scorers.For(symbol))); to.IsZero() || last.Before(to) {
+			to = last
+		}
+	}
+	return from, to
+}
+
+// longestTTL returns the longest TTL of the signals raised under p.
+func longestTTL(p entity.ScorerParams) time.Duration {
+	return max(p.RSIDivergence.TTL, p.EMAInteraction.TTL, p.Candlestick.TTL)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func parseTime(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return t, nil
+}
+
+func printComparison(w io.Writer, cmp usecase.LiveComparison, from, to time.Time) {
+	fmt.Fprintf(w, "Period:   %s to %s\n", from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "Live:     %s\n", summary(cmp.Live.Total, cmp.Live.Wins, cmp.Live.Losses, cmp.Live.Neutrals, cmp.Live.Accuracy))
+	fmt.Fprintf(w, "Backtest: %s\n", summary(cmp.Backtest.Total, cmp.Backtest.Wins, cmp.Backtest.Losses, cmp.Backtest.Neutrals, cmp.Backtest.Accuracy))
+	fmt.Fprintf(w, "Matched %d, missed %d, extra %d, settled differently %d, accuracy drift %+.1f pts\n",
+		len(cmp.Matched), len(cmp.Missed), len(cmp.Extra), cmp.Disagreements, cmp.AccuracyDrift*100)
+
+	if len(cmp.BySymbol) > 0 {
+		symbols := make([]string, 0, len(cmp.BySymbol))
+		for s := range cmp.BySymbol {
+			symbols = append(symbols, s)
+		}
+		sort.Strings(symbols)
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SYMBOL\tLIVE\tBACKTEST\tMATCHED\tMISSED\tEXTRA\tDIFFERENT\tLIVE WIN%\tBACKTEST WIN%")
+		for _, s := range symbols {
+			st := cmp.BySymbol[s]
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.0f%%\t%.0f%%\n", s, st.Live.Total, st.Backtest.Total,
+				st.Matched, st.Missed, st.Extra, st.Disagreements, st.Live.Accuracy*100, st.Backtest.Accuracy*100)
+		}
+		tw.Flush()
+	}
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s:\n", title)
+		for _, l := range lines {
+			fmt.Fprintf(w, "  %s\n", l)
+		}
+	}
+	var missed, extra, different []string
+	for _, r := range cmp.Missed {
+		missed = append(missed, fmt.Sprintf("%s  %s %s  backtest %s", r.SignalTime.UTC().Format(time.RFC3339), r.Symbol, r.Direction, r.Outcome))
+	}
+	for _, r := range cmp.Extra {
+		extra = append(extra, fmt.Sprintf("%s  %s %s  live %s", r.SignalTime.UTC().Format(time.RFC3339), r.Symbol, r.Direction, r.Outcome))
+	}
+	for _, m := range cmp.Matched {
+		if !m.Agrees() {
+			different = append(different, fmt.Sprintf("%s  %s %s  live %s at %g, backtest %s at %g", m.Live.SignalTime.UTC().Format(time.RFC3339),
+				m.Live.Symbol, m.Live.Direction, m.Live.Outcome, m.Live.ExitPrice, m.Backtest.Outcome, m.Backtest.ExitPrice))
+		}
+	}
+	section("Missed live", missed)
+	section("Extra live", extra)
+	section("Settled differently", different)
+}
+
+func summary(total, wins, losses, neutrals int, accuracy float64) string {
+	return fmt.Sprintf("%d signals, win rate %.0f%% (%d wins, %d losses, %d neutral)", total, accuracy*100, wins, losses, neutrals)
+}
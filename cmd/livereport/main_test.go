@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/infrastructure"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+// recordSeries writes 60 EURUSD bars with a bullish divergence to path and
+// returns them.
+func recordSeries(t *testing.T, path string) []ports.Candle {
+	t.Helper()
+	base := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	var candles []ports.Candle
+	for i := 0; i < 35; i++ {
+		candles = append(candles, ports.Candle{Open: 1, High: 1, Low: 1, Close: 1})
+	}
+	candles = append(candles, testutils.MakeCandles(true)...)
+	for i := 0; i < 5; i++ {
+		c := 1.1 + float64(i)*0.05
+		candles = append(candles, ports.Candle{Open: c, High: c, Low: c, Close: c})
+	}
+	rec := infrastructure.NewFileCandleRecorder(path)
+	for i := range candles {
+		candles[i].Symbol = "EURUSD"
+		candles[i].Time = base.Add(time.Duration(i) * time.Minute)
+		if err := rec.RecordCandle(context.Background(), candles[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return candles
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	candlesPath := filepath.Join(dir, "candles.jsonl")
+	candles := recordSeries(t, candlesPath)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	data := map[string][]ports.Candle{"EURUSD": candles}
+	bt := usecase.BacktestSignalsWithOptions(context.Background(), logger, data, 0, 0, usecase.BacktestOptions{SignalTTL: true})
+	if bt.Total < 2 {
+		t.Fatalf("expected several backtest signals, got %d", bt.Total)
+	}
+	// Publish the first backtest signal live with the same settlement.
+	r := bt.Results[0]
+	outcomesPath := filepath.Join(dir, "outcomes.jsonl")
+	if err := infrastructure.NewFileOutcomeStore(outcomesPath).Store(context.Background(), entity.SignalOutcome{
+		Signal:     entity.Signal{Symbol: r.Symbol, Direction: r.Direction, Time: r.SignalTime, TTL: r.ExpiryTime.Sub(r.EntryTime)},
+		EntryTime:  r.EntryTime,
+		ExpiryTime: r.ExpiryTime,
+		EntryPrice: r.EntryPrice,
+		ExitPrice:  r.ExitPrice,
+		Outcome:    r.Outcome,
+		Reason:     r.Reason,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	warmup := filepath.Join(dir, "scorers.json")
+	if err := os.WriteFile(warmup, []byte(`{"default":{"rsi_divergence":{"rsi_period":60}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{"-candles", candlesPath, "-outcomes", outcomesPath}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "settles with signal ttl",
+			args: files,
+			want: []string{
+				"Period:   2024-01-03T10:49:00Z to 2024-01-03T10:58:00Z\n",
+				fmt.Sprintf("Matched 1, missed %d, extra 0, settled differently 0", bt.Total-1),
+			},
+		},
+		{
+			name: "warm-up from scorer params",
+			args: append([]string{"-scorers", warmup}, files...),
+			want: []string{"Backtest: 0 signals", "Matched 0, missed 0, extra 1"},
+		},
+		{
+			name: "throttle",
+			args: append([]string{"-max-signals", "1"}, files...),
+			want: []string{"Backtest: 1 signals"},
+		},
+		{
+			name: "json",
+			args: append([]string{"-json"}, files...),
+			want: []string{`"Disagreements": 0`},
+		},
+		{name: "session filter without instruments", args: append([]string{"-market-hours"}, files...), wantErr: true},
+		{name: "missing candles", args: []string{"-candles", filepath.Join(dir, "missing.jsonl")}, wantErr: true},
+		{name: "missing scorer params", args: append([]string{"-scorers", filepath.Join(dir, "missing.json")}, files...), wantErr: true},
+		{name: "unknown flag", args: []string{"-nope"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := run(tt.args, &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRecordedPeriod(t *testing.T) {
+	base := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	bars := func(symbol string, start time.Duration, n int) []ports.Candle {
+		out := make([]ports.Candle, n)
+		for i := range out {
+			out[i] = ports.Candle{Symbol: symbol, Time: base.Add(start + time.Duration(i)*time.Minute)}
+		}
+		return out
+	}
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 3, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		data     map[string][]ports.Candle
+		from, to time.Time
+	}{
+		{
+			name: "single symbol",
+			data: map[string][]ports.Candle{"EURUSD": bars("EURUSD", 0, 60)},
+			from: at(10, 49), to: at(10, 58),
+		},
+		{
+			name: "overlap of symbols",
+			data: map[string][]ports.Candle{
+				"EURUSD": bars("EURUSD", 0, 60),
+				"GBPUSD": bars("GBPUSD", 5*time.Minute, 60),
+			},
+			from: at(10, 54), to: at(10, 58),
+		},
+		{
+			name: "symbol too short to score",
+			data: map[string][]ports.Candle{
+				"EURUSD": bars("EURUSD", 0, 60),
+				"GBPUSD": bars("GBPUSD", 30*time.Minute, 10),
+			},
+			from: at(10, 49), to: at(10, 58),
+		},
+		{name: "no data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := recordedPeriod(tt.data, usecase.BacktestOptions{})
+			if !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Errorf("recordedPeriod() = %v, %v; want %v, %v", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package delivery
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+// ChartOptions configures a ChartRenderer.
+type ChartOptions struct {
+	// Bars is the number of candles drawn. Defaults to 40.
+	Bars int
+	// Width and Height are the image size in pixels. They default to 800
+	// by 480 and are raised to at least 320 by 240.
+	Width  int
+	Height int
+	// Instruments formats the symbol and price labels.
+	Instruments *entity.InstrumentRegistry
+	// Scorers sets the indicator periods and setup rules per symbol. Pass
+	// the table given to the Orchestrator. Nil uses the defaults.
+	Scorers *entity.ScorerParamsTable
+}
+
+func (o ChartOptions) withDefaults() ChartOptions {
+	if o.Bars <= 0 {
+		o.Bars = 40
+	}
+	if o.Width <= 0 {
+		o.Width = 800
+	}
+	if o.Height <= 0 {
+		o.Height = 480
+	}
+	o.Width = max(o.Width, 320)
+	o.Height = max(o.Height, 240)
+	return o
+}
+
+// Chart colours, on a dark background.
+var (
+	chartBackground = color.RGBA{19, 23, 34, 255}
+	chartGrid       = color.RGBA{42, 46, 57, 255}
+	chartText       = color.RGBA{178, 181, 190, 255}
+	chartUp         = color.RGBA{38, 166, 154, 255}
+	chartDown       = color.RGBA{239, 83, 80, 255}
+	chartEMAFast    = color.RGBA{255, 152, 0, 255}
+	chartEMASlow    = color.RGBA{41, 98, 255, 255}
+	chartRSI        = color.RGBA{171, 71, 188, 255}
+	chartMarker     = color.RGBA{255, 235, 59, 255}
+)
+
+// Chart layout in pixels.
+const (
+	chartMargin = 8
+	chartAxis   = 84 // price labels right of the plot
+	chartHeader = 28
+	chartFooter = 22
+	chartGap    = 14 // between the price and RSI panels
+)
+
+// ChartRenderer draws signal charts as PNG images with the standard library
+// only: the last candles with the fast and slow EMAs, an RSI panel, the
+// signal's entry price and markers on the bars behind the signal, as found
+// by usecase.SignalSetup.
+type ChartRenderer struct {
+	opts ChartOptions
+}
+
+// NewChartRenderer returns a renderer for opts.
+func NewChartRenderer(opts ChartOptions) *ChartRenderer {
+	return &ChartRenderer{opts: opts.withDefaults()}
+}
+
+// Render draws s over candles, the buffer it was raised on, which ends with
+// the signal's bar. Indicators are computed over all of candles, so pass the
+// whole buffer rather than only the bars to draw.
+func (r *ChartRenderer) Render(s entity.Signal, candles []ports.Candle) ([]byte, error) {
+	if len(candles) < 2 {
+		return nil, fmt.Errorf("chart: need at least 2 candles, got %d", len(candles))
+	}
+	params := r.opts.Scorers.For(s.Symbol)
+	ch := chart{
+		canvas:  canvas{image.NewRGBA(image.Rect(0, 0, r.opts.Width, r.opts.Height))},
+		signal:  s,
+		candles: candles,
+		params:  params,
+		first:   max(0, len(candles)-r.opts.Bars),
+		reg:     r.opts.Instruments,
+	}
+	ch.rsi, ch.emaFast, ch.emaSlow = usecase.CalcIndicators(candles, params)
+	ch.layout()
+	ch.draw()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, ch.RGBA); err != nil {
+		return nil, fmt.Errorf("chart: encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// chart draws one signal chart.
+type chart struct {
+	canvas
+	signal           entity.Signal
+	candles          []ports.Candle
+	params           entity.ScorerParams
+	rsi              []float64
+	emaFast, emaSlow []float64
+	first            int // first candle drawn
+	reg              *entity.InstrumentRegistry
+	pricePanel       image.Rectangle
+	rsiPanel         image.Rectangle
+	slot             float64 // horizontal pixels per bar
+	lo, hi           float64 // price range of pricePanel
+	decimals         int     // price label precision without an instrument
+	dirColor         color.RGBA
+}
+
+// layout sizes the panels and the price range.
+func (ch *chart) layout() {
+	b := ch.Bounds()
+	plot := image.Rect(chartMargin, chartHeader, b.Dx()-chartAxis, b.Dy()-chartFooter)
+	split := plot.Min.Y + plot.Dy()*7/10
+	ch.pricePanel = image.Rect(plot.Min.X, plot.Min.Y, plot.Max.X, split-chartGap/2)
+	ch.rsiPanel = image.Rect(plot.Min.X, split+chartGap/2, plot.Max.X, plot.Max.Y)
+	ch.slot = float64(plot.Dx()) / float64(len(ch.candles)-ch.first)
+
+	ch.lo, ch.hi = math.Inf(1), math.Inf(-1)
+	include := func(v float64) {
+		ch.lo, ch.hi = min(ch.lo, v), max(ch.hi, v)
+	}
+	for i := ch.first; i < len(ch.candles); i++ {
+		include(ch.candles[i].Low)
+		include(ch.candles[i].High)
+		include(ch.emaFast[i])
+		include(ch.emaSlow[i])
+	}
+	if ch.signal.Price > 0 {
+		include(ch.signal.Price)
+	}
+	// Leave room for the markers above and below the bars.
+	const room = 32
+	pad := (ch.hi - ch.lo) * room / math.Max(float64(ch.pricePanel.Dy()-2*room), 1)
+	if pad == 0 {
+		pad = math.Max(math.Abs(ch.hi)*0.001, 1e-9)
+	}
+	ch.lo, ch.hi = ch.lo-pad, ch.hi+pad
+	step := (ch.hi - ch.lo) / 4
+	ch.decimals = min(max(0, int(math.Ceil(-math.Log10(step)))+1), 8)
+
+	ch.dirColor = chartUp
+	if ch.signal.Direction == "DOWN" {
+		ch.dirColor = chartDown
+	}
+}
+
+func (ch *chart) x(i int) int {
+	return ch.pricePanel.Min.X + int((float64(i-ch.first)+0.5)*ch.slot)
+}
+
+func (ch *chart) priceY(p float64) int {
+	f := (ch.hi - p) / (ch.hi - ch.lo)
+	return ch.pricePanel.Min.Y + int(math.Round(f*float64(ch.pricePanel.Dy()-1)))
+}
+
+func (ch *chart) rsiY(v float64) int {
+	f := (100 - v) / 100
+	return ch.rsiPanel.Min.Y + int(math.Round(f*float64(ch.rsiPanel.Dy()-1)))
+}
+
+func (ch *chart) formatPrice(p float64) string {
+	if inst, ok := ch.reg.Lookup(ch.signal.Symbol); ok {
+		return inst.FormatPrice(p)
+	}
+	return strconv.FormatFloat(p, 'f', ch.decimals, 64)
+}
+
+func (ch *chart) draw() {
+	ch.fill(ch.Bounds(), chartBackground)
+	ch.drawGrid()
+	ch.drawCandles()
+	ch.drawIndicators()
+	ch.drawEntry()
+	ch.drawMarkers()
+	ch.drawHeader()
+}
+
+// drawGrid draws the panel frames, price and RSI levels and time labels.
+func (ch *chart) drawGrid() {
+	labelX := ch.pricePanel.Max.X + 6
+	for k := range 5 {
+		p := ch.lo + (ch.hi-ch.lo)*float64(k)/4
+		y := ch.priceY(p)
+		ch.line(ch.pricePanel.Min.X, y, ch.pricePanel.Max.X, y, 1, chartGrid, 0)
+		ch.text(labelX, y-fontHeight/2, ch.formatPrice(p), chartText)
+	}
+	ch.frame(ch.pricePanel, chartGrid)
+	ch.frame(ch.rsiPanel, chartGrid)
+	for _, v := range []float64{30, 70} {
+		y := ch.rsiY(v)
+		ch.line(ch.rsiPanel.Min.X, y, ch.rsiPanel.Max.X, y, 1, chartText, 4)
+		ch.text(labelX, y-fontHeight/2, strconv.Itoa(int(v)), chartText)
+	}
+	ch.text(ch.rsiPanel.Min.X+4, ch.rsiPanel.Min.Y+4, "RSI "+strconv.Itoa(ch.params.RSIDivergence.RSIPeriod), chartRSI)
+
+	last := len(ch.candles) - 1
+	for _, i := range []int{ch.first, (ch.first + last) / 2, last} {
+		label := ch.candles[i].Time.UTC().Format("15:04")
+		x := min(max(ch.x(i)-textWidth(label)/2, chartMargin), ch.pricePanel.Max.X-textWidth(label))
+		ch.text(x, ch.rsiPanel.Max.Y+6, label, chartText)
+	}
+}
+
+func (ch *chart) drawCandles() {
+	bw := max(1, int(ch.slot*0.6))
+	for i := ch.first; i < len(ch.candles); i++ {
+		c := ch.candles[i]
+		col := chartUp
+		if c.Close < c.Open {
+			col = chartDown
+		}
+		x := ch.x(i)
+		ch.line(x, ch.priceY(c.High), x, ch.priceY(c.Low), 1, col, 0)
+		top, bottom := ch.priceY(math.Max(c.Open, c.Close)), ch.priceY(math.Min(c.Open, c.Close))
+		ch.fill(image.Rect(x-bw/2, top, x-bw/2+bw, bottom+1), col)
+	}
+}
+
+func (ch *chart) drawIndicators() {
+	for i := ch.first + 1; i < len(ch.candles); i++ {
+		ch.line(ch.x(i-1), ch.priceY(ch.emaSlow[i-1]), ch.x(i), ch.priceY(ch.emaSlow[i]), 2, chartEMASlow, 0)
+		ch.line(ch.x(i-1), ch.priceY(ch.emaFast[i-1]), ch.x(i), ch.priceY(ch.emaFast[i]), 2, chartEMAFast, 0)
+	}
+	// RSI values before the first full period are zero.
+	for i := max(ch.first, ch.params.RSIDivergence.RSIPeriod) + 1; i < len(ch.candles); i++ {
+		ch.line(ch.x(i-1), ch.rsiY(ch.rsi[i-1]), ch.x(i), ch.rsiY(ch.rsi[i]), 2, chartRSI, 0)
+	}
+}
+
+// drawEntry marks the signal's price with a dashed line and an axis label.
+func (ch *chart) drawEntry() {
+	if ch.signal.Price <= 0 {
+		return
+	}
+	y := ch.priceY(ch.signal.Price)
+	ch.line(ch.pricePanel.Min.X, y, ch.pricePanel.Max.X, y, 1, ch.dirColor, 6)
+	label := ch.formatPrice(ch.signal.Price)
+	box := image.Rect(ch.pricePanel.Max.X+2, y-fontHeight/2-3, ch.pricePanel.Max.X+10+textWidth(label), y+fontHeight/2+3)
+	ch.fill(box, ch.dirColor)
+	ch.text(box.Min.X+4, y-fontHeight/2, label, chartBackground)
+}
+
+// drawMarkers highlights the bars found by usecase.SignalSetup. Swing
+// points are circled and joined in both panels to show the divergence.
+func (ch *chart) drawMarkers() {
+	markers := usecase.SignalSetupWithParams(ch.signal, ch.candles, ch.rsi, ch.emaFast, ch.emaSlow, ch.params)
+	var swings []usecase.SetupMarker
+	for _, m := range markers {
+		if m.Index < ch.first {
+			continue
+		}
+		x := ch.x(m.Index)
+		switch m.Kind {
+		case usecase.MarkerSwingHigh, usecase.MarkerSwingLow:
+			swings = append(swings, m)
+			ch.ring(x, ch.priceY(m.Price), 5, chartMarker)
+			ch.ring(x, ch.rsiY(m.RSI), 5, chartMarker)
+		case usecase.MarkerEngulfing, usecase.MarkerPinBar:
+			label := "ENG"
+			if m.Kind == usecase.MarkerPinBar {
+				label = "PIN"
+			}
+			y := ch.priceY(m.Price)
+			if ch.signal.Direction == "UP" {
+				ch.triangle(x, y+5, 9, true, chartMarker)
+				ch.text(x-textWidth(label)/2, y+17, label, chartMarker)
+			} else {
+				ch.triangle(x, y-5, 9, false, chartMarker)
+				ch.text(x-textWidth(label)/2, y-17-fontHeight, label, chartMarker)
+			}
+		case usecase.MarkerEMACross:
+			ch.ring(x, ch.priceY(m.Price), 6, chartMarker)
+		}
+	}
+	if len(swings) == 2 {
+		a, b := swings[0], swings[1]
+		ch.line(ch.x(a.Index), ch.priceY(a.Price), ch.x(b.Index), ch.priceY(b.Price), 2, chartMarker, 0)
+		ch.line(ch.x(a.Index), ch.rsiY(a.RSI), ch.x(b.Index), ch.rsiY(b.RSI), 2, chartMarker, 0)
+	}
+}
+
+// drawHeader writes the signal summary and the indicator legend.
+func (ch *chart) drawHeader() {
+	s := ch.signal
+	summary := fmt.Sprintf(" %s %.0f%%", s.Direction, s.Confidence*100)
+	if src, ok := localePacks["en"]["source."+s.Source]; ok {
+		summary += "  " + strings.ToUpper(src)
+	}
+	if s.Stage == entity.StageEarly {
+		summary += "  (EARLY)"
+	}
+	y := (chartHeader - fontHeight) / 2
+	x := chartMargin + ch.text(chartMargin, y, displaySymbol(s.Symbol, ch.reg), chartText)
+	ch.text(x, y, summary, ch.dirColor)
+
+	x = ch.pricePanel.Max.X
+	for _, l := range []struct {
+		label string
+		col   color.RGBA
+	}{
+		{"EMA" + strconv.Itoa(ch.params.EMAInteraction.SlowPeriod), chartEMASlow},
+		{"EMA" + strconv.Itoa(ch.params.EMAInteraction.FastPeriod), chartEMAFast},
+	} {
+		x -= textWidth(l.label)
+		ch.text(x, y, l.label, l.col)
+		x -= 2 * fontAdvance
+	}
+}
+
+// canvas adds drawing primitives to an image.
+type canvas struct {
+	*image.RGBA
+}
+
+func (c canvas) fill(r image.Rectangle, col color.RGBA) {
+	draw.Draw(c.RGBA, r, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// dot fills a size by size square at (x, y).
+func (c canvas) dot(x, y, size int, col color.RGBA) {
+	c.fill(image.Rect(x, y, x+size, y+size), col)
+}
+
+// frame outlines r.
+func (c canvas) frame(r image.Rectangle, col color.RGBA) {
+	c.line(r.Min.X, r.Min.Y, r.Max.X-1, r.Min.Y, 1, col, 0)
+	c.line(r.Min.X, r.Max.Y-1, r.Max.X-1, r.Max.Y-1, 1, col, 0)
+	c.line(r.Min.X, r.Min.Y, r.Min.X, r.Max.Y-1, 1, col, 0)
+	c.line(r.Max.X-1, r.Min.Y, r.Max.X-1, r.Max.Y-1, 1, col, 0)
+}
+
+// line draws a line width pixels thick from (x0, y0) to (x1, y1), dashed
+// every dash pixels when dash is positive.
+func (c canvas) line(x0, y0, x1, y1, width int, col color.RGBA, dash int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for n := 0; ; n++ {
+		if dash <= 0 || (n/dash)%2 == 0 {
+			c.dot(x0, y0, width, col)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// ring draws a two-pixel circle of radius r centred on (x, y).
+func (c canvas) ring(x, y, r int, col color.RGBA) {
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if d := dx*dx + dy*dy; d <= r*r && d > (r-2)*(r-2) {
+				c.SetRGBA(x+dx, y+dy, col)
+			}
+		}
+	}
+}
+
+// triangle fills a triangle size pixels tall with its apex at (x, y),
+// pointing up or down.
+func (c canvas) triangle(x, y, size int, up bool, col color.RGBA) {
+	for i := range size {
+		row := y + i
+		if !up {
+			row = y - i
+		}
+		half := i * 2 / 3
+		c.fill(image.Rect(x-half, row, x+half+1, row+1), col)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
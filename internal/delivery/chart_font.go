@@ -0,0 +1,95 @@
+package delivery
+
+import (
+	"image/color"
+	"unicode"
+)
+
+// chartGlyphs is a 3x5 pixel font for chart labels. Each row is three bits,
+// the highest bit being the leftmost pixel. Lower-case letters are drawn in
+// upper case and unknown characters as '?'.
+var chartGlyphs = map[rune][5]uint8{
+	' ': {0b000, 0b000, 0b000, 0b000, 0b000},
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b111, 0b001, 0b111, 0b100, 0b111},
+	'3': {0b111, 0b001, 0b111, 0b001, 0b111},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b111, 0b001, 0b111},
+	'6': {0b111, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b010, 0b010, 0b010},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b111},
+	'A': {0b010, 0b101, 0b111, 0b101, 0b101},
+	'B': {0b110, 0b101, 0b111, 0b101, 0b110},
+	'C': {0b011, 0b100, 0b100, 0b100, 0b011},
+	'D': {0b110, 0b101, 0b101, 0b101, 0b110},
+	'E': {0b111, 0b100, 0b110, 0b100, 0b111},
+	'F': {0b111, 0b100, 0b110, 0b100, 0b100},
+	'G': {0b011, 0b100, 0b101, 0b101, 0b011},
+	'H': {0b101, 0b101, 0b111, 0b101, 0b101},
+	'I': {0b111, 0b010, 0b010, 0b010, 0b111},
+	'J': {0b001, 0b001, 0b001, 0b101, 0b010},
+	'K': {0b101, 0b101, 0b110, 0b101, 0b101},
+	'L': {0b100, 0b100, 0b100, 0b100, 0b111},
+	'M': {0b101, 0b111, 0b111, 0b101, 0b101},
+	'N': {0b110, 0b101, 0b101, 0b101, 0b101},
+	'O': {0b010, 0b101, 0b101, 0b101, 0b010},
+	'P': {0b110, 0b101, 0b110, 0b100, 0b100},
+	'Q': {0b010, 0b101, 0b101, 0b110, 0b011},
+	'R': {0b110, 0b101, 0b110, 0b101, 0b101},
+	'S': {0b011, 0b100, 0b010, 0b001, 0b110},
+	'T': {0b111, 0b010, 0b010, 0b010, 0b010},
+	'U': {0b101, 0b101, 0b101, 0b101, 0b111},
+	'V': {0b101, 0b101, 0b101, 0b101, 0b010},
+	'W': {0b101, 0b101, 0b111, 0b111, 0b101},
+	'X': {0b101, 0b101, 0b010, 0b101, 0b101},
+	'Y': {0b101, 0b101, 0b010, 0b010, 0b010},
+	'Z': {0b111, 0b001, 0b010, 0b100, 0b111},
+	'.': {0b000, 0b000, 0b000, 0b000, 0b010},
+	',': {0b000, 0b000, 0b000, 0b010, 0b100},
+	':': {0b000, 0b010, 0b000, 0b010, 0b000},
+	'/': {0b001, 0b001, 0b010, 0b100, 0b100},
+	'%': {0b100, 0b001, 0b010, 0b100, 0b001},
+	'-': {0b000, 0b000, 0b111, 0b000, 0b000},
+	'+': {0b000, 0b010, 0b111, 0b010, 0b000},
+	'(': {0b001, 0b010, 0b010, 0b010, 0b001},
+	')': {0b100, 0b010, 0b010, 0b010, 0b100},
+	'?': {0b111, 0b001, 0b010, 0b000, 0b010},
+}
+
+const (
+	// fontScale enlarges each font pixel to a square of this size.
+	fontScale = 2
+	// fontAdvance is the width of a character including spacing.
+	fontAdvance = 4 * fontScale
+	// fontHeight is the height of a character.
+	fontHeight = 5 * fontScale
+)
+
+// textWidth returns the width of s in pixels.
+func textWidth(s string) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return n*fontAdvance - fontScale
+}
+
+// text draws s with its top-left corner at (x, y) and returns its width.
+func (c canvas) text(x, y int, s string, col color.RGBA) int {
+	for i, r := range []rune(s) {
+		g, ok := chartGlyphs[unicode.ToUpper(r)]
+		if !ok {
+			g = chartGlyphs['?']
+		}
+		for row, bits := range g {
+			for cx := range 3 {
+				if bits&(0b100>>cx) != 0 {
+					c.dot(x+i*fontAdvance+cx*fontScale, y+row*fontScale, fontScale, col)
+				}
+			}
+		}
+	}
+	return textWidth(s)
+}
@@ -0,0 +1,70 @@
+package delivery
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+func TestChartRenderer_Render(t *testing.T) {
+	candles, _, _, _ := testutils.MakeScannerDistinctData()
+	last := candles[len(candles)-1]
+
+	tests := []struct {
+		name    string
+		opts    ChartOptions
+		signal  entity.Signal
+		size    image.Point
+		markers bool
+	}{
+		{
+			name:    "divergence",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, Price: last.Close, Time: last.Time, Source: usecase.ScorerRSIDivergence},
+			size:    image.Pt(800, 480),
+			markers: true,
+		},
+		{
+			name:   "no setup",
+			opts:   ChartOptions{Bars: 10, Width: 400, Height: 100, Instruments: entity.DefaultInstruments()},
+			signal: entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.8, Source: usecase.ScorerRSIDivergence, Stage: entity.StageEarly},
+			size:   image.Pt(400, 240),
+		},
+	}
+	for _, tt := range tests {
+		b, err := NewChartRenderer(tt.opts).Render(tt.signal, candles)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", tt.name, err)
+		}
+		if got := img.Bounds().Size(); got != tt.size {
+			t.Errorf("%s: expected size %v, got %v", tt.name, tt.size, got)
+		}
+		// The legend and header never use the marker colour.
+		markers := false
+		for y := chartHeader; y < img.Bounds().Dy() && !markers; y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				if color.RGBAModel.Convert(img.At(x, y)) == chartMarker {
+					markers = true
+					break
+				}
+			}
+		}
+		if markers != tt.markers {
+			t.Errorf("%s: expected markers %v, got %v", tt.name, tt.markers, markers)
+		}
+	}
+
+	if _, err := NewChartRenderer(ChartOptions{}).Render(entity.Signal{}, candles[:1]); err == nil || !strings.Contains(err.Error(), "at least 2 candles") {
+		t.Fatalf("expected a short buffer error, got %v", err)
+	}
+}
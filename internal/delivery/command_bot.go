@@ -0,0 +1,526 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+const commandHelp = `Commands:
+/subscribe EURUSD [GBPUSD ...] - receive signals for symbols
+/unsubscribe [EURUSD ...] - stop signals for symbols, or all
+/status - feed health and last signals
+/stats - recent win rate
+/language [fr] - language of this chat's signals
+/timezone [Europe/Paris] - time zone of this chat's signals
+/pause, /resume - stop or restart publishing (operators only)`
+
+// CommandBotOptions configures a CommandBot.
+type CommandBotOptions struct {
+	// Admins are the Telegram user IDs allowed to /pause and /resume.
+	Admins []int64
+	// Store persists subscriptions. Without it they last until restart.
+	Store ports.SubscriptionStore
+	// Health backs the feed part of /status. Optional.
+	Health *HealthChecker
+	// Stats returns recent signal outcomes for /stats. Optional.
+	Stats func(ctx context.Context) (usecase.BacktestReport, error)
+	// Instruments validates subscribed symbols and formats signals. Without
+	// it any symbol is accepted.
+	Instruments *entity.InstrumentRegistry
+	// Templates formats signals and outcomes. Each chat gets them in its
+	// /language and /timezone. Defaults to the built-in layout.
+	Templates *MessageTemplates
+	// Preferences persists each chat's language and time zone. Without it
+	// they last until restart.
+	Preferences ports.PreferenceStore
+	// PollTimeout is the long-poll wait passed to the bot. Defaults to 30s.
+	PollTimeout time.Duration
+	Logger      *slog.Logger
+}
+
+// OutcomeStats returns a CommandBotOptions.Stats function summarising the
+// live outcomes in store that expired within the last window.
+func OutcomeStats(store ports.OutcomeStore, window time.Duration) func(context.Context) (usecase.BacktestReport, error) {
+	return func(ctx context.Context) (usecase.BacktestReport, error) {
+		outcomes, err := store.List(ctx, time.Now().Add(-window))
+		if err != nil {
+			return usecase.BacktestReport{}, err
+		}
+		return usecase.OutcomeReport(outcomes), nil
+	}
+}
+
+func (o CommandBotOptions) withDefaults() CommandBotOptions {
+	if o.PollTimeout <= 0 {
+		o.PollTimeout = 30 * time.Second
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if o.Templates == nil {
+		o.Templates = plainTemplates.withInstruments(o.Instruments)
+	}
+	return o
+}
+
+// CommandBot answers subscriber and operator commands sent to the Telegram
+// bot and implements ports.SignalPublisher by sending each chat the signals
+// for the symbols it subscribed to.
+type CommandBot struct {
+	bot  ports.TelegramBot
+	orch *Orchestrator
+	opts CommandBotOptions
+
+	mu    sync.Mutex
+	subs  map[int64][]string
+	prefs map[int64]ports.ChatPreferences
+}
+
+// NewCommandBot loads the stored subscriptions and preferences and returns a
+// bot controlling orch.
+func NewCommandBot(ctx context.Context, bot ports.TelegramBot, orch *Orchestrator, opts CommandBotOptions) (*CommandBot, error) {
+	b := &CommandBot{bot: bot, orch: orch, opts: opts.withDefaults(), subs: map[int64][]string{}, prefs: map[int64]ports.ChatPreferences{}}
+	if b.opts.Store != nil {
+		subs, err := b.opts.Store.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load subscriptions: %w", err)
+		}
+		if subs != nil {
+			b.subs = subs
+		}
+	}
+	if b.opts.Preferences != nil {
+		prefs, err := b.opts.Preferences.LoadPreferences(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load preferences: %w", err)
+		}
+		if prefs != nil {
+			b.prefs = prefs
+		}
+	}
+	return b, nil
+}
+
+// Run long-polls the bot for commands until ctx is done. Polling errors are
+// logged and retried with backoff. Use HandleMessage instead when updates
+// arrive through a webhook.
+func (b *CommandBot) Run(ctx context.Context) error {
+	var offset int64
+	failures := 0
+	for {
+		msgs, err := b.bot.Updates(ctx, offset, b.opts.PollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			wait := defaultRetryBackoff{}.Next(failures)
+			failures++
+			b.opts.Logger.WarnContext(ctx, "poll telegram updates", "retry_in", wait, "error", err)
+			if !sleep(ctx, wait) {
+				return ctx.Err()
+			}
+			continue
+		}
+		failures = 0
+		for _, m := range msgs {
+			offset = max(offset, m.UpdateID+1)
+			if m.Text != "" {
+				b.HandleMessage(ctx, m)
+			}
+		}
+	}
+}
+
+// HandleMessage runs the command in m and sends the reply. Messages that are
+// not commands are ignored.
+func (b *CommandBot) HandleMessage(ctx context.Context, m ports.BotMessage) {
+	fields := strings.Fields(m.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+	// Commands in groups may be addressed as /status@SignalBot.
+	cmd, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	args := fields[1:]
+
+	var reply string
+	switch cmd {
+	case "/start", "/help":
+		reply = commandHelp
+	case "/subscribe":
+		reply = b.subscribe(ctx, m.ChatID, args)
+	case "/unsubscribe":
+		reply = b.unsubscribe(ctx, m.ChatID, args)
+	case "/status":
+		reply = b.status(m.ChatID)
+	case "/stats":
+		reply = b.stats(ctx)
+	case "/language":
+		reply = b.language(ctx, m.ChatID, args)
+	case "/timezone":
+		reply = b.timezone(ctx, m.ChatID, args)
+	case "/pause", "/resume":
+		reply = b.pauseResume(cmd, m.UserID)
+	default:
+		reply = "Unknown command. Send /help for the list."
+	}
+	b.opts.Logger.InfoContext(ctx, "telegram command", "command", cmd, "chat", m.ChatID, "user", m.UserID)
+	if err := b.bot.SendMessage(ctx, m.ChatID, reply); err != nil {
+		b.opts.Logger.ErrorContext(ctx, "send command reply", "command", cmd, "chat", m.ChatID, "error", err)
+	}
+}
+
+// Subscriptions returns the symbols each chat subscribed to.
+func (b *CommandBot) Subscriptions() map[int64][]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[int64][]string, len(b.subs))
+	for chat, syms := range b.subs {
+		out[chat] = slices.Clone(syms)
+	}
+	return out
+}
+
+func (b *CommandBot) subscribe(ctx context.Context, chat int64, args []string) string {
+	if len(args) == 0 {
+		return "Usage: /subscribe EURUSD [GBPUSD ...]"
+	}
+	var add []string
+	for _, a := range args {
+		sym, ok := b.symbolKey(a)
+		if !ok {
+			return fmt.Sprintf("Unknown symbol %s.", a)
+		}
+		add = append(add, sym)
+	}
+	syms, err := b.updateSubscriptions(ctx, chat, func(cur []string) []string {
+		for _, s := range add {
+			if !slices.Contains(cur, s) {
+				cur = append(cur, s)
+			}
+		}
+		slices.Sort(cur)
+		return cur
+	})
+	if err != nil {
+		return "Could not save your subscription, please try again."
+	}
+	return "Subscribed. Your symbols: " + strings.Join(syms, ", ")
+}
+
+func (b *CommandBot) unsubscribe(ctx context.Context, chat int64, args []string) string {
+	remove := make([]string, len(args))
+	for i, a := range args {
+		remove[i], _ = b.symbolKey(a)
+	}
+	syms, err := b.updateSubscriptions(ctx, chat, func(cur []string) []string {
+		if len(remove) == 0 {
+			return nil
+		}
+		return slices.DeleteFunc(cur, func(s string) bool { return slices.Contains(remove, s) })
+	})
+	if err != nil {
+		return "Could not save your subscription, please try again."
+	}
+	if len(syms) == 0 {
+		return "Unsubscribed from all symbols."
+	}
+	return "Unsubscribed. Your symbols: " + strings.Join(syms, ", ")
+}
+
+// symbolKey returns the subscription key of symbol, canonicalised through
+// Instruments when set. It reports false for symbols Instruments does not
+// know.
+func (b *CommandBot) symbolKey(symbol string) (string, bool) {
+	if b.opts.Instruments == nil {
+		return entity.SymbolKey(symbol), true
+	}
+	if _, ok := b.opts.Instruments.Lookup(symbol); !ok {
+		return entity.SymbolKey(symbol), false
+	}
+	return entity.SymbolKey(b.opts.Instruments.Canonical(symbol)), true
+}
+
+// updateSubscriptions applies update to the chat's symbols and persists the
+// result. The change is rolled back if it cannot be saved.
+func (b *CommandBot) updateSubscriptions(ctx context.Context, chat int64, update func([]string) []string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prev, had := b.subs[chat]
+	next := update(slices.Clone(prev))
+	if len(next) == 0 {
+		delete(b.subs, chat)
+	} else {
+		b.subs[chat] = next
+	}
+	if b.opts.Store != nil {
+		if err := b.opts.Store.Save(ctx, b.subs); err != nil {
+			b.opts.Logger.ErrorContext(ctx, "save subscriptions", "chat", chat, "error", err)
+			if had {
+				b.subs[chat] = prev
+			} else {
+				delete(b.subs, chat)
+			}
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+func (b *CommandBot) language(ctx context.Context, chat int64, args []string) string {
+	locales := strings.Join(b.opts.Templates.Locales(), ", ")
+	if len(args) == 0 {
+		return fmt.Sprintf("Language: %s. Available: %s.", b.preferences(chat).Locale, locales)
+	}
+	if _, err := b.opts.Templates.For(args[0], nil); err != nil {
+		return fmt.Sprintf("Unknown language %s. Available: %s.", args[0], locales)
+	}
+	code := localeCode(args[0])
+	if err := b.updatePreferences(ctx, chat, func(p *ports.ChatPreferences) { p.Locale = code }); err != nil {
+		return "Could not save your settings, please try again."
+	}
+	return "Language set to " + code + "."
+}
+
+func (b *CommandBot) timezone(ctx context.Context, chat int64, args []string) string {
+	if len(args) == 0 {
+		return fmt.Sprintf("Time zone: %s. Change it with /timezone Europe/Paris.", b.preferences(chat).Timezone)
+	}
+	loc, err := time.LoadLocation(args[0])
+	if err != nil {
+		return fmt.Sprintf("Unknown time zone %s. Use a name such as Europe/Paris or America/Sao_Paulo.", args[0])
+	}
+	if err := b.updatePreferences(ctx, chat, func(p *ports.ChatPreferences) { p.Timezone = loc.String() }); err != nil {
+		return "Could not save your settings, please try again."
+	}
+	return "Time zone set to " + loc.String() + "."
+}
+
+// preferences returns the chat's preferences with defaults filled in.
+func (b *CommandBot) preferences(chat int64) ports.ChatPreferences {
+	b.mu.Lock()
+	p := b.prefs[chat]
+	b.mu.Unlock()
+	if p.Locale == "" {
+		p.Locale = b.opts.Templates.opts.Locale
+	}
+	if p.Timezone == "" {
+		p.Timezone = b.opts.Templates.opts.Location.String()
+	}
+	return p
+}
+
+// updatePreferences applies update to the chat's preferences and persists
+// the result. The change is rolled back if it cannot be saved.
+func (b *CommandBot) updatePreferences(ctx context.Context, chat int64, update func(*ports.ChatPreferences)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prev, had := b.prefs[chat]
+	next := prev
+	update(&next)
+	b.prefs[chat] = next
+	if b.opts.Preferences != nil {
+		if err := b.opts.Preferences.SavePreferences(ctx, b.prefs); err != nil {
+			b.opts.Logger.ErrorContext(ctx, "save preferences", "chat", chat, "error", err)
+			if had {
+				b.prefs[chat] = prev
+			} else {
+				delete(b.prefs, chat)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// templates returns the templates in the chat's language and time zone.
+func (b *CommandBot) templates(ctx context.Context, chat int64) *MessageTemplates {
+	b.mu.Lock()
+	p := b.prefs[chat]
+	b.mu.Unlock()
+	if p == (ports.ChatPreferences{}) {
+		return b.opts.Templates
+	}
+	var loc *time.Location
+	if p.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(p.Timezone); err != nil {
+			b.opts.Logger.WarnContext(ctx, "load chat time zone", "chat", chat, "timezone", p.Timezone, "error", err)
+		}
+	}
+	t, err := b.opts.Templates.For(p.Locale, loc)
+	if err != nil {
+		b.opts.Logger.WarnContext(ctx, "load chat templates", "chat", chat, "locale", p.Locale, "error", err)
+		return b.opts.Templates
+	}
+	return t
+}
+
+func (b *CommandBot) status(chat int64) string {
+	st := b.orch.Status()
+	var lines []string
+	if st.Paused {
+		lines = append(lines, "Signals: paused")
+	} else {
+		lines = append(lines, "Signals: active")
+	}
+	if b.opts.Health != nil {
+		rep := b.opts.Health.Readiness()
+		feed := "ready"
+		if !rep.OK {
+			var failing []string
+			for _, c := range rep.Checks {
+				if !c.OK {
+					failing = append(failing, strings.TrimSuffix(c.Name+": "+c.Detail, ": "))
+				}
+			}
+			feed = "not ready (" + strings.Join(failing, "; ") + ")"
+		}
+		lines = append(lines, "Feed: "+feed)
+	}
+	if syms := b.Subscriptions()[chat]; len(syms) > 0 {
+		lines = append(lines, "Your symbols: "+strings.Join(syms, ", "))
+	}
+	if len(st.RecentSignals) == 0 {
+		return strings.Join(append(lines, "No signals yet."), "\n")
+	}
+	lines = append(lines, "Last signals:")
+	for i := len(st.RecentSignals) - 1; i >= 0; i-- {
+		s := st.RecentSignals[i]
+		line := fmt.Sprintf("%s %s %s", s.Time.UTC().Format("15:04"), displaySymbol(s.Symbol, b.opts.Instruments), s.Direction)
+		switch s.Stage {
+		case entity.StageConfirmed, entity.StageCancelled:
+			line += " " + string(s.Stage)
+		case entity.StageEarly:
+			line += fmt.Sprintf(" %.0f%% early", s.Confidence*100)
+		default:
+			line += fmt.Sprintf(" %.0f%%", s.Confidence*100)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *CommandBot) stats(ctx context.Context) string {
+	if b.opts.Stats == nil {
+		return "Stats are not available."
+	}
+	rep, err := b.opts.Stats(ctx)
+	if err != nil {
+		b.opts.Logger.ErrorContext(ctx, "compute stats", "error", err)
+		return "Stats are not available right now."
+	}
+	if rep.Wins+rep.Losses == 0 {
+		return fmt.Sprintf("No settled signals yet (%d total).", rep.Total)
+	}
+	return fmt.Sprintf("Win rate %.0f%% over %d signals: %d wins, %d losses, %d neutral.",
+		rep.Accuracy*100, rep.Total, rep.Wins, rep.Losses, rep.Neutrals)
+}
+
+func (b *CommandBot) pauseResume(cmd string, user int64) string {
+	if !slices.Contains(b.opts.Admins, user) {
+		return "This command is for operators only."
+	}
+	if cmd == "/pause" {
+		b.orch.Pause()
+		return "Signals paused. Send /resume to restart."
+	}
+	b.orch.Resume()
+	return "Signals resumed."
+}
+
+// PublishSignals sends every subscribed chat the signals for its symbols,
+// one message per signal in the chat's language and time zone. It returns
+// the joined errors of failed chats.
+func (b *CommandBot) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	return b.PublishSignalCharts(ctx, signals, nil)
+}
+
+// PublishSignalCharts behaves like PublishSignals and sends each signal that
+// has a chart as a photo captioned with its message.
+func (b *CommandBot) PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error {
+	symbols := make([]string, len(signals))
+	for i, s := range signals {
+		symbols[i] = s.Symbol
+	}
+	return b.broadcast(ctx, symbols, charts, func(t *MessageTemplates, i int) string { return t.FormatSignal(signals[i]) })
+}
+
+// PublishOutcomes sends every chat subscribed to an outcome's symbol a
+// follow-up message.
+func (b *CommandBot) PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error {
+	symbols := make([]string, len(outcomes))
+	for i, o := range outcomes {
+		symbols[i] = o.Signal.Symbol
+	}
+	return b.broadcast(ctx, symbols, nil, func(t *MessageTemplates, i int) string { return t.FormatOutcome(outcomes[i]) })
+}
+
+// PublishDigest sends every subscribed chat one summary of the signals for
+// its symbols, in the chat's language and time zone.
+func (b *CommandBot) PublishDigest(ctx context.Context, signals []entity.Signal) error {
+	subs := b.Subscriptions()
+	parseMode := string(b.opts.Templates.Markup())
+	var errs []error
+	for _, chat := range slices.Sorted(maps.Keys(subs)) {
+		var matched []entity.Signal
+		for _, s := range signals {
+			if slices.Contains(subs[chat], entity.SymbolKey(s.Symbol)) {
+				matched = append(matched, s)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		text := b.templates(ctx, chat).FormatDigest(matched)
+		if err := b.bot.SendFormatted(ctx, chat, text, parseMode); err != nil {
+			errs = append(errs, fmt.Errorf("chat %d: %w", chat, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// broadcast sends format(t, i) to every chat subscribed to symbols[i], where
+// t are the chat's templates, as the caption of photos[i] when there is one.
+// A chat is skipped after its first failure.
+func (b *CommandBot) broadcast(ctx context.Context, symbols []string, photos [][]byte, format func(t *MessageTemplates, i int) string) error {
+	subs := b.Subscriptions()
+	parseMode := string(b.opts.Templates.Markup())
+	var errs []error
+	for _, chat := range slices.Sorted(maps.Keys(subs)) {
+		t := b.templates(ctx, chat)
+		for i, sym := range symbols {
+			if !slices.Contains(subs[chat], entity.SymbolKey(sym)) {
+				continue
+			}
+			var err error
+			if i < len(photos) && len(photos[i]) > 0 {
+				err = b.bot.SendPhoto(ctx, chat, photos[i], format(t, i), parseMode)
+			} else {
+				err = b.bot.SendFormatted(ctx, chat, format(t, i), parseMode)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("chat %d: %w", chat, err))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	_ ports.SignalPublisher  = (*CommandBot)(nil)
+	_ ports.OutcomePublisher = (*CommandBot)(nil)
+	_ ports.ChartPublisher   = (*CommandBot)(nil)
+	_ ports.DigestPublisher  = (*CommandBot)(nil)
+)
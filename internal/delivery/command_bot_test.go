@@ -0,0 +1,318 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+const (
+	testChat  = int64(-100)
+	testAdmin = int64(7)
+)
+
+func newTestCommandBot(t *testing.T, bot *testutils.MockTelegramBot, store ports.SubscriptionStore, opts CommandBotOptions) (*CommandBot, *Orchestrator) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	o := NewOrchestrator(nil, nil, logger, WithSignalPublisher(&testutils.MockSignalPublisher{}))
+	opts.Store = store
+	opts.Logger = logger
+	opts.Admins = []int64{testAdmin}
+	b, err := NewCommandBot(context.Background(), bot, o, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, o
+}
+
+// lastReply sends text from user and returns the bot's reply.
+func lastReply(t *testing.T, b *CommandBot, bot *testutils.MockTelegramBot, user int64, text string) string {
+	t.Helper()
+	before := len(bot.Sent())
+	b.HandleMessage(context.Background(), ports.BotMessage{ChatID: testChat, UserID: user, Text: text})
+	sent := bot.Sent()
+	if len(sent) != before+1 {
+		t.Fatalf("%q: expected one reply, got %d", text, len(sent)-before)
+	}
+	if sent[len(sent)-1].ChatID != testChat {
+		t.Fatalf("%q: reply sent to chat %d", text, sent[len(sent)-1].ChatID)
+	}
+	return sent[len(sent)-1].Text
+}
+
+func TestCommandBot_Subscriptions(t *testing.T) {
+	bot := &testutils.MockTelegramBot{}
+	store := &testutils.MockSubscriptionStore{}
+	b, _ := newTestCommandBot(t, bot, store, CommandBotOptions{Instruments: entity.DefaultInstruments()})
+
+	steps := []struct {
+		text string
+		want string
+		subs []string
+	}{
+		{text: "/subscribe", want: "Usage", subs: nil},
+		{text: "/subscribe EUR/USD", want: "Your symbols: EURUSD", subs: []string{"EURUSD"}},
+		{text: "/subscribe@SignalBot gbpusd EURUSD", want: "EURUSD, GBPUSD", subs: []string{"EURUSD", "GBPUSD"}},
+		{text: "/subscribe NOPE", want: "Unknown symbol NOPE", subs: []string{"EURUSD", "GBPUSD"}},
+		{text: "/unsubscribe eur/usd", want: "Your symbols: GBPUSD", subs: []string{"GBPUSD"}},
+		{text: "/subscribe eur_usd", want: "EURUSD, GBPUSD", subs: []string{"EURUSD", "GBPUSD"}},
+		{text: "/unsubscribe EURUSD", want: "Your symbols: GBPUSD", subs: []string{"GBPUSD"}},
+		{text: "/unsubscribe", want: "all symbols", subs: nil},
+		{text: "/subscribe USDJPY", want: "USDJPY", subs: []string{"USDJPY"}},
+		{text: "/frobnicate", want: "Unknown command", subs: []string{"USDJPY"}},
+	}
+	for _, st := range steps {
+		if got := lastReply(t, b, bot, 1, st.text); !strings.Contains(got, st.want) {
+			t.Fatalf("%q: expected reply containing %q, got %q", st.text, st.want, got)
+		}
+		if got := b.Subscriptions()[testChat]; !reflect.DeepEqual(got, st.subs) {
+			t.Fatalf("%q: expected subscriptions %v, got %v", st.text, st.subs, got)
+		}
+	}
+
+	b.HandleMessage(context.Background(), ports.BotMessage{ChatID: testChat, Text: "just chatting"})
+	if n := len(bot.Sent()); n != len(steps) {
+		t.Fatalf("plain text should be ignored, got %d replies", n)
+	}
+
+	// Subscriptions survive a restart.
+	restarted, _ := newTestCommandBot(t, &testutils.MockTelegramBot{}, store, CommandBotOptions{})
+	if got := restarted.Subscriptions(); !reflect.DeepEqual(got, map[int64][]string{testChat: {"USDJPY"}}) {
+		t.Fatalf("expected persisted subscriptions, got %v", got)
+	}
+
+	// A failed save leaves the subscriptions unchanged.
+	store.SaveErr = errors.New("disk full")
+	if got := lastReply(t, b, bot, 1, "/subscribe EURUSD"); !strings.Contains(got, "Could not save") {
+		t.Fatalf("expected save error reply, got %q", got)
+	}
+	if got := b.Subscriptions()[testChat]; !reflect.DeepEqual(got, []string{"USDJPY"}) {
+		t.Fatalf("expected rollback, got %v", got)
+	}
+}
+
+func TestCommandBot_PauseResume(t *testing.T) {
+	bot := &testutils.MockTelegramBot{}
+	b, o := newTestCommandBot(t, bot, nil, CommandBotOptions{})
+
+	if got := lastReply(t, b, bot, 1, "/pause"); !strings.Contains(got, "operators only") || o.Status().Paused {
+		t.Fatalf("non-admin pause: reply %q, paused %v", got, o.Status().Paused)
+	}
+	if got := lastReply(t, b, bot, testAdmin, "/pause"); !strings.Contains(got, "paused") || !o.Status().Paused {
+		t.Fatalf("admin pause: reply %q, paused %v", got, o.Status().Paused)
+	}
+	if got := lastReply(t, b, bot, 1, "/status"); !strings.Contains(got, "Signals: paused") {
+		t.Fatalf("status while paused: %q", got)
+	}
+	if got := lastReply(t, b, bot, testAdmin, "/resume"); !strings.Contains(got, "resumed") || o.Status().Paused {
+		t.Fatalf("admin resume: reply %q, paused %v", got, o.Status().Paused)
+	}
+}
+
+func TestCommandBot_StatusAndStats(t *testing.T) {
+	bot := &testutils.MockTelegramBot{}
+	stats := func(context.Context) (usecase.BacktestReport, error) {
+		return usecase.BacktestReport{Total: 10, Wins: 6, Losses: 3, Neutrals: 1, Accuracy: 6.0 / 9}, nil
+	}
+	o := NewOrchestrator(nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), WithSignalPublisher(&testutils.MockSignalPublisher{}))
+	h := NewHealthChecker(o, staticHealth{ports.FeedHealth{State: ports.FeedConnecting}}, nil, 0)
+	b, err := NewCommandBot(context.Background(), bot, o, CommandBotOptions{Health: h, Stats: stats, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := lastReply(t, b, bot, 1, "/status"); !strings.Contains(got, "Feed: not ready (orchestrator: not running; feed: connecting") ||
+		!strings.Contains(got, "No signals yet") {
+		t.Fatalf("status: %q", got)
+	}
+
+	bar := time.Date(2024, 1, 3, 13, 30, 0, 0, time.UTC)
+	o.publish(context.Background(), "EURUSD", []entity.Signal{{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, Time: bar}}, nil)
+	o.publish(context.Background(), "EURUSD", []entity.Signal{{Symbol: "EURUSD", Direction: "UP", Time: bar, Stage: entity.StageConfirmed}}, nil)
+	got := lastReply(t, b, bot, 1, "/status")
+	if !strings.Contains(got, "Last signals:\n13:30 EURUSD UP confirmed\n13:30 EURUSD UP 80%") {
+		t.Fatalf("status with signals: %q", got)
+	}
+
+	if got := lastReply(t, b, bot, 1, "/stats"); got != "Win rate 67% over 10 signals: 6 wins, 3 losses, 1 neutral." {
+		t.Fatalf("stats: %q", got)
+	}
+}
+
+func TestCommandBot_PublishSignals(t *testing.T) {
+	bot := &testutils.MockTelegramBot{}
+	b, _ := newTestCommandBot(t, bot, nil, CommandBotOptions{})
+	for chat, text := range map[int64]string{1: "/subscribe EURUSD", 2: "/subscribe GBPUSD EURUSD", 3: "/subscribe USDJPY"} {
+		b.HandleMessage(context.Background(), ports.BotMessage{ChatID: chat, Text: text})
+	}
+	replies := len(bot.Sent())
+
+	signals := []entity.Signal{
+		{Symbol: "EUR/USD", Direction: "UP", Confidence: 0.8, TTL: time.Minute},
+		{Symbol: "GBPUSD", Direction: "DOWN", Confidence: 0.7, TTL: time.Minute},
+	}
+	if err := b.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, m := range bot.Sent()[replies:] {
+		got = append(got, m.ChatID)
+		if !strings.Contains(m.Text, "Signal") {
+			t.Errorf("expected formatted signal, got %q", m.Text)
+		}
+	}
+	if want := []int64{1, 2, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected messages to chats %v, got %v", want, got)
+	}
+
+	sent := len(bot.Sent())
+	if err := b.PublishOutcomes(context.Background(), []entity.SignalOutcome{{Signal: signals[1], Outcome: "WIN"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bot.Sent()[sent:]; len(got) != 1 || got[0].ChatID != 2 || !strings.HasPrefix(got[0].Text, "🏆 WIN: GBPUSD DOWN") {
+		t.Fatalf("expected one follow-up to chat 2, got %+v", got)
+	}
+
+	sent = len(bot.Sent())
+	if err := b.PublishSignalCharts(context.Background(), signals, [][]byte{[]byte("png")}); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range bot.Sent()[sent:] {
+		if wantPhoto := m.Text == FormatSignal(signals[0], nil); wantPhoto != (string(m.Photo) == "png") {
+			t.Errorf("expected the chart with the EURUSD signal only, got %+v", m)
+		}
+	}
+
+	sent = len(bot.Sent())
+	if err := b.PublishDigest(context.Background(), signals); err != nil {
+		t.Fatal(err)
+	}
+	digests := map[int64]string{}
+	for _, m := range bot.Sent()[sent:] {
+		digests[m.ChatID] = m.Text
+	}
+	if want := map[int64]string{1: FormatDigest(signals[:1], nil), 2: FormatDigest(signals, nil)}; !reflect.DeepEqual(digests, want) {
+		t.Fatalf("expected digests %q, got %q", want, digests)
+	}
+
+	bot.SendErr = errors.New("forbidden")
+	if err := b.PublishSignals(context.Background(), signals); err == nil {
+		t.Fatal("expected send error")
+	}
+}
+
+func TestOutcomeStats(t *testing.T) {
+	store := &testutils.MockOutcomeStore{}
+	now := time.Now()
+	for i, outcome := range []string{"WIN", "LOSS", "WIN"} {
+		o := entity.SignalOutcome{Signal: entity.Signal{Symbol: "EURUSD"}, ExpiryTime: now.Add(-time.Duration(i) * time.Hour), Outcome: outcome}
+		if err := store.Store(context.Background(), o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep, err := OutcomeStats(store, 90*time.Minute)(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Total != 2 || rep.Wins != 1 || rep.Losses != 1 {
+		t.Fatalf("expected the last 90 minutes of outcomes, got %+v", rep)
+	}
+}
+
+func TestCommandBot_Run(t *testing.T) {
+	bot := &testutils.MockTelegramBot{}
+	b, _ := newTestCommandBot(t, bot, nil, CommandBotOptions{PollTimeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	bot.Push(
+		ports.BotMessage{UpdateID: 10, ChatID: testChat, Text: "/help"},
+		ports.BotMessage{UpdateID: 11},
+		ports.BotMessage{UpdateID: 12, ChatID: testChat, Text: "/subscribe EURUSD"},
+	)
+	// Polling resumes after the last update, including ones without text.
+	polled := func() bool {
+		offsets := bot.Offsets()
+		return len(offsets) > 0 && offsets[len(offsets)-1] == 13
+	}
+	deadline := time.After(time.Second)
+	for len(bot.Sent()) < 2 || !polled() {
+		select {
+		case <-deadline:
+			t.Fatalf("expected 2 replies and a poll from offset 13, got %v and offsets %v", bot.Sent(), bot.Offsets())
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := bot.Sent()[0].Text; got != commandHelp {
+		t.Fatalf("expected help, got %q", got)
+	}
+}
+
+func TestCommandBot_Preferences(t *testing.T) {
+	if _, err := time.LoadLocation("America/Sao_Paulo"); err != nil {
+		t.Skip("time zone data not available:", err)
+	}
+	bot := &testutils.MockTelegramBot{}
+	store := &testutils.MockSubscriptionStore{}
+	tmpl, err := NewMessageTemplates(TemplateOptions{Markup: MarkupMarkdownV2, Outcome: `{{.OutcomeText}} {{clock .Signal.Time}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := newTestCommandBot(t, bot, store, CommandBotOptions{Templates: tmpl, Preferences: store})
+
+	if got := lastReply(t, b, bot, 1, "/language"); got != "Language: en. Available: en, es, fr, pt." {
+		t.Fatalf("language: %q", got)
+	}
+	if got := lastReply(t, b, bot, 1, "/language xx"); !strings.HasPrefix(got, "Unknown language xx") {
+		t.Fatalf("unknown language: %q", got)
+	}
+	if got := lastReply(t, b, bot, 1, "/timezone Mars/Olympus"); !strings.HasPrefix(got, "Unknown time zone") {
+		t.Fatalf("unknown time zone: %q", got)
+	}
+	if got := lastReply(t, b, bot, 1, "/language pt-BR"); got != "Language set to pt." {
+		t.Fatalf("set language: %q", got)
+	}
+	if got := lastReply(t, b, bot, 1, "/timezone America/Sao_Paulo"); got != "Time zone set to America/Sao_Paulo." {
+		t.Fatalf("set time zone: %q", got)
+	}
+	b.HandleMessage(context.Background(), ports.BotMessage{ChatID: testChat, Text: "/subscribe EURUSD"})
+	b.HandleMessage(context.Background(), ports.BotMessage{ChatID: 2, Text: "/subscribe EURUSD"})
+
+	// Preferences survive a restart.
+	b, _ = newTestCommandBot(t, bot, store, CommandBotOptions{Templates: tmpl, Preferences: store})
+	sent := len(bot.Sent())
+	bar := time.Date(2024, 7, 1, 13, 30, 0, 0, time.UTC)
+	if err := b.PublishOutcomes(context.Background(), []entity.SignalOutcome{{Signal: entity.Signal{Symbol: "EURUSD", Direction: "UP", Time: bar}, Outcome: "WIN"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []testutils.SentMessage{
+		{ChatID: testChat, Text: "GANHO 10:30 \\-03", ParseMode: "MarkdownV2"},
+		{ChatID: 2, Text: "WIN 13:30 UTC", ParseMode: "MarkdownV2"},
+	}
+	if got := bot.Sent()[sent:]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	store.SaveErr = errors.New("disk full")
+	if got := lastReply(t, b, bot, 1, "/language fr"); !strings.HasPrefix(got, "Could not save") {
+		t.Fatalf("failed save: %q", got)
+	}
+	if got := lastReply(t, b, bot, 1, "/language"); !strings.HasPrefix(got, "Language: pt.") {
+		t.Fatalf("expected the failed change rolled back, got %q", got)
+	}
+}
@@ -0,0 +1,329 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// Errors reported for deliveries the queue drops.
+var (
+	// ErrDeliveryQueueFull is returned by Enqueue when the queue is full and
+	// the overflow policy cannot make room, and passed to the Done callback
+	// of deliveries dropped by OverflowDropOldest.
+	ErrDeliveryQueueFull = errors.New("delivery queue full")
+	// ErrDeliveryExpired is passed to the Done callback of deliveries whose
+	// messages all expired before they could be sent.
+	ErrDeliveryExpired = errors.New("delivery expired")
+)
+
+// OverflowPolicy selects what Enqueue does when the queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest queued delivery.
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDropExpired discards queued deliveries whose messages have
+	// all expired and rejects the new delivery when none have.
+	OverflowDropExpired
+	// OverflowBlock waits for room until the context is done.
+	OverflowBlock
+)
+
+// OutboundMessage is a formatted message awaiting delivery.
+type OutboundMessage struct {
+	Text string
+	// Photo, when set, is a PNG image sent with Text as its caption if the
+	// publisher implements ports.PhotoPublisher. Otherwise only Text is
+	// sent. Dead letters keep only Text.
+	Photo []byte
+	// Expires is when the message goes stale. The zero value never expires.
+	Expires time.Time
+	// Markup is the markup Text was escaped for. Publishers implementing
+	// ports.FormattedPublisher parse Text with it; others use their own
+	// parse mode.
+	Markup Markup
+}
+
+func (m OutboundMessage) expired(now time.Time) bool {
+	return !m.Expires.IsZero() && !now.Before(m.Expires)
+}
+
+// Delivery is a batch of messages for one symbol.
+type Delivery struct {
+	Symbol   string
+	Messages []OutboundMessage
+	// Done, when set, is called once with the publish result, or with
+	// ErrDeliveryQueueFull, ErrDeliveryExpired or the context's error when
+	// the delivery is dropped. It is called from a worker or from Enqueue,
+	// never with the queue locked.
+	Done func(error)
+}
+
+func (d Delivery) expired(now time.Time) bool {
+	for _, m := range d.Messages {
+		if !m.expired(now) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeliveryQueueOptions configures a DeliveryQueue.
+type DeliveryQueueOptions struct {
+	// Capacity bounds the number of queued deliveries. Defaults to 100.
+	Capacity int
+	// Workers is the number of concurrent publishers. Defaults to 1, which
+	// preserves message order.
+	Workers int
+	// Overflow selects the policy applied when the queue is full.
+	Overflow OverflowPolicy
+	// Retry configures publish retries and the dead-letter store.
+	Retry  RetryOptions
+	Logger *slog.Logger
+}
+
+func (o DeliveryQueueOptions) withDefaults() DeliveryQueueOptions {
+	if o.Capacity <= 0 {
+		o.Capacity = 100
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	return o
+}
+
+// DeliveryQueueStats counts deliveries by fate.
+type DeliveryQueueStats struct {
+	Queued    int
+	Delivered int
+	Failed    int
+	// Expired counts messages discarded because their TTL elapsed.
+	Expired int
+	// Dropped counts deliveries discarded or rejected on overflow.
+	Dropped int
+}
+
+// DeliveryQueue decouples signal scoring from publishing. Enqueue returns
+// immediately under the drop policies, and Run's workers publish in the
+// background, discarding messages that expired while queued.
+type DeliveryQueue struct {
+	publisher ports.TelegramPublisher
+	opts      DeliveryQueueOptions
+	now       func() time.Time
+
+	mu    sync.Mutex
+	items []Delivery
+	stats DeliveryQueueStats
+	ready chan struct{}
+	space chan struct{}
+}
+
+// NewDeliveryQueue returns a queue publishing to publisher. Call Run to
+// start the workers.
+func NewDeliveryQueue(publisher ports.TelegramPublisher, opts DeliveryQueueOptions) *DeliveryQueue {
+	return &DeliveryQueue{
+		publisher: publisher,
+		opts:      opts.withDefaults(),
+		now:       time.Now,
+		ready:     make(chan struct{}, 1),
+		space:     make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds d to the queue, applying the overflow policy when it is full.
+// A rejected delivery is reported to its Done callback as well as returned.
+func (q *DeliveryQueue) Enqueue(ctx context.Context, d Delivery) error {
+	for {
+		q.mu.Lock()
+		room := len(q.items) < q.opts.Capacity
+		var dropped []Delivery
+		var reason error
+		if !room {
+			dropped, reason = q.makeRoom()
+			room = len(q.items) < q.opts.Capacity
+		}
+		if room {
+			q.items = append(q.items, d)
+			more := len(q.items) < q.opts.Capacity
+			q.mu.Unlock()
+			q.drop(ctx, dropped, reason)
+			notify(q.ready)
+			if more {
+				// Pass the wake-up on to other blocked callers.
+				notify(q.space)
+			}
+			return nil
+		}
+		if q.opts.Overflow != OverflowBlock {
+			q.stats.Dropped++
+			q.mu.Unlock()
+			q.opts.Logger.WarnContext(ctx, "delivery queue full, dropping delivery", "symbol", d.Symbol)
+			finish(d, ErrDeliveryQueueFull)
+			return ErrDeliveryQueueFull
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			finish(d, ctx.Err())
+			return ctx.Err()
+		case <-q.space:
+		}
+	}
+}
+
+// makeRoom applies the drop policies to a full queue and returns the
+// deliveries it removed with the error to report to them. q.mu must be held.
+func (q *DeliveryQueue) makeRoom() ([]Delivery, error) {
+	switch q.opts.Overflow {
+	case OverflowDropOldest:
+		oldest := q.items[0]
+		q.items[0] = Delivery{}
+		q.items = q.items[1:]
+		q.stats.Dropped++
+		return []Delivery{oldest}, ErrDeliveryQueueFull
+	case OverflowDropExpired:
+		now := q.now()
+		var dropped []Delivery
+		kept := q.items[:0]
+		for _, d := range q.items {
+			if d.expired(now) {
+				q.stats.Expired += len(d.Messages)
+				dropped = append(dropped, d)
+				continue
+			}
+			kept = append(kept, d)
+		}
+		clear(q.items[len(kept):])
+		q.items = kept
+		return dropped, ErrDeliveryExpired
+	}
+	return nil, nil
+}
+
+// drop logs the deliveries makeRoom removed and reports reason to them.
+// q.mu must not be held.
+func (q *DeliveryQueue) drop(ctx context.Context, dropped []Delivery, reason error) {
+	for _, d := range dropped {
+		if reason == ErrDeliveryQueueFull {
+			q.opts.Logger.WarnContext(ctx, "delivery queue full, dropping oldest", "symbol", d.Symbol)
+		} else {
+			q.opts.Logger.InfoContext(ctx, "discarding expired delivery", "symbol", d.Symbol)
+		}
+		finish(d, reason)
+	}
+}
+
+// finish reports err to d's Done callback, if any.
+func finish(d Delivery, err error) {
+	if d.Done != nil {
+		d.Done(err)
+	}
+}
+
+// Run starts the workers and blocks until ctx is done. Queued deliveries are
+// abandoned on return.
+func (q *DeliveryQueue) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for range q.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (q *DeliveryQueue) work(ctx context.Context) {
+	for {
+		d, ok := q.next()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.ready:
+			}
+			continue
+		}
+		q.deliver(ctx, d)
+	}
+}
+
+// next pops the oldest delivery.
+func (q *DeliveryQueue) next() (Delivery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return Delivery{}, false
+	}
+	d := q.items[0]
+	q.items[0] = Delivery{}
+	q.items = q.items[1:]
+	if len(q.items) > 0 {
+		notify(q.ready)
+	}
+	notify(q.space)
+	return d, true
+}
+
+// deliver publishes the messages of d that have not expired, retrying as
+// configured.
+func (q *DeliveryQueue) deliver(ctx context.Context, d Delivery) {
+	now := q.now()
+	live := make([]OutboundMessage, 0, len(d.Messages))
+	for _, m := range d.Messages {
+		if !m.expired(now) {
+			live = append(live, m)
+		}
+	}
+	if expired := len(d.Messages) - len(live); expired > 0 {
+		q.opts.Logger.InfoContext(ctx, "discarding expired messages", "symbol", d.Symbol, "messages", expired)
+		q.mu.Lock()
+		q.stats.Expired += expired
+		q.mu.Unlock()
+	}
+	if len(live) == 0 {
+		finish(d, ErrDeliveryExpired)
+		return
+	}
+
+	err := publishWithRetry(ctx, q.publisher, q.opts.Logger, q.opts.Retry, Delivery{Symbol: d.Symbol, Messages: live}, q.now)
+	q.mu.Lock()
+	if err != nil {
+		q.stats.Failed++
+	} else {
+		q.stats.Delivered++
+	}
+	q.mu.Unlock()
+	if err != nil {
+		q.opts.Logger.ErrorContext(ctx, "publish telegram", "symbol", d.Symbol, "error", err)
+	}
+	finish(d, err)
+}
+
+// Stats returns the current queue length and delivery counters.
+func (q *DeliveryQueue) Stats() DeliveryQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	st := q.stats
+	st.Queued = len(q.items)
+	return st
+}
+
+// notify performs a non-blocking send on a one-slot notification channel.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,239 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedPublisher records messages and blocks each publish until gate yields.
+type gatedPublisher struct {
+	gate chan struct{}
+
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (p *gatedPublisher) PublishMessages(ctx context.Context, msgs []string) error {
+	if p.gate != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.gate:
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+
+func (p *gatedPublisher) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.msgs...)
+}
+
+func newTestQueue(pub *gatedPublisher, opts DeliveryQueueOptions) *DeliveryQueue {
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewDeliveryQueue(pub, opts)
+}
+
+func msgs(texts ...string) []OutboundMessage {
+	out := make([]OutboundMessage, len(texts))
+	for i, t := range texts {
+		out[i].Text = t
+	}
+	return out
+}
+
+func TestDeliveryQueue_DeliversInOrder(t *testing.T) {
+	pub := &gatedPublisher{}
+	q := newTestQueue(pub, DeliveryQueueOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	done := make(chan error, 1)
+	if err := q.Enqueue(ctx, Delivery{Symbol: "EURUSD", Messages: msgs("a", "b")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := q.Enqueue(ctx, Delivery{Symbol: "GBPUSD", Messages: msgs("c"), Done: func(err error) { done <- err }}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("delivery not completed")
+	}
+	if got := pub.published(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("expected ordered delivery, got %q", got)
+	}
+	if st := q.Stats(); st.Delivered != 2 || st.Queued != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestDeliveryQueue_DiscardsExpired(t *testing.T) {
+	pub := &gatedPublisher{}
+	q := newTestQueue(pub, DeliveryQueueOptions{})
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	err := q.Enqueue(ctx, Delivery{
+		Symbol: "EURUSD",
+		Messages: []OutboundMessage{
+			{Text: "stale", Expires: now.Add(-time.Second)},
+			{Text: "fresh", Expires: now.Add(time.Minute)},
+			{Text: "resolution"},
+		},
+		Done: func(error) { close(done) },
+	})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	go q.Run(ctx)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("delivery not completed")
+	}
+	if got := pub.published(); !reflect.DeepEqual(got, []string{"fresh", "resolution"}) {
+		t.Fatalf("expected stale message discarded, got %q", got)
+	}
+	if st := q.Stats(); st.Expired != 1 {
+		t.Fatalf("expected one expired message, got %+v", st)
+	}
+}
+
+func TestDeliveryQueue_Overflow(t *testing.T) {
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	stale := Delivery{Symbol: "EURUSD", Messages: []OutboundMessage{{Text: "stale", Expires: now.Add(-time.Second)}}}
+	fresh := Delivery{Symbol: "EURUSD", Messages: []OutboundMessage{{Text: "fresh", Expires: now.Add(time.Minute)}}}
+	next := Delivery{Symbol: "GBPUSD", Messages: msgs("next")}
+
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		queued  []Delivery
+		err     error
+		want    []string
+		dropped int
+		// done maps the text of each delivery whose Done was called to the
+		// error it got.
+		done map[string]error
+	}{
+		{name: "drop oldest", policy: OverflowDropOldest, queued: []Delivery{fresh, stale}, want: []string{"stale", "next"}, dropped: 1,
+			done: map[string]error{"fresh": ErrDeliveryQueueFull}},
+		{name: "drop expired", policy: OverflowDropExpired, queued: []Delivery{fresh, stale}, want: []string{"fresh", "next"},
+			done: map[string]error{"stale": ErrDeliveryExpired}},
+		{name: "drop expired without expired", policy: OverflowDropExpired, queued: []Delivery{fresh, fresh}, err: ErrDeliveryQueueFull, want: []string{"fresh", "fresh"}, dropped: 1,
+			done: map[string]error{"next": ErrDeliveryQueueFull}},
+		{name: "block", policy: OverflowBlock, queued: []Delivery{fresh, stale}, err: context.DeadlineExceeded, want: []string{"fresh", "stale"},
+			done: map[string]error{"next": context.DeadlineExceeded}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(&gatedPublisher{}, DeliveryQueueOptions{Capacity: 2, Overflow: tt.policy})
+			q.now = func() time.Time { return now }
+			done := make(map[string]error)
+			track := func(d Delivery) Delivery {
+				d.Done = func(err error) { done[d.Messages[0].Text] = err }
+				return d
+			}
+			for _, d := range tt.queued {
+				if err := q.Enqueue(context.Background(), track(d)); err != nil {
+					t.Fatalf("enqueue: %v", err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if err := q.Enqueue(ctx, track(next)); !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if len(done) != len(tt.done) {
+				t.Fatalf("expected Done called for %v, got %v", tt.done, done)
+			}
+			for text, want := range tt.done {
+				if err, ok := done[text]; !ok || !errors.Is(err, want) {
+					t.Fatalf("expected Done(%v) for %q, got %v", want, text, done)
+				}
+			}
+
+			var got []string
+			for _, d := range q.items {
+				got = append(got, d.Messages[0].Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected queue %q, got %q", tt.want, got)
+			}
+			if st := q.Stats(); st.Dropped != tt.dropped {
+				t.Fatalf("expected %d dropped, got %+v", tt.dropped, st)
+			}
+		})
+	}
+}
+
+func TestDeliveryQueue_BlockWaitsForRoom(t *testing.T) {
+	pub := &gatedPublisher{gate: make(chan struct{})}
+	q := newTestQueue(pub, DeliveryQueueOptions{Capacity: 1, Overflow: OverflowBlock})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	for _, text := range []string{"a", "b"} {
+		if err := q.Enqueue(ctx, Delivery{Messages: msgs(text)}); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	blocked := make(chan error, 1)
+	go func() { blocked <- q.Enqueue(ctx, Delivery{Messages: msgs("c")}) }()
+	select {
+	case err := <-blocked:
+		t.Fatalf("expected enqueue to block, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	pub.gate <- struct{}{}
+	if err := <-blocked; err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	pub.gate <- struct{}{}
+	pub.gate <- struct{}{}
+	deadline := time.Now().Add(time.Second)
+	for len(pub.published()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pub.published(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("expected all messages delivered, got %q", got)
+	}
+}
+
+func TestDeliveryQueue_DoneOnExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	pub := &gatedPublisher{}
+	q := newTestQueue(pub, DeliveryQueueOptions{})
+	q.now = func() time.Time { return now }
+	var got error
+	q.deliver(context.Background(), Delivery{
+		Symbol:   "EURUSD",
+		Messages: []OutboundMessage{{Text: "stale", Expires: now}},
+		Done:     func(err error) { got = err },
+	})
+	if !errors.Is(got, ErrDeliveryExpired) || len(pub.published()) != 0 {
+		t.Fatalf("expected ErrDeliveryExpired without publishing, got %v and %q", got, pub.published())
+	}
+}
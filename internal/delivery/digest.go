@@ -0,0 +1,239 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// DigestOptions configures a Digest.
+type DigestOptions struct {
+	// MinConfidence is the confidence from which signals are published at
+	// once. Signals below it are held for the next digest.
+	MinConfidence float64
+	// Interval is the time between digests. Defaults to 15 minutes.
+	Interval time.Duration
+	// MaxSignals sends the digest early once it holds this many signals.
+	// Defaults to 30.
+	MaxSignals int
+	// Paused, when set, reports whether publishing is paused, e.g. with
+	// Orchestrator.Pause. Digests falling due while it returns true are
+	// discarded rather than sent.
+	Paused func() bool
+	Logger *slog.Logger
+}
+
+func (o DigestOptions) withDefaults() DigestOptions {
+	if o.Interval <= 0 {
+		o.Interval = 15 * time.Minute
+	}
+	if o.MaxSignals <= 0 {
+		o.MaxSignals = 30
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	return o
+}
+
+// Digest implements ports.SignalPublisher in front of one destination. It
+// publishes signals at or above MinConfidence immediately and batches the
+// others into a summary sent every Interval. The summary goes through
+// ports.DigestPublisher when the destination implements it, and as ordinary
+// per-symbol batches otherwise.
+//
+// Confirmations and cancellations follow the confidence of their early
+// signal. One resolving a signal still held updates it in place, so a
+// cancelled early signal never reaches the digest. Outcomes are forwarded
+// only for signals published immediately.
+type Digest struct {
+	next ports.SignalPublisher
+	opts DigestOptions
+
+	mu      sync.Mutex
+	pending []entity.Signal
+}
+
+// NewDigest returns a digest publishing to next. The caller runs d.Run to
+// send the periodic summaries.
+func NewDigest(next ports.SignalPublisher, opts DigestOptions) *Digest {
+	return &Digest{next: next, opts: opts.withDefaults()}
+}
+
+// PublishSignals publishes the signals at or above MinConfidence and holds
+// the others. A full digest is sent straight away.
+func (d *Digest) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	return d.PublishSignalCharts(ctx, signals, nil)
+}
+
+// PublishSignalCharts behaves like PublishSignals and passes the charts of
+// immediate signals on when the destination implements
+// ports.ChartPublisher. Charts of held signals are dropped.
+func (d *Digest) PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error {
+	var (
+		now       []entity.Signal
+		nowCharts [][]byte
+	)
+	for i, s := range signals {
+		if s.Confidence >= d.opts.MinConfidence {
+			now = append(now, s)
+			if i < len(charts) {
+				nowCharts = append(nowCharts, charts[i])
+			} else {
+				nowCharts = append(nowCharts, nil)
+			}
+			continue
+		}
+		d.hold(s)
+	}
+
+	var errs []error
+	if len(now) > 0 {
+		if p, ok := d.next.(ports.ChartPublisher); ok && len(charts) > 0 {
+			errs = append(errs, p.PublishSignalCharts(ctx, now, nowCharts))
+		} else {
+			errs = append(errs, d.next.PublishSignals(ctx, now))
+		}
+	}
+	if d.full() {
+		errs = append(errs, d.Flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// PublishOutcomes forwards the outcomes of signals published immediately
+// when the destination implements ports.OutcomePublisher.
+func (d *Digest) PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error {
+	p, ok := d.next.(ports.OutcomePublisher)
+	if !ok {
+		return nil
+	}
+	var forward []entity.SignalOutcome
+	for _, o := range outcomes {
+		if o.Signal.Confidence >= d.opts.MinConfidence {
+			forward = append(forward, o)
+		}
+	}
+	if len(forward) == 0 {
+		return nil
+	}
+	return p.PublishOutcomes(ctx, forward)
+}
+
+// Pending returns the number of signals held for the next digest.
+func (d *Digest) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// Flush sends the held signals as one digest. Signals are dropped when the
+// destination fails, since it retries on its own.
+func (d *Digest) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	signals := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	if len(signals) == 0 {
+		return nil
+	}
+	if p, ok := d.next.(ports.DigestPublisher); ok {
+		return p.PublishDigest(ctx, signals)
+	}
+	var errs []error
+	for _, batch := range bySymbol(signals) {
+		errs = append(errs, d.next.PublishSignals(ctx, batch))
+	}
+	return errors.Join(errs...)
+}
+
+// Run sends a digest every Interval until ctx is done. Digests due while
+// Paused reports true, and signals still held on return, are discarded.
+func (d *Digest) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if d.opts.Paused != nil && d.opts.Paused() {
+				if n := d.discard(); n > 0 {
+					d.opts.Logger.InfoContext(ctx, "digest discarded while paused", "signals", n)
+				}
+				continue
+			}
+			n := d.Pending()
+			if err := d.Flush(ctx); err != nil {
+				d.opts.Logger.ErrorContext(ctx, "publish digest", "signals", n, "error", err)
+			}
+		}
+	}
+}
+
+// hold adds s to the next digest, merging a resolution into the early
+// signal it resolves so that the digest shows the confirmed signal.
+func (d *Digest) hold(s entity.Signal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s.Stage == entity.StageConfirmed || s.Stage == entity.StageCancelled {
+		id := s.ID()
+		i := slices.IndexFunc(d.pending, func(p entity.Signal) bool { return p.Stage == entity.StageEarly && p.ID() == id })
+		if i >= 0 {
+			if s.Stage == entity.StageCancelled {
+				d.pending = slices.Delete(d.pending, i, i+1)
+			} else {
+				d.pending[i] = s
+			}
+			return
+		}
+	}
+	d.pending = append(d.pending, s)
+}
+
+// discard drops the held signals and returns how many there were.
+func (d *Digest) discard() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := len(d.pending)
+	d.pending = nil
+	return n
+}
+
+func (d *Digest) full() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending) >= d.opts.MaxSignals
+}
+
+// bySymbol splits signals into per-symbol batches, in order of first
+// appearance.
+func bySymbol(signals []entity.Signal) [][]entity.Signal {
+	var (
+		keys    []string
+		batches [][]entity.Signal
+	)
+	for _, s := range signals {
+		key := entity.SymbolKey(s.Symbol)
+		i := slices.Index(keys, key)
+		if i < 0 {
+			keys = append(keys, key)
+			batches = append(batches, nil)
+			i = len(keys) - 1
+		}
+		batches[i] = append(batches[i], s)
+	}
+	return batches
+}
+
+var (
+	_ ports.SignalPublisher  = (*Digest)(nil)
+	_ ports.OutcomePublisher = (*Digest)(nil)
+	_ ports.ChartPublisher   = (*Digest)(nil)
+)
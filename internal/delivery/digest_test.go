@@ -0,0 +1,171 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestDigest_TelegramSink(t *testing.T) {
+	bar := time.Date(2024, 1, 3, 13, 30, 0, 0, time.UTC)
+	high := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute, Time: bar}
+	low := entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.5, TTL: 2 * time.Minute, Time: bar, Source: "candlestick"}
+	cancelled := entity.Signal{Symbol: "GBPUSD", Direction: "UP", Confidence: 0.5, TTL: 2 * time.Minute, Time: bar, Stage: entity.StageEarly}
+	confirmed := entity.Signal{Symbol: "USDJPY", Direction: "UP", Confidence: 0.55, TTL: 2 * time.Minute, Time: bar.Add(time.Minute),
+		Source: "ema_interaction", Stage: entity.StageEarly}
+
+	pub := &gatedPublisher{}
+	d := NewDigest(NewTelegramSink(pub, TelegramSinkOptions{}), DigestOptions{MinConfidence: 0.7})
+	ctx := context.Background()
+	for _, batch := range [][]entity.Signal{{high, low}, {cancelled}, {confirmed}} {
+		if err := d.PublishSignals(ctx, batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancelled.Stage, confirmed.Stage = entity.StageCancelled, entity.StageConfirmed
+	confirmed.Price = 151.2
+	for _, s := range []entity.Signal{cancelled, confirmed} {
+		if err := d.PublishSignals(ctx, []entity.Signal{s}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := pub.published(); !reflect.DeepEqual(got, FormatSignals([]entity.Signal{high})) {
+		t.Fatalf("expected only the high-confidence signal, got %q", got)
+	}
+	if got := d.Pending(); got != 2 {
+		t.Fatalf("expected 2 held signals, got %d", got)
+	}
+	if got := d.pending[1]; !reflect.DeepEqual(got, confirmed) {
+		t.Fatalf("expected the confirmation to replace its early signal, got %+v", got)
+	}
+
+	if err := d.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := "📋 Digest: 2 low-confidence signals\n" +
+		"• 13:30 UTC EURUSD DOWN 50% (candlestick pattern)\n" +
+		"✅ 13:31 UTC USDJPY UP 55% (EMA interaction)"
+	if got := pub.published(); len(got) != 2 || got[1] != want {
+		t.Fatalf("expected digest %q, got %q", want, got)
+	}
+	if err := d.Flush(ctx); err != nil || len(pub.published()) != 2 {
+		t.Fatalf("expected an empty flush to send nothing, got %v", err)
+	}
+
+	// A resolution whose early signal went out in an earlier digest joins
+	// the next one.
+	if err := d.PublishSignals(ctx, []entity.Signal{cancelled}); err != nil || d.Pending() != 1 {
+		t.Fatalf("expected the cancellation to be held, got %v", err)
+	}
+}
+
+func TestDigest_Fallback(t *testing.T) {
+	next := &testutils.MockSignalPublisher{}
+	d := NewDigest(next, DigestOptions{MinConfidence: 0.7, MaxSignals: 3})
+	ctx := context.Background()
+	eur := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.5}
+	gbp := entity.Signal{Symbol: "GBPUSD", Direction: "UP", Confidence: 0.5}
+	high := entity.Signal{Symbol: "GBPUSD", Direction: "DOWN", Confidence: 0.9}
+
+	if err := d.PublishSignalCharts(ctx, []entity.Signal{eur, high}, [][]byte{{1}, {2}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.Charts(); !reflect.DeepEqual(got, [][]byte{{2}}) {
+		t.Fatalf("expected the immediate signal's chart, got %v", got)
+	}
+	// The third held signal fills the digest, which goes out per symbol.
+	if err := d.PublishSignals(ctx, []entity.Signal{gbp, eur}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]entity.Signal{{high}, {eur, eur}, {gbp}}
+	if got := next.Batches(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected batches %+v, got %+v", want, got)
+	}
+
+	outcomes := []entity.SignalOutcome{{Signal: eur, Outcome: "WIN"}, {Signal: high, Outcome: "LOSS"}}
+	if err := d.PublishOutcomes(ctx, outcomes); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.Outcomes(); !reflect.DeepEqual(got, outcomes[1:]) {
+		t.Fatalf("expected only the immediate signal's outcome, got %+v", got)
+	}
+}
+
+func TestDigest_Run(t *testing.T) {
+	next := &testutils.MockSignalPublisher{}
+	d := NewDigest(next, DigestOptions{
+		MinConfidence: 0.7,
+		Interval:      10 * time.Millisecond,
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+
+	s := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.5}
+	if err := d.PublishSignals(ctx, []entity.Signal{s}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(next.Signals()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("digest not sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDigest_RunPaused(t *testing.T) {
+	next := &testutils.MockSignalPublisher{}
+	var paused atomic.Bool
+	paused.Store(true)
+	d := NewDigest(next, DigestOptions{
+		MinConfidence: 0.7,
+		Interval:      10 * time.Millisecond,
+		Paused:        paused.Load,
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+	wait := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatal(what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	s := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.5}
+	if err := d.PublishSignals(ctx, []entity.Signal{s}); err != nil {
+		t.Fatal(err)
+	}
+	wait("digest not discarded", func() bool { return d.Pending() == 0 })
+	if got := next.Signals(); len(got) != 0 {
+		t.Fatalf("expected nothing sent while paused, got %+v", got)
+	}
+
+	paused.Store(false)
+	if err := d.PublishSignals(ctx, []entity.Signal{s}); err != nil {
+		t.Fatal(err)
+	}
+	wait("digest not sent after resume", func() bool { return len(next.Signals()) == 1 })
+	cancel()
+	<-done
+}
@@ -0,0 +1,243 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// SinkFilter selects the signals a sink receives. Empty fields match
+// everything.
+type SinkFilter struct {
+	// Symbols in any common spelling, e.g. "EUR/USD" or "EURUSD".
+	Symbols    []string
+	Directions []string
+	Stages     []entity.SignalStage
+	// MinConfidence drops signals below this confidence. Confirmations and
+	// cancellations carry the confidence of their early signal, so they reach
+	// exactly the sinks the early signal did.
+	MinConfidence float64
+}
+
+// Match reports whether s passes the filter.
+func (f SinkFilter) Match(s entity.Signal) bool {
+	if len(f.Stages) > 0 && !slices.Contains(f.Stages, s.Stage) {
+		return false
+	}
+	rule := entity.RoutingRule{Symbols: f.Symbols, Directions: f.Directions, MinConfidence: f.MinConfidence}
+	return rule.Match(s, nil)
+}
+
+// Sink is a named notification channel in a FanOut.
+type Sink struct {
+	Name      string
+	Publisher ports.SignalPublisher
+	Filter    SinkFilter
+	// Timeout bounds each publish to this sink. Zero means no limit.
+	Timeout time.Duration
+	// Retry retries failed publishes to this sink and stores what still
+	// fails as a dead letter, as for Telegram deliveries. Signals expire
+	// after their TTL; the text kept is the built-in Telegram layout. The
+	// zero value publishes once.
+	Retry RetryOptions
+}
+
+// FanOut implements ports.SignalPublisher by dispatching signals to several
+// sinks concurrently. Sinks are isolated from each other: a sink that fails,
+// times out or panics does not affect delivery to the others. Retries of one
+// sink hold up the call, but not delivery to the other sinks.
+type FanOut struct {
+	sinks  []Sink
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewFanOut returns a dispatcher over sinks.
+func NewFanOut(logger *slog.Logger, sinks ...Sink) *FanOut {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FanOut{sinks: sinks, logger: logger, now: time.Now}
+}
+
+// PublishSignals sends each sink the signals matching its filter and waits
+// for all of them. Failures are logged per sink. It returns an error only
+// when every sink that received signals failed, so that a single broken
+// channel does not hold back the others.
+func (f *FanOut) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	return dispatchSignals(ctx, f, signals, func(Sink, entity.Signal) bool { return true })
+}
+
+// PublishOutcomes sends outcomes to the sinks that implement
+// ports.OutcomePublisher and whose filter matches the outcome's signal.
+// Errors are reported as for PublishSignals.
+func (f *FanOut) PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error {
+	return dispatchOutcomes(ctx, f, outcomes, func(Sink, entity.Signal) bool { return true })
+}
+
+// PublishSignalCharts behaves like PublishSignals and passes each signal's
+// chart on to the sinks that implement ports.ChartPublisher.
+func (f *FanOut) PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error {
+	return dispatchCharts(ctx, f, signals, charts, func(Sink, entity.Signal) bool { return true })
+}
+
+func dispatchSignals(ctx context.Context, f *FanOut, signals []entity.Signal, match func(Sink, entity.Signal) bool) error {
+	return dispatch(ctx, f, signals, func(s entity.Signal) entity.Signal { return s }, match, signalMessage,
+		func(ctx context.Context, sink Sink, signals []entity.Signal) error {
+			return sink.Publisher.PublishSignals(ctx, signals)
+		})
+}
+
+// signalChart pairs a signal with its chart, which may be nil.
+type signalChart struct {
+	signal entity.Signal
+	chart  []byte
+}
+
+func dispatchCharts(ctx context.Context, f *FanOut, signals []entity.Signal, charts [][]byte, match func(Sink, entity.Signal) bool) error {
+	items := make([]signalChart, len(signals))
+	for i, s := range signals {
+		items[i].signal = s
+		if i < len(charts) {
+			items[i].chart = charts[i]
+		}
+	}
+	return dispatch(ctx, f, items, func(it signalChart) entity.Signal { return it.signal }, match,
+		func(it signalChart, now time.Time) OutboundMessage { return signalMessage(it.signal, now) },
+		func(ctx context.Context, sink Sink, items []signalChart) error {
+			signals := make([]entity.Signal, len(items))
+			charts := make([][]byte, len(items))
+			for i, it := range items {
+				signals[i], charts[i] = it.signal, it.chart
+			}
+			if p, ok := sink.Publisher.(ports.ChartPublisher); ok {
+				return p.PublishSignalCharts(ctx, signals, charts)
+			}
+			return sink.Publisher.PublishSignals(ctx, signals)
+		})
+}
+
+func dispatchOutcomes(ctx context.Context, f *FanOut, outcomes []entity.SignalOutcome, match func(Sink, entity.Signal) bool) error {
+	return dispatch(ctx, f, outcomes, func(o entity.SignalOutcome) entity.Signal { return o.Signal },
+		func(sink Sink, s entity.Signal) bool {
+			_, ok := sink.Publisher.(ports.OutcomePublisher)
+			return ok && match(sink, s)
+		},
+		func(o entity.SignalOutcome, _ time.Time) OutboundMessage {
+			return OutboundMessage{Text: FormatOutcome(o, nil)}
+		},
+		func(ctx context.Context, sink Sink, outcomes []entity.SignalOutcome) error {
+			return sink.Publisher.(ports.OutcomePublisher).PublishOutcomes(ctx, outcomes)
+		})
+}
+
+// signalMessage describes s for retries and dead letters. Signal and
+// early-signal messages expire after the signal's TTL.
+func signalMessage(s entity.Signal, now time.Time) OutboundMessage {
+	m := OutboundMessage{Text: FormatSignal(s, nil)}
+	if (s.Stage == entity.StageSignal || s.Stage == entity.StageEarly) && s.TTL > 0 {
+		m.Expires = now.Add(s.TTL)
+	}
+	return m
+}
+
+// dispatch sends each sink the items whose signal matches both its filter
+// and match, as described for PublishSignals. message describes an item for
+// retries and dead letters.
+func dispatch[T any](ctx context.Context, f *FanOut, items []T, signal func(T) entity.Signal, match func(Sink, entity.Signal) bool, message func(T, time.Time) OutboundMessage, send func(context.Context, Sink, []T) error) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errs     []error
+		attempts int
+	)
+	for _, sink := range f.sinks {
+		var matched []T
+		for _, it := range items {
+			if s := signal(it); sink.Filter.Match(s) && match(sink, s) {
+				matched = append(matched, it)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		attempts++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sendToSink(ctx, f, sink, matched, signal, message, send)
+			if err != nil {
+				f.logger.ErrorContext(ctx, "publish to sink", "sink", sink.Name, "items", len(matched), "error", err)
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if attempts > 0 && len(errs) == attempts {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// sendToSink sends items to sink, retrying and storing dead letters as
+// configured by sink.Retry.
+func sendToSink[T any](ctx context.Context, f *FanOut, sink Sink, items []T, signal func(T) entity.Signal, message func(T, time.Time) OutboundMessage, send func(context.Context, Sink, []T) error) error {
+	now := f.now()
+	msgs := make([]OutboundMessage, len(items))
+	var symbols []string
+	for i, it := range items {
+		msgs[i] = message(it, now)
+		if sym := signal(it).Symbol; !slices.Contains(symbols, sym) {
+			symbols = append(symbols, sym)
+		}
+	}
+	return sendWithRetry(ctx, f.logger, sink.Retry, strings.Join(symbols, ","), items, msgs, f.now,
+		func(ctx context.Context, items []T) (int, error) {
+			if err := f.publish(ctx, sink, func(ctx context.Context) error { return send(ctx, sink, items) }); err != nil {
+				return 0, fmt.Errorf("sink %s: %w", sink.Name, err)
+			}
+			return len(items), nil
+		})
+}
+
+// publish runs send for sink. It returns once send does or the sink's
+// timeout elapses, even if send ignores cancellation, and turns panics into
+// errors.
+func (f *FanOut) publish(ctx context.Context, sink Sink, send func(context.Context) error) error {
+	if sink.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sink.Timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- send(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var (
+	_ ports.SignalPublisher  = (*FanOut)(nil)
+	_ ports.OutcomePublisher = (*FanOut)(nil)
+	_ ports.ChartPublisher   = (*FanOut)(nil)
+)
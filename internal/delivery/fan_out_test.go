@@ -0,0 +1,225 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestSinkFilter_Match(t *testing.T) {
+	up := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.6}
+	tests := []struct {
+		name   string
+		filter SinkFilter
+		signal entity.Signal
+		want   bool
+	}{
+		{name: "empty matches all", signal: up, want: true},
+		{name: "symbol spelling", filter: SinkFilter{Symbols: []string{"EUR/USD"}}, signal: up, want: true},
+		{name: "other symbol", filter: SinkFilter{Symbols: []string{"GBPUSD"}}, signal: up, want: false},
+		{name: "direction", filter: SinkFilter{Directions: []string{"DOWN"}}, signal: up, want: false},
+		{name: "stage", filter: SinkFilter{Stages: []entity.SignalStage{entity.StageEarly}}, signal: up, want: false},
+		{name: "below confidence", filter: SinkFilter{MinConfidence: 0.7}, signal: up, want: false},
+		{
+			name:   "resolution below confidence",
+			filter: SinkFilter{MinConfidence: 0.7},
+			signal: entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.6, Stage: entity.StageConfirmed},
+			want:   false,
+		},
+		{
+			name:   "resolution at confidence",
+			filter: SinkFilter{MinConfidence: 0.7},
+			signal: entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.7, Stage: entity.StageConfirmed},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(tt.signal); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFanOut_PublishSignals(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.9},
+		{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.5},
+	}
+
+	all := &testutils.MockSignalPublisher{}
+	strong := &testutils.MockSignalPublisher{}
+	gbp := &testutils.MockSignalPublisher{}
+	failing := &testutils.MockSignalPublisher{Err: errors.New("webhook status 500")}
+	panicking := ports.SignalPublisherFunc(func(context.Context, []entity.Signal) error { panic("boom") })
+	hanging := ports.SignalPublisherFunc(func(context.Context, []entity.Signal) error { select {} })
+
+	f := NewFanOut(logger,
+		Sink{Name: "all", Publisher: all},
+		Sink{Name: "strong", Publisher: strong, Filter: SinkFilter{MinConfidence: 0.8}},
+		Sink{Name: "gbp", Publisher: gbp, Filter: SinkFilter{Symbols: []string{"GBPUSD"}}},
+		Sink{Name: "failing", Publisher: failing},
+		Sink{Name: "panicking", Publisher: panicking},
+		Sink{Name: "hanging", Publisher: hanging, Timeout: 10 * time.Millisecond},
+	)
+	if err := f.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatalf("expected partial failure to be isolated, got %v", err)
+	}
+	if got := all.Signals(); !reflect.DeepEqual(got, signals) {
+		t.Fatalf("expected all signals, got %+v", got)
+	}
+	if got := strong.Signals(); !reflect.DeepEqual(got, signals[:1]) {
+		t.Fatalf("expected strong signal only, got %+v", got)
+	}
+	if got := gbp.Batches(); len(got) != 0 {
+		t.Fatalf("expected no signals for filtered sink, got %+v", got)
+	}
+
+	broken := NewFanOut(logger,
+		Sink{Name: "failing", Publisher: failing},
+		Sink{Name: "panicking", Publisher: panicking},
+		Sink{Name: "idle", Publisher: gbp, Filter: SinkFilter{Symbols: []string{"GBPUSD"}}},
+	)
+	if err := broken.PublishSignals(context.Background(), signals); err == nil {
+		t.Fatalf("expected error when every sink fails")
+	}
+}
+
+func TestFanOut_PublishOutcomes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	outcomes := []entity.SignalOutcome{
+		{Signal: entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.9}, Outcome: "WIN"},
+		{Signal: entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.5}, Outcome: "LOSS"},
+	}
+
+	all := &testutils.MockSignalPublisher{}
+	strong := &testutils.MockSignalPublisher{}
+	signalsOnly := ports.SignalPublisherFunc(func(context.Context, []entity.Signal) error {
+		t.Error("outcomes sent to a sink without follow-up support")
+		return nil
+	})
+
+	f := NewFanOut(logger,
+		Sink{Name: "all", Publisher: all},
+		Sink{Name: "strong", Publisher: strong, Filter: SinkFilter{MinConfidence: 0.8}},
+		Sink{Name: "signals-only", Publisher: signalsOnly},
+	)
+	if err := f.PublishOutcomes(context.Background(), outcomes); err != nil {
+		t.Fatalf("publish outcomes: %v", err)
+	}
+	if got := all.Outcomes(); !reflect.DeepEqual(got, outcomes) {
+		t.Fatalf("expected all outcomes, got %+v", got)
+	}
+	if got := strong.Outcomes(); !reflect.DeepEqual(got, outcomes[:1]) {
+		t.Fatalf("expected the strong signal's outcome only, got %+v", got)
+	}
+}
+
+func TestFanOut_PublishSignalCharts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.9},
+		{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.5},
+	}
+	charts := [][]byte{[]byte("up"), nil}
+
+	all := &testutils.MockSignalPublisher{}
+	strong := &testutils.MockSignalPublisher{}
+	var plain []entity.Signal
+	f := NewFanOut(logger,
+		Sink{Name: "all", Publisher: all},
+		Sink{Name: "strong", Publisher: strong, Filter: SinkFilter{MinConfidence: 0.8}},
+		Sink{Name: "plain", Publisher: ports.SignalPublisherFunc(func(_ context.Context, s []entity.Signal) error {
+			plain = s
+			return nil
+		})},
+	)
+	if err := f.PublishSignalCharts(context.Background(), signals, charts); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if got := all.Charts(); !reflect.DeepEqual(got, charts) {
+		t.Fatalf("expected all charts, got %q", got)
+	}
+	if got := strong.Charts(); !reflect.DeepEqual(got, charts[:1]) || !reflect.DeepEqual(strong.Signals(), signals[:1]) {
+		t.Fatalf("expected the strong signal's chart only, got %q", got)
+	}
+	if !reflect.DeepEqual(plain, signals) {
+		t.Fatalf("expected the signals without charts, got %+v", plain)
+	}
+}
+
+func TestFanOut_Retry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.9, TTL: 2 * time.Minute, Time: now},
+		{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.8, TTL: time.Millisecond, Time: now},
+	}
+	flaky := func(fail int, calls *int) ports.SignalPublisher {
+		return ports.SignalPublisherFunc(func(context.Context, []entity.Signal) error {
+			*calls++
+			if *calls <= fail {
+				return errors.New("webhook status 503")
+			}
+			return nil
+		})
+	}
+
+	tests := []struct {
+		name   string
+		fail   int
+		retry  RetryOptions
+		calls  int
+		letter []string
+	}{
+		{name: "no retries", fail: 1, calls: 1, letter: []string{FormatSignal(signals[0], nil), FormatSignal(signals[1], nil)}},
+		{name: "recovers", fail: 1, retry: RetryOptions{MaxAttempts: 3, Backoff: fixedBackoff(time.Millisecond)}, calls: 2},
+		{
+			// The short-lived signal expires before the retry.
+			name:   "dead letter",
+			fail:   5,
+			retry:  RetryOptions{MaxAttempts: 2, Backoff: fixedBackoff(10 * time.Millisecond)},
+			calls:  2,
+			letter: []string{FormatSignal(signals[0], nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &testutils.MockDeadLetterStore{}
+			tt.retry.DeadLetters = store
+			var calls int
+			other := &testutils.MockSignalPublisher{}
+			f := NewFanOut(logger,
+				Sink{Name: "webhook", Publisher: flaky(tt.fail, &calls), Retry: tt.retry},
+				Sink{Name: "other", Publisher: other},
+			)
+			f.now = func() time.Time { return now }
+			if err := f.PublishSignals(context.Background(), signals); err != nil {
+				t.Fatalf("expected the failure to stay isolated, got %v", err)
+			}
+			if calls != tt.calls || len(other.Batches()) != 1 {
+				t.Fatalf("expected %d webhook calls and one other batch, got %d and %d", tt.calls, calls, len(other.Batches()))
+			}
+			letters, _ := store.List(context.Background())
+			if tt.letter == nil {
+				if len(letters) != 0 {
+					t.Fatalf("expected no dead letter, got %+v", letters)
+				}
+				return
+			}
+			if len(letters) != 1 || letters[0].Symbol != "EURUSD" || !reflect.DeepEqual(letters[0].Messages, tt.letter) ||
+				!strings.Contains(letters[0].Error, "sink webhook") {
+				t.Fatalf("expected a dead letter with %q, got %+v", tt.letter, letters)
+			}
+		})
+	}
+}
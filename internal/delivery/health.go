@@ -0,0 +1,135 @@
+package delivery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// DefaultMaxCandleAge is the readiness threshold for the time since a symbol
+// last received a candle.
+const DefaultMaxCandleAge = 2 * time.Minute
+
+// HealthCheck is the result of a single named check.
+type HealthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// HealthReport aggregates checks for a liveness or readiness probe.
+type HealthReport struct {
+	OK     bool          `json:"ok"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+func newHealthReport(checks ...HealthCheck) HealthReport {
+	rep := HealthReport{OK: true, Checks: checks}
+	for _, c := range checks {
+		if !c.OK {
+			rep.OK = false
+		}
+	}
+	return rep
+}
+
+// HealthChecker evaluates liveness and readiness from the Orchestrator's
+// per-symbol state and, when available, the feed's health.
+type HealthChecker struct {
+	orch         *Orchestrator
+	feed         ports.FeedHealthReporter
+	symbols      []string
+	maxCandleAge time.Duration
+	now          func() time.Time
+}
+
+// NewHealthChecker returns a HealthChecker for symbols. feed may be nil when
+// the market feed does not report health. A non-positive maxCandleAge uses
+// DefaultMaxCandleAge.
+func NewHealthChecker(orch *Orchestrator, feed ports.FeedHealthReporter, symbols []string, maxCandleAge time.Duration) *HealthChecker {
+	if maxCandleAge <= 0 {
+		maxCandleAge = DefaultMaxCandleAge
+	}
+	return &HealthChecker{
+		orch:         orch,
+		feed:         feed,
+		symbols:      symbols,
+		maxCandleAge: maxCandleAge,
+		now:          time.Now,
+	}
+}
+
+// Liveness reports whether the Orchestrator loop is running and the feed has
+// not stopped.
+func (h *HealthChecker) Liveness() HealthReport {
+	st := h.orch.Status()
+	checks := []HealthCheck{{Name: "orchestrator", OK: st.Running}}
+	if !st.Running {
+		checks[0].Detail = "not running"
+	}
+	if h.feed != nil {
+		fh := h.feed.FeedHealth()
+		checks = append(checks, HealthCheck{Name: "feed", OK: fh.State != ports.FeedStopped, Detail: string(fh.State)})
+	}
+	return newHealthReport(checks...)
+}
+
+// Readiness reports whether the feed is connected, every symbol received a
+// candle within the configured age, enough bars are buffered to score and the
+// last publish succeeded.
+func (h *HealthChecker) Readiness() HealthReport {
+	st := h.orch.Status()
+	now := h.now()
+
+	checks := []HealthCheck{{Name: "orchestrator", OK: st.Running}}
+	if !st.Running {
+		checks[0].Detail = "not running"
+	}
+
+	if h.feed != nil {
+		fh := h.feed.FeedHealth()
+		checks = append(checks, HealthCheck{
+			Name:   "feed",
+			OK:     fh.Healthy(),
+			Detail: fmt.Sprintf("%s since %s", fh.State, fh.Since.Format(time.RFC3339)),
+		})
+	}
+
+	var stale, short []string
+	for _, sym := range h.symbols {
+		ss, ok := st.Symbols[sym]
+		if !ok || now.Sub(ss.LastReceived) > h.maxCandleAge {
+			stale = append(stale, sym)
+		}
+		if need, _ := h.orch.bars(sym); ss.Buffered < need {
+			short = append(short, fmt.Sprintf("%s=%d/%d", sym, ss.Buffered, need))
+		}
+	}
+	sort.Strings(stale)
+	sort.Strings(short)
+	checks = append(checks,
+		HealthCheck{Name: "candles", OK: len(stale) == 0, Detail: listDetail("stale", stale)},
+		HealthCheck{Name: "buffers", OK: len(short) == 0, Detail: listDetail("below minimum bars", short)},
+	)
+
+	pub := HealthCheck{Name: "publish", OK: st.PublishError == ""}
+	switch {
+	case st.PublishError != "":
+		pub.Detail = st.PublishError
+	case st.LastPublish.IsZero():
+		pub.Detail = "no publish yet"
+	}
+	checks = append(checks, pub)
+
+	return newHealthReport(checks...)
+}
+
+func listDetail(label string, items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return label + ": " + strings.Join(items, ", ")
+}
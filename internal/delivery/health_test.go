@@ -0,0 +1,146 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+type staticHealth struct{ h ports.FeedHealth }
+
+func (s staticHealth) FeedHealth() ports.FeedHealth { return s.h }
+
+func (s staticHealth) StateChanges(ctx context.Context) <-chan ports.FeedStateChange {
+	ch := make(chan ports.FeedStateChange)
+	close(ch)
+	return ch
+}
+
+func TestHealthChecker_Readiness(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ready := OrchestratorStatus{
+		Running: true,
+		Symbols: map[string]SymbolStatus{
+			"EURUSD": {LastReceived: now.Add(-30 * time.Second), Buffered: 50},
+			"GBPUSD": {LastReceived: now.Add(-10 * time.Second), Buffered: 20},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		status func(OrchestratorStatus) OrchestratorStatus
+		feed   ports.FeedState
+		failed []string
+	}{
+		{name: "ready", status: func(s OrchestratorStatus) OrchestratorStatus { return s }, feed: ports.FeedSubscribed},
+		{name: "feed backing off", status: func(s OrchestratorStatus) OrchestratorStatus { return s }, feed: ports.FeedBackingOff, failed: []string{"feed"}},
+		{
+			name: "stale symbol",
+			status: func(s OrchestratorStatus) OrchestratorStatus {
+				s.Symbols = map[string]SymbolStatus{"EURUSD": s.Symbols["EURUSD"], "GBPUSD": {LastReceived: now.Add(-5 * time.Minute), Buffered: 20}}
+				return s
+			},
+			feed:   ports.FeedSubscribed,
+			failed: []string{"candles"},
+		},
+		{
+			name: "missing symbol",
+			status: func(s OrchestratorStatus) OrchestratorStatus {
+				s.Symbols = map[string]SymbolStatus{"EURUSD": s.Symbols["EURUSD"]}
+				return s
+			},
+			feed:   ports.FeedSubscribed,
+			failed: []string{"candles", "buffers"},
+		},
+		{
+			name: "short buffer",
+			status: func(s OrchestratorStatus) OrchestratorStatus {
+				s.Symbols = map[string]SymbolStatus{"EURUSD": s.Symbols["EURUSD"], "GBPUSD": {LastReceived: now, Buffered: 5}}
+				return s
+			},
+			feed:   ports.FeedSubscribed,
+			failed: []string{"buffers"},
+		},
+		{
+			name: "publish failed",
+			status: func(s OrchestratorStatus) OrchestratorStatus {
+				s.LastPublish = now
+				s.PublishError = "telegram down"
+				return s
+			},
+			feed:   ports.FeedSubscribed,
+			failed: []string{"publish"},
+		},
+		{
+			name: "not running",
+			status: func(s OrchestratorStatus) OrchestratorStatus {
+				s.Running = false
+				return s
+			},
+			feed:   ports.FeedSubscribed,
+			failed: []string{"orchestrator"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOrchestrator(nil, nil, nil)
+			o.status = tt.status(ready)
+			h := NewHealthChecker(o, staticHealth{ports.FeedHealth{State: tt.feed}}, []string{"EURUSD", "GBPUSD"}, 0)
+			h.now = func() time.Time { return now }
+
+			rep := h.Readiness()
+			if rep.OK != (len(tt.failed) == 0) {
+				t.Fatalf("expected ok=%v, got %+v", len(tt.failed) == 0, rep)
+			}
+			for _, c := range rep.Checks {
+				if c.OK == slices.Contains(tt.failed, c.Name) {
+					t.Errorf("check %s: unexpected ok=%v (%s)", c.Name, c.OK, c.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestOpsHandler_Health(t *testing.T) {
+	o := NewOrchestrator(nil, nil, nil)
+	o.status.Running = true
+	h := NewHealthChecker(o, staticHealth{ports.FeedHealth{State: ports.FeedConnecting}}, []string{"EURUSD"}, time.Minute)
+	srv := httptest.NewServer(NewOpsHandler(nil, h))
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{path: "/healthz", code: http.StatusOK},
+		{path: "/readyz", code: http.StatusServiceUnavailable},
+		{path: "/metrics", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatalf("get %s: %v", tt.path, err)
+		}
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, resp.StatusCode)
+		}
+		if tt.code != http.StatusNotFound {
+			var rep HealthReport
+			if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+				t.Errorf("%s: decode: %v", tt.path, err)
+			}
+			if rep.OK != (tt.code == http.StatusOK) {
+				t.Errorf("%s: unexpected report %+v", tt.path, rep)
+			}
+		}
+		resp.Body.Close()
+	}
+}
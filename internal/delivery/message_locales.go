@@ -0,0 +1,107 @@
+package delivery
+
+import "strings"
+
+// localePacks holds the built-in translations used by the t template
+// function. Every pack defines the same keys as "en".
+var localePacks = map[string]map[string]string{
+	"en": {
+		"signal":                 "Signal",
+		"early_signal":           "Early signal",
+		"direction":              "Direction",
+		"price":                  "Price",
+		"confidence":             "Confidence",
+		"expires_in":             "Expires in",
+		"expires_at":             "Expires at",
+		"confirmed":              "Confirmed",
+		"cancelled":              "Cancelled",
+		"pips":                   "pips",
+		"UP":                     "UP",
+		"DOWN":                   "DOWN",
+		"WIN":                    "WIN",
+		"LOSS":                   "LOSS",
+		"NEUTRAL":                "NEUTRAL",
+		"source.rsi_divergence":  "RSI divergence",
+		"source.ema_interaction": "EMA interaction",
+		"source.candlestick":     "candlestick pattern",
+		"digest":                 "Digest",
+		"low_confidence_signals": "low-confidence signals",
+	},
+	"fr": {
+		"signal":                 "Signal",
+		"early_signal":           "Signal anticipé",
+		"direction":              "Direction",
+		"price":                  "Prix",
+		"confidence":             "Confiance",
+		"expires_in":             "Expire dans",
+		"expires_at":             "Expire à",
+		"confirmed":              "Confirmé",
+		"cancelled":              "Annulé",
+		"pips":                   "pips",
+		"UP":                     "HAUSSE",
+		"DOWN":                   "BAISSE",
+		"WIN":                    "GAGNÉ",
+		"LOSS":                   "PERDU",
+		"NEUTRAL":                "NEUTRE",
+		"source.rsi_divergence":  "divergence RSI",
+		"source.ema_interaction": "interaction EMA",
+		"source.candlestick":     "figure de chandelier",
+		"digest":                 "Résumé",
+		"low_confidence_signals": "signaux à faible confiance",
+	},
+	"pt": {
+		"signal":                 "Sinal",
+		"early_signal":           "Sinal antecipado",
+		"direction":              "Direção",
+		"price":                  "Preço",
+		"confidence":             "Confiança",
+		"expires_in":             "Expira em",
+		"expires_at":             "Expira às",
+		"confirmed":              "Confirmado",
+		"cancelled":              "Cancelado",
+		"pips":                   "pips",
+		"UP":                     "ALTA",
+		"DOWN":                   "BAIXA",
+		"WIN":                    "GANHO",
+		"LOSS":                   "PERDA",
+		"NEUTRAL":                "NEUTRO",
+		"source.rsi_divergence":  "divergência RSI",
+		"source.ema_interaction": "interação EMA",
+		"source.candlestick":     "padrão de candlestick",
+		"digest":                 "Resumo",
+		"low_confidence_signals": "sinais de baixa confiança",
+	},
+	"es": {
+		"signal":                 "Señal",
+		"early_signal":           "Señal anticipada",
+		"direction":              "Dirección",
+		"price":                  "Precio",
+		"confidence":             "Confianza",
+		"expires_in":             "Expira en",
+		"expires_at":             "Expira a las",
+		"confirmed":              "Confirmada",
+		"cancelled":              "Cancelada",
+		"pips":                   "pips",
+		"UP":                     "ALZA",
+		"DOWN":                   "BAJA",
+		"WIN":                    "GANADA",
+		"LOSS":                   "PERDIDA",
+		"NEUTRAL":                "NEUTRAL",
+		"source.rsi_divergence":  "divergencia RSI",
+		"source.ema_interaction": "interacción EMA",
+		"source.candlestick":     "patrón de velas",
+		"digest":                 "Resumen",
+		"low_confidence_signals": "señales de baja confianza",
+	},
+}
+
+// localeCode reduces a language tag such as "pt-BR" to a pack code. An
+// empty tag selects "en".
+func localeCode(tag string) string {
+	if tag == "" {
+		return "en"
+	}
+	code, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	code, _, _ = strings.Cut(code, "_")
+	return code
+}
@@ -0,0 +1,529 @@
+package delivery
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// Markup selects how template output is escaped. The values match Telegram's
+// parse_mode.
+type Markup string
+
+const (
+	// MarkupPlain sends text as is.
+	MarkupPlain Markup = ""
+	// MarkupMarkdownV2 escapes values for Telegram MarkdownV2.
+	MarkupMarkdownV2 Markup = "MarkdownV2"
+	// MarkupHTML escapes values for Telegram HTML.
+	MarkupHTML Markup = "HTML"
+)
+
+// The built-in layouts. They are used when TemplateOptions leaves a
+// template empty and as a fallback when a custom template fails.
+const (
+	defaultSignalTemplate = `{{if .Early}}⏳ {{t "early_signal"}}{{else}}⚡ {{t "signal"}}{{end}}: {{.Symbol}}
+📈 {{t "direction"}}: {{.DirectionText}}
+{{if .Price}}💵 {{t "price"}}: {{.Price}}
+{{end}}🎯 {{t "confidence"}}: {{.RoundedConfidence}}%
+⏱️ {{t "expires_in"}}: {{.TTLMinutes}}m{{range .Tags}}
+⚠️ {{.}}{{end}}`
+	defaultResolutionTemplate = `{{if .Confirmed}}✅ {{t "confirmed"}}{{else}}❌ {{t "cancelled"}}{{end}}: {{.Symbol}} {{.DirectionText}}`
+	defaultOutcomeTemplate    = `{{if .Win}}🏆{{else if .Loss}}🔻{{else}}➖{{end}} {{.OutcomeText}}: {{.Signal.Symbol}} {{.Signal.DirectionText}} ({{clock .Signal.Time}})
+💵 {{.EntryPrice}} → {{.ExitPrice}}{{if .HasPips}} ({{printf "%+.1f" .Pips}} {{t "pips"}}){{end}}`
+	defaultDigestTemplate = `📋 {{t "digest"}}: {{.Count}} {{t "low_confidence_signals"}}{{range .Signals}}
+{{if .Confirmed}}✅{{else if eq .Stage "cancelled"}}❌{{else if .Early}}⏳{{else}}•{{end}} {{if not .Time.IsZero}}{{clock .Time}} {{end}}{{.Symbol}} {{.DirectionText}} {{.RoundedConfidence}}%{{if .SourceText}} ({{.SourceText}}){{end}}{{end}}`
+)
+
+// TemplateOptions configures MessageTemplates. Templates use text/template
+// syntax; an empty template selects the built-in layout.
+type TemplateOptions struct {
+	// Signal renders closed-bar and early signals from a SignalView. Use
+	// .Early to tell them apart.
+	Signal string
+	// Resolution renders the confirmation or cancellation of an early
+	// signal from a SignalView.
+	Resolution string
+	// Outcome renders a settled signal from an OutcomeView.
+	Outcome string
+	// Digest renders a batch of low-confidence signals from a DigestView.
+	Digest string
+	// Locale picks the locale pack: "en" (default), "es", "fr", "pt" or one
+	// defined in Translations. Region suffixes such as "pt-BR" are ignored.
+	Locale string
+	// Translations adds or overrides locale packs, by locale code. Keys
+	// missing from a pack fall back to English.
+	Translations map[string]map[string]string
+	// Markup escapes every value a template prints. Literal text in custom
+	// templates is left as written, so it may contain markup.
+	Markup Markup
+	// Location is the time zone of the times passed to templates. Defaults
+	// to UTC.
+	Location *time.Location
+	// BarInterval is the candle length, used to derive expiry times.
+	// Defaults to one minute.
+	BarInterval time.Duration
+	// Instruments formats symbols and prices.
+	Instruments *entity.InstrumentRegistry
+	// Logger reports templates that fail to render. The built-in layout is
+	// sent instead.
+	Logger *slog.Logger
+}
+
+// SignalView is the data passed to Signal and Resolution templates.
+type SignalView struct {
+	// ID identifies the signal, as in entity.Signal.ID.
+	ID string
+	// Symbol is the canonical symbol when registered.
+	Symbol string
+	// Direction is "UP" or "DOWN"; DirectionText is its translation.
+	Direction     string
+	DirectionText string
+	// Confidence is between 0 and 1. RoundedConfidence is the percentage
+	// rounded to the nearest 5.
+	Confidence        float64
+	RoundedConfidence int
+	// Price is the signal bar's close formatted with the instrument's
+	// precision, empty when unknown. PriceValue is the raw value.
+	Price      string
+	PriceValue float64
+	// TTL is the trade duration. TTLMinutes rounds it to whole minutes, at
+	// least one.
+	TTL        time.Duration
+	TTLMinutes int
+	// Time is the open time of the signal bar and ExpiresAt the end of the
+	// trade, both in the template's location. They are zero when the bar
+	// time is unknown.
+	Time      time.Time
+	ExpiresAt time.Time
+	// Source names the scorer that raised the signal and SourceText is its
+	// translation, e.g. "RSI divergence".
+	Source     string
+	SourceText string
+	Tags       []string
+	// Stage is "signal", "early", "confirmed" or "cancelled".
+	Stage     string
+	Early     bool
+	Confirmed bool
+}
+
+// OutcomeView is the data passed to Outcome templates.
+type OutcomeView struct {
+	Signal SignalView
+	// Outcome is "WIN", "LOSS" or "NEUTRAL"; OutcomeText is its translation.
+	Outcome     string
+	OutcomeText string
+	Win         bool
+	Loss        bool
+	// EntryPrice and ExitPrice are formatted like SignalView.Price.
+	EntryPrice string
+	ExitPrice  string
+	// Pips is the move in the signal's direction, set when HasPips is.
+	Pips       float64
+	HasPips    bool
+	EntryTime  time.Time
+	ExpiryTime time.Time
+	Reason     string
+}
+
+// DigestView is the data passed to Digest templates.
+type DigestView struct {
+	// Signals are the batched signals, oldest first. Early signals resolved
+	// before the digest was sent appear once, with the resolution's stage.
+	Signals []SignalView
+	Count   int
+}
+
+// templateSet holds parsed signal, resolution, outcome and digest templates.
+type templateSet struct {
+	signal, resolution, outcome, digest *template.Template
+}
+
+// MessageTemplates renders signals and outcomes with user-defined templates,
+// locale packs and Telegram markup escaping. It is safe for concurrent use.
+type MessageTemplates struct {
+	opts     TemplateOptions
+	words    map[string]string
+	custom   templateSet
+	fallback templateSet
+
+	mu       sync.Mutex
+	variants map[string]*MessageTemplates
+}
+
+// plainTemplates is the built-in English layout behind FormatSignal and
+// FormatOutcome.
+var plainTemplates = func() *MessageTemplates {
+	m, err := NewMessageTemplates(TemplateOptions{})
+	if err != nil {
+		panic(err)
+	}
+	return m
+}()
+
+// NewMessageTemplates parses the templates in opts and checks them against
+// sample data, so that errors such as unknown fields are reported up front.
+func NewMessageTemplates(opts TemplateOptions) (*MessageTemplates, error) {
+	code := localeCode(opts.Locale)
+	if _, ok := localePacks[code]; !ok && opts.Translations[code] == nil {
+		return nil, fmt.Errorf("templates: unknown locale %q", opts.Locale)
+	}
+	opts.Locale = code
+	switch opts.Markup {
+	case MarkupPlain, MarkupMarkdownV2, MarkupHTML:
+	default:
+		return nil, fmt.Errorf("templates: unknown markup %q", opts.Markup)
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.BarInterval <= 0 {
+		opts.BarInterval = time.Minute
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	m := &MessageTemplates{opts: opts, words: make(map[string]string)}
+	for _, pack := range []map[string]string{localePacks["en"], localePacks[code], opts.Translations[code]} {
+		for k, v := range pack {
+			m.words[k] = v
+		}
+	}
+	var err error
+	if m.fallback, err = m.parseSet("", "", "", ""); err != nil {
+		return nil, err
+	}
+	if m.custom, err = m.parseSet(opts.Signal, opts.Resolution, opts.Outcome, opts.Digest); err != nil {
+		return nil, err
+	}
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Markup returns the markup the templates escape for.
+func (m *MessageTemplates) Markup() Markup {
+	return m.opts.Markup
+}
+
+// Locales returns the codes of the available locale packs, sorted.
+func (m *MessageTemplates) Locales() []string {
+	codes := slices.Collect(maps.Keys(localePacks))
+	for code := range m.opts.Translations {
+		if _, ok := localePacks[code]; !ok {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// For returns templates rendering in locale and location instead, e.g. for
+// one subscriber. An empty locale or nil location keeps the current one.
+// Variants are cached.
+func (m *MessageTemplates) For(locale string, loc *time.Location) (*MessageTemplates, error) {
+	if locale == "" {
+		locale = m.opts.Locale
+	}
+	if loc == nil {
+		loc = m.opts.Location
+	}
+	key := localeCode(locale) + "|" + loc.String()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.variants[key]; ok {
+		return v, nil
+	}
+	opts := m.opts
+	opts.Locale, opts.Location = locale, loc
+	v, err := NewMessageTemplates(opts)
+	if err != nil {
+		return nil, err
+	}
+	if m.variants == nil {
+		m.variants = make(map[string]*MessageTemplates)
+	}
+	m.variants[key] = v
+	return v, nil
+}
+
+// FormatSignal renders s with the Signal or Resolution template according to
+// its stage.
+func (m *MessageTemplates) FormatSignal(s entity.Signal) string {
+	view := m.signalView(s)
+	if s.Stage == entity.StageConfirmed || s.Stage == entity.StageCancelled {
+		return m.render(m.custom.resolution, m.fallback.resolution, view)
+	}
+	return m.render(m.custom.signal, m.fallback.signal, view)
+}
+
+// FormatOutcome renders o with the Outcome template.
+func (m *MessageTemplates) FormatOutcome(o entity.SignalOutcome) string {
+	return m.render(m.custom.outcome, m.fallback.outcome, m.outcomeView(o))
+}
+
+// FormatDigest renders signals, which may belong to several symbols, as one
+// message with the Digest template.
+func (m *MessageTemplates) FormatDigest(signals []entity.Signal) string {
+	view := DigestView{Signals: make([]SignalView, len(signals)), Count: len(signals)}
+	for i, s := range signals {
+		view.Signals[i] = m.signalView(s)
+	}
+	return m.render(m.custom.digest, m.fallback.digest, view)
+}
+
+// withInstruments returns a copy of m formatting with reg.
+func (m *MessageTemplates) withInstruments(reg *entity.InstrumentRegistry) *MessageTemplates {
+	opts := m.opts
+	opts.Instruments = reg
+	return &MessageTemplates{opts: opts, words: m.words, custom: m.custom, fallback: m.fallback}
+}
+
+func (m *MessageTemplates) render(tmpl, fallback *template.Template, data any) string {
+	var b bytes.Buffer
+	err := tmpl.Execute(&b, data)
+	if err == nil {
+		return b.String()
+	}
+	m.opts.Logger.Warn("render message template", "template", tmpl.Name(), "error", err)
+	b.Reset()
+	_ = fallback.Execute(&b, data) // the built-in layouts cannot fail
+	return b.String()
+}
+
+func (m *MessageTemplates) signalView(s entity.Signal) SignalView {
+	reg := m.opts.Instruments
+	confidence := int(math.Round((s.Confidence*100)/5) * 5)
+	confidence = min(max(confidence, 0), 100)
+	stage := string(s.Stage)
+	if s.Stage == entity.StageSignal {
+		stage = "signal"
+	}
+	v := SignalView{
+		ID:                s.ID(),
+		Symbol:            displaySymbol(s.Symbol, reg),
+		Direction:         strings.ToUpper(s.Direction),
+		Confidence:        s.Confidence,
+		RoundedConfidence: confidence,
+		PriceValue:        s.Price,
+		TTL:               s.TTL,
+		TTLMinutes:        max(int(s.TTL.Round(time.Minute)/time.Minute), 1),
+		Source:            s.Source,
+		Tags:              s.Tags,
+		Stage:             stage,
+		Early:             s.Stage == entity.StageEarly,
+		Confirmed:         s.Stage == entity.StageConfirmed,
+	}
+	v.DirectionText = m.translate(v.Direction)
+	if s.Source != "" {
+		v.SourceText = m.translateOr("source."+s.Source, s.Source)
+	}
+	if s.Price > 0 {
+		v.Price = formatPrice(s.Symbol, s.Price, reg)
+	}
+	if !s.Time.IsZero() {
+		v.Time = s.Time.In(m.opts.Location)
+		v.ExpiresAt = v.Time.Add(m.opts.BarInterval + s.TTL)
+	}
+	return v
+}
+
+func (m *MessageTemplates) outcomeView(o entity.SignalOutcome) OutcomeView {
+	reg := m.opts.Instruments
+	_, hasPips := reg.Lookup(o.Signal.Symbol)
+	v := OutcomeView{
+		Signal:      m.signalView(o.Signal),
+		Outcome:     o.Outcome,
+		OutcomeText: m.translate(o.Outcome),
+		Win:         o.Outcome == "WIN",
+		Loss:        o.Outcome == "LOSS",
+		EntryPrice:  formatPrice(o.Signal.Symbol, o.EntryPrice, reg),
+		ExitPrice:   formatPrice(o.Signal.Symbol, o.ExitPrice, reg),
+		Pips:        o.Pips,
+		HasPips:     hasPips,
+		Reason:      o.Reason,
+	}
+	if !o.EntryTime.IsZero() {
+		v.EntryTime = o.EntryTime.In(m.opts.Location)
+	}
+	if !o.ExpiryTime.IsZero() {
+		v.ExpiryTime = o.ExpiryTime.In(m.opts.Location)
+	}
+	return v
+}
+
+// translate returns the translation of key, or key itself when the locale
+// has none.
+func (m *MessageTemplates) translate(key string) string {
+	return m.translateOr(key, key)
+}
+
+func (m *MessageTemplates) translateOr(key, def string) string {
+	if w, ok := m.words[key]; ok {
+		return w
+	}
+	return def
+}
+
+// parseSet parses the given templates, using the built-in layout for empty
+// ones.
+func (m *MessageTemplates) parseSet(signal, resolution, outcome, digest string) (templateSet, error) {
+	var set templateSet
+	var err error
+	if set.signal, err = m.parse("signal", signal, defaultSignalTemplate); err != nil {
+		return set, err
+	}
+	if set.resolution, err = m.parse("resolution", resolution, defaultResolutionTemplate); err != nil {
+		return set, err
+	}
+	if set.outcome, err = m.parse("outcome", outcome, defaultOutcomeTemplate); err != nil {
+		return set, err
+	}
+	if set.digest, err = m.parse("digest", digest, defaultDigestTemplate); err != nil {
+		return set, err
+	}
+	return set, nil
+}
+
+func (m *MessageTemplates) parse(name, text, builtin string) (*template.Template, error) {
+	isBuiltin := text == ""
+	if isBuiltin {
+		text = builtin
+	}
+	tmpl, err := template.New(name).Funcs(m.funcs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("templates: parse %s: %w", name, err)
+	}
+	if esc := escaper(m.opts.Markup); esc != nil {
+		for _, t := range tmpl.Templates() {
+			if t.Tree != nil {
+				// Built-in layouts are plain text, so their literal text is
+				// escaped as well.
+				escapeNode(t.Tree.Root, isBuiltin, esc)
+			}
+		}
+	}
+	return tmpl, nil
+}
+
+// check renders every template against sample data.
+func (m *MessageTemplates) check() error {
+	bar := time.Date(2024, 1, 2, 13, 30, 0, 0, time.UTC)
+	s := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute, Price: 1.1, Time: bar,
+		Source: "rsi_divergence", Tags: []string{"news"}}
+	early, confirmed := s, s
+	early.Stage, confirmed.Stage = entity.StageEarly, entity.StageConfirmed
+	o := entity.SignalOutcome{Signal: s, EntryTime: bar.Add(time.Minute), ExpiryTime: bar.Add(3 * time.Minute),
+		EntryPrice: 1.1, ExitPrice: 1.2, Pips: 1000, Outcome: "WIN", Reason: "closed above entry"}
+	checks := []struct {
+		tmpl *template.Template
+		data any
+	}{
+		{m.custom.signal, m.signalView(s)},
+		{m.custom.signal, m.signalView(early)},
+		{m.custom.resolution, m.signalView(confirmed)},
+		{m.custom.outcome, m.outcomeView(o)},
+		{m.custom.digest, DigestView{Signals: []SignalView{m.signalView(s), m.signalView(early)}, Count: 2}},
+	}
+	for _, c := range checks {
+		if err := c.tmpl.Execute(&bytes.Buffer{}, c.data); err != nil {
+			return fmt.Errorf("templates: %w", err)
+		}
+	}
+	return nil
+}
+
+// funcs returns the functions available to templates.
+func (m *MessageTemplates) funcs() template.FuncMap {
+	esc := escaper(m.opts.Markup)
+	if esc == nil {
+		esc = func(s string) string { return s }
+	}
+	return template.FuncMap{
+		// t translates a key of the locale pack.
+		"t": m.translate,
+		// clock formats a time as "15:04 MST" in the template's location.
+		"clock": func(t time.Time) string { return t.In(m.opts.Location).Format("15:04 MST") },
+		// percent formats a fraction as a whole percentage, e.g. "83%".
+		"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"join":    strings.Join,
+		// raw, as the last command of an action, prints its argument
+		// without escaping.
+		"raw": func(v any) string { return fmt.Sprint(v) },
+		// escapeFunc is appended to actions when a markup is set.
+		escapeFunc: func(v any) string { return esc(fmt.Sprint(v)) },
+	}
+}
+
+const escapeFunc = "_escape"
+
+// markdownV2Escaper escapes the characters Telegram reserves in MarkdownV2.
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+func escaper(markup Markup) func(string) string {
+	switch markup {
+	case MarkupMarkdownV2:
+		return markdownV2Escaper.Replace
+	case MarkupHTML:
+		return html.EscapeString
+	}
+	return nil
+}
+
+// escapeNode rewrites every action under n to pass its value through esc,
+// the way html/template does, and escapes literal text when text is set.
+func escapeNode(n parse.Node, text bool, esc func(string) string) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			escapeNode(c, text, esc)
+		}
+	case *parse.TextNode:
+		if text {
+			n.Text = []byte(esc(string(n.Text)))
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 || isRaw(n.Pipe) {
+			return
+		}
+		escape := parse.NewIdentifier(escapeFunc).SetTree(nil).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{escape}})
+	case *parse.IfNode:
+		escapeNode(n.List, text, esc)
+		escapeNode(n.ElseList, text, esc)
+	case *parse.RangeNode:
+		escapeNode(n.List, text, esc)
+		escapeNode(n.ElseList, text, esc)
+	case *parse.WithNode:
+		escapeNode(n.List, text, esc)
+		escapeNode(n.ElseList, text, esc)
+	}
+}
+
+// isRaw reports whether pipe ends with the raw function.
+func isRaw(pipe *parse.PipeNode) bool {
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	id, ok := last.Args[0].(*parse.IdentifierNode)
+	return ok && id.Ident == "raw"
+}
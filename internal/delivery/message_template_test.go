@@ -0,0 +1,194 @@
+package delivery
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestMessageTemplates_Locales(t *testing.T) {
+	bar := time.Date(2024, 7, 1, 13, 30, 0, 0, time.UTC)
+	s := entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Confidence: 0.72, TTL: 2 * time.Minute, Price: 1.0845, Time: bar}
+	o := entity.SignalOutcome{Signal: s, EntryPrice: 1.0845, ExitPrice: 1.084, Pips: 5, Outcome: "WIN"}
+
+	tests := []struct {
+		locale  string
+		signal  string
+		outcome string
+	}{
+		{
+			locale:  "",
+			signal:  "⚡ Signal: EUR/USD\n📈 Direction: DOWN\n💵 Price: 1.08450\n🎯 Confidence: 70%\n⏱️ Expires in: 2m",
+			outcome: "🏆 WIN: EUR/USD DOWN (13:30 UTC)\n💵 1.08450 → 1.08400 (+5.0 pips)",
+		},
+		{
+			locale:  "fr",
+			signal:  "⚡ Signal: EUR/USD\n📈 Direction: BAISSE\n💵 Prix: 1.08450\n🎯 Confiance: 70%\n⏱️ Expire dans: 2m",
+			outcome: "🏆 GAGNÉ: EUR/USD BAISSE (13:30 UTC)\n💵 1.08450 → 1.08400 (+5.0 pips)",
+		},
+		{
+			locale:  "pt-BR",
+			signal:  "⚡ Sinal: EUR/USD\n📈 Direção: BAIXA\n💵 Preço: 1.08450\n🎯 Confiança: 70%\n⏱️ Expira em: 2m",
+			outcome: "🏆 GANHO: EUR/USD BAIXA (13:30 UTC)\n💵 1.08450 → 1.08400 (+5.0 pips)",
+		},
+		{
+			locale:  "es",
+			signal:  "⚡ Señal: EUR/USD\n📈 Dirección: BAJA\n💵 Precio: 1.08450\n🎯 Confianza: 70%\n⏱️ Expira en: 2m",
+			outcome: "🏆 GANADA: EUR/USD BAJA (13:30 UTC)\n💵 1.08450 → 1.08400 (+5.0 pips)",
+		},
+	}
+	for _, tt := range tests {
+		m, err := NewMessageTemplates(TemplateOptions{Locale: tt.locale, Instruments: entity.DefaultInstruments()})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.locale, err)
+		}
+		if got := m.FormatSignal(s); got != tt.signal {
+			t.Errorf("%q signal: expected %q, got %q", tt.locale, tt.signal, got)
+		}
+		if got := m.FormatOutcome(o); got != tt.outcome {
+			t.Errorf("%q outcome: expected %q, got %q", tt.locale, tt.outcome, got)
+		}
+	}
+}
+
+func TestMessageTemplates_Custom(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone data not available:", err)
+	}
+	m, err := NewMessageTemplates(TemplateOptions{
+		Signal:      `{{.Symbol}} {{.Direction}} {{percent .Confidence}} @ {{.Price}} by {{.SourceText}} until {{clock .ExpiresAt}}{{range .Tags}} [{{.}}]{{end}}`,
+		Resolution:  `{{.Stage}} {{.Symbol}}`,
+		Outcome:     `{{.Signal.ID}} {{.Outcome}} {{.Reason}} {{.ExpiryTime.Format "15:04"}}`,
+		Location:    paris,
+		Instruments: entity.DefaultInstruments(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar := time.Date(2024, 7, 1, 13, 30, 0, 0, time.UTC)
+	s := entity.Signal{Symbol: "GBPUSD", Direction: "UP", Confidence: 0.83, TTL: 2 * time.Minute, Price: 1.27,
+		Time: bar, Source: "rsi_divergence", Tags: []string{"high-impact news"}}
+	if got, want := m.FormatSignal(s), "GBP/USD UP 83% @ 1.27000 by RSI divergence until 15:33 CEST [high-impact news]"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	s.Stage = entity.StageCancelled
+	if got, want := m.FormatSignal(s), "cancelled GBP/USD"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	o := entity.SignalOutcome{Signal: s, ExpiryTime: bar.Add(3 * time.Minute), Outcome: "LOSS", Reason: "closed below entry"}
+	if got, want := m.FormatOutcome(o), s.ID()+" LOSS closed below entry 15:33"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMessageTemplates_Markup(t *testing.T) {
+	s := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: time.Minute, Price: 1.0845,
+		Tags: []string{"news: NFP (high) <b>&"}}
+	tests := []struct {
+		name string
+		opts TemplateOptions
+		want string
+	}{
+		{
+			name: "markdown built-in",
+			opts: TemplateOptions{Markup: MarkupMarkdownV2},
+			want: "⚡ Signal: EURUSD\n📈 Direction: UP\n💵 Price: 1\\.0845\n🎯 Confidence: 80%\n⏱️ Expires in: 1m\n⚠️ news: NFP \\(high\\) <b\\>&",
+		},
+		{
+			name: "markdown custom keeps literal markup",
+			opts: TemplateOptions{Markup: MarkupMarkdownV2, Signal: `*{{.Symbol}}* _{{index .Tags 0}}_ {{raw "\\-"}}`},
+			want: "*EURUSD* _news: NFP \\(high\\) <b\\>&_ \\-",
+		},
+		{
+			name: "html custom",
+			opts: TemplateOptions{Markup: MarkupHTML, Signal: `<b>{{.Symbol}}</b> {{$t := index .Tags 0}}<i>{{$t}}</i>`},
+			want: "<b>EURUSD</b> <i>news: NFP (high) &lt;b&gt;&amp;</i>",
+		},
+		{
+			name: "html built-in",
+			opts: TemplateOptions{Markup: MarkupHTML},
+			want: "⚡ Signal: EURUSD\n📈 Direction: UP\n💵 Price: 1.0845\n🎯 Confidence: 80%\n⏱️ Expires in: 1m\n⚠️ news: NFP (high) &lt;b&gt;&amp;",
+		},
+	}
+	for _, tt := range tests {
+		m, err := NewMessageTemplates(tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := m.FormatSignal(s); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestMessageTemplates_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TemplateOptions
+	}{
+		{name: "unknown locale", opts: TemplateOptions{Locale: "xx"}},
+		{name: "unknown markup", opts: TemplateOptions{Markup: "Markdown"}},
+		{name: "parse error", opts: TemplateOptions{Signal: "{{.Symbol"}},
+		{name: "unknown field", opts: TemplateOptions{Outcome: "{{.Signal.Nope}}"}},
+		{name: "unknown function", opts: TemplateOptions{Resolution: "{{shout .Symbol}}"}},
+	}
+	for _, tt := range tests {
+		if _, err := NewMessageTemplates(tt.opts); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	// Templates failing at send time fall back to the built-in layout.
+	m, err := NewMessageTemplates(TemplateOptions{
+		Signal: `{{if eq .Symbol "GBPUSD"}}{{index .Tags 5}}{{end}}{{.Symbol}}`,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := entity.Signal{Symbol: "GBPUSD", Direction: "UP", Confidence: 0.8, TTL: time.Minute}
+	if got := m.FormatSignal(s); got != FormatSignal(s, nil) {
+		t.Fatalf("expected the built-in layout, got %q", got)
+	}
+}
+
+func TestMessageTemplates_For(t *testing.T) {
+	m, err := NewMessageTemplates(TemplateOptions{
+		Signal:       `{{t "signal"}} {{.DirectionText}} {{t "price"}}`,
+		Translations: map[string]map[string]string{"de": {"signal": "Signal", "UP": "STEIGEND"}, "fr": {"signal": "Alerte"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Locales(), []string{"de", "en", "es", "fr", "pt"}; !slices.Equal(got, want) {
+		t.Fatalf("expected locales %v, got %v", want, got)
+	}
+	s := entity.Signal{Symbol: "EURUSD", Direction: "UP"}
+
+	de, err := m.For("de", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := de.FormatSignal(s), "Signal STEIGEND Price"; got != want {
+		t.Errorf("expected %q with English fallback, got %q", want, got)
+	}
+	fr, err := m.For("FR", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fr.FormatSignal(s), "Alerte HAUSSE Prix"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if again, _ := m.For("fr", nil); again != fr {
+		t.Error("expected variants to be cached")
+	}
+	if _, err := m.For("xx", nil); err == nil || !strings.Contains(err.Error(), "unknown locale") {
+		t.Errorf("expected an unknown locale error, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NewOpsHandler returns an http.Handler exposing operational endpoints.
+// metrics is served at /metrics; health backs /healthz and /readyz. Either may
+// be nil to omit the corresponding endpoints.
+func NewOpsHandler(metrics http.Handler, health *HealthChecker) http.Handler {
+	mux := http.NewServeMux()
+	if metrics != nil {
+		mux.Handle("GET /metrics", metrics)
+	}
+	if health != nil {
+		mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+			writeHealth(w, health.Liveness())
+		})
+		mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+			writeHealth(w, health.Readiness())
+		})
+	}
+	return mux
+}
+
+func writeHealth(w http.ResponseWriter, rep HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if !rep.OK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(rep)
+}
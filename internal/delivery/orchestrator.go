@@ -3,24 +3,250 @@ package delivery
 import (
 	"context"
 	"log/slog"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 	"github.com/nomenarkt/signalengine/internal/usecase"
 )
 
+const (
+	keepBars = 50
+	minBars  = 20
+	// recentSignals is the number of published signals kept in
+	// OrchestratorStatus.
+	recentSignals = 10
+)
+
+// SuppressPaused is the suppression reason recorded for signals raised while
+// the Orchestrator is paused.
+const SuppressPaused = "paused"
+
+// SymbolStatus describes the buffered state of a single symbol.
+type SymbolStatus struct {
+	LastCandle   time.Time
+	LastReceived time.Time
+	Buffered     int
+}
+
+// OrchestratorStatus is a snapshot of the Orchestrator's runtime state.
+type OrchestratorStatus struct {
+	Running      bool
+	Symbols      map[string]SymbolStatus
+	LastPublish  time.Time
+	PublishError string
+	// Paused is set while publishing is paused with Pause.
+	Paused bool
+	// RecentSignals holds the last signals published, oldest first.
+	RecentSignals []entity.Signal
+}
+
 // Orchestrator streams market data, scores signals and publishes alerts.
 type Orchestrator struct {
-	feed      ports.MarketFeedPort
-	publisher ports.TelegramPublisher
-	logger    *slog.Logger
+	feed        ports.MarketFeedPort
+	logger      *slog.Logger
+	metrics     ports.MetricsRecorder
+	intrabar    bool
+	instruments *entity.InstrumentRegistry
+	templates   *MessageTemplates
+	calendar    *entity.SessionCalendar
+	sessions    entity.SessionFilter
+	blackout    *usecase.NewsBlackout
+	regime      *usecase.RegimeFilter
+	scorers     *entity.ScorerParamsTable
+	throttle    *usecase.SignalThrottle
+	outcomes    *usecase.OutcomeTracker
+	outcomeLog  ports.OutcomeStore
+	recorder    ports.CandleRecorder
+	charts      *ChartRenderer
+	queue       *DeliveryQueue
+	retry       RetryOptions
+	sink        ports.SignalPublisher
+	// sinkStatus is set when sink reports publish results to recordPublish
+	// itself.
+	sinkStatus bool
+
+	mu     sync.Mutex
+	status OrchestratorStatus
+}
+
+// OrchestratorOption configures optional Orchestrator behaviour.
+type OrchestratorOption func(*Orchestrator)
+
+// WithMetrics records pipeline instrumentation on m.
+func WithMetrics(m ports.MetricsRecorder) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if m != nil {
+			o.metrics = m
+		}
+	}
+}
+
+// WithInstruments formats published signals with the canonical symbols and
+// price precision from reg.
+func WithInstruments(reg *entity.InstrumentRegistry) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.instruments = reg
+	}
+}
+
+// WithSessionFilter skips scoring for bars that cal rejects under f, such as
+// bars outside the allowed sessions, on weekends and holidays, or shortly
+// after the market opens.
+func WithSessionFilter(cal *entity.SessionCalendar, f entity.SessionFilter) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.calendar = cal
+		o.sessions = f
+	}
+}
+
+// WithNewsBlackout suppresses or tags signals raised around economic releases
+// that affect the symbol's currencies.
+func WithNewsBlackout(b *usecase.NewsBlackout) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.blackout = b
+	}
+}
+
+// WithRegimeFilter checks each bar's volatility and spread before scoring and
+// vetoes or down-weights signals raised outside the allowed regime.
+func WithRegimeFilter(f *usecase.RegimeFilter) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.regime = f
+	}
+}
+
+// WithScorerParams configures the scorers per symbol or asset class. Symbols
+// whose scorers look back further than 20 bars wait for that many candles
+// before scoring.
+func WithScorerParams(t *entity.ScorerParamsTable) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.scorers = t
+	}
+}
+
+// WithThrottle applies cooldowns, the global signal cap and TTL suppression
+// from t before publishing. Suppressed signals are logged and counted.
+func WithThrottle(t *usecase.SignalThrottle) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.throttle = t
+	}
+}
+
+// WithOutcomeTracking follows every published signal with t until its TTL
+// expires and settles it on the live candles. Outcomes are counted, stored in
+// store when it is not nil and sent as follow-ups when the signal publisher
+// implements ports.OutcomePublisher.
+func WithOutcomeTracking(t *usecase.OutcomeTracker, store ports.OutcomeStore) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.outcomes = t
+		o.outcomeLog = store
+	}
+}
+
+// WithCandleRecorder saves every closed candle to r, so that live results
+// can later be compared with a backtest over the same data.
+func WithCandleRecorder(r ports.CandleRecorder) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.recorder = r
+	}
+}
+
+// WithCharts attaches a chart of the buffered candles, drawn by r, to new
+// and early signals when the signal publisher implements
+// ports.ChartPublisher, as TelegramSink, FanOut, Router and CommandBot do.
+// The built-in Telegram sink sends charts only when the publisher passed to
+// NewOrchestrator implements ports.PhotoPublisher.
+func WithCharts(r *ChartRenderer) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.charts = r
+	}
+}
+
+// WithSignalPublisher delivers structured signals to p, such as a FanOut over
+// several channels, instead of formatting them for the TelegramPublisher
+// passed to NewOrchestrator. WithDeliveryQueue and WithPublishRetry only apply
+// to the default Telegram output; configure a TelegramSink to combine them
+// with p.
+func WithSignalPublisher(p ports.SignalPublisher) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.sink = p
+	}
+}
+
+// WithDeliveryQueue hands messages to q instead of publishing them inline, so
+// a slow publisher does not hold up candle processing. Messages carry the
+// signal's TTL and are discarded if they expire before delivery. The caller
+// runs q.
+func WithDeliveryQueue(q *DeliveryQueue) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.queue = q
+	}
+}
+
+// WithPublishRetry retries failed inline publishes and stores undelivered
+// messages as dead letters. Retries block candle processing, so pair it with
+// WithDeliveryQueue, configuring DeliveryQueueOptions.Retry instead, when the
+// publisher may stay down for long.
+func WithPublishRetry(opts RetryOptions) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.retry = opts
+	}
+}
+
+// WithTemplates formats the messages of the built-in Telegram sink with t.
+// The publisher passed to NewOrchestrator must send them with t's markup.
+// It has no effect together with WithSignalPublisher.
+func WithTemplates(t *MessageTemplates) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.templates = t
+	}
+}
+
+// WithIntrabarEvaluation scores Partial candles on the forming bar and
+// publishes early signals. When the bar closes each early signal is confirmed
+// or cancelled, and only signals not already sent early are published in full.
+// Without this option Partial candles are ignored.
+func WithIntrabarEvaluation() OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.intrabar = true
+	}
+}
+
+// formingBar tracks the early signals sent for a bar that has not closed.
+type formingBar struct {
+	time time.Time
+	sent map[string]entity.Signal // by direction
 }
 
 // NewOrchestrator initializes an Orchestrator.
-func NewOrchestrator(feed ports.MarketFeedPort, pub ports.TelegramPublisher, logger *slog.Logger) *Orchestrator {
+func NewOrchestrator(feed ports.MarketFeedPort, pub ports.TelegramPublisher, logger *slog.Logger, opts ...OrchestratorOption) *Orchestrator {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Orchestrator{feed: feed, publisher: pub, logger: logger}
+	o := &Orchestrator{
+		feed:    feed,
+		logger:  logger,
+		metrics: ports.NopMetrics{},
+		status:  OrchestratorStatus{Symbols: make(map[string]SymbolStatus)},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.sink == nil {
+		o.sink = NewTelegramSink(pub, TelegramSinkOptions{
+			Instruments: o.instruments,
+			Templates:   o.templates,
+			Queue:       o.queue,
+			Retry:       o.retry,
+			Done:        o.recordPublish,
+			Logger:      logger,
+		})
+		o.sinkStatus = true
+	}
+	return o
 }
 
 // Run starts streaming candles for the given symbols and processes signals.
@@ -29,13 +255,11 @@ func (o *Orchestrator) Run(ctx context.Context, symbols []string) error {
 	if err != nil {
 		return err
 	}
-
-	const (
-		keepBars  = 50
-		rsiPeriod = 14
-	)
+	o.setRunning(true)
+	defer o.setRunning(false)
 
 	data := make(map[string][]ports.Candle)
+	forming := make(map[string]*formingBar)
 	for {
 		select {
 		case <-ctx.Done():
@@ -44,34 +268,372 @@ func (o *Orchestrator) Run(ctx context.Context, symbols []string) error {
 			if !ok {
 				return nil
 			}
+			if c.Partial {
+				if o.intrabar {
+					o.evaluateForming(ctx, data[c.Symbol], c, forming)
+				}
+				continue
+			}
+			o.metrics.CandleReceived(c.Symbol)
+			need, keep := o.bars(c.Symbol)
 			candles := append(data[c.Symbol], c)
-			if len(candles) > keepBars {
-				candles = candles[len(candles)-keepBars:]
+			if len(candles) > keep {
+				candles = candles[len(candles)-keep:]
 			}
 			data[c.Symbol] = candles
-			if len(candles) < 20 {
-				continue
-			}
-			closes := make([]float64, len(candles))
-			for i := range candles {
-				closes[i] = candles[i].Close
+			o.metrics.BufferSize(c.Symbol, len(candles))
+			o.recordCandle(c, len(candles))
+			if o.recorder != nil {
+				if err := o.recorder.RecordCandle(ctx, c); err != nil {
+					o.logger.WarnContext(ctx, "record candle", "symbol", c.Symbol, "error", err)
+				}
 			}
-			rsi := usecase.CalcRSI(closes, rsiPeriod)
-			ema8 := usecase.CalcEMA(closes, 8)
-			ema21 := usecase.CalcEMA(closes, 21)
+			o.settle(ctx, c)
 
-			signals, err := usecase.ScanSignalPatterns(ctx, o.logger, c.Symbol, candles, rsi, ema8, ema21)
-			if err != nil {
-				o.logger.ErrorContext(ctx, "scan patterns", "error", err)
-				continue
+			var signals []entity.Signal
+			if len(candles) >= need && o.inSession(ctx, c) {
+				signals = o.applyBlackout(ctx, c, o.filteredScan(ctx, c.Symbol, candles))
 			}
+			if o.intrabar {
+				signals = o.resolveForming(ctx, c, signals, forming)
+			}
+			signals = o.throttled(ctx, c, signals)
 			if len(signals) == 0 {
 				continue
 			}
-			msgs := FormatSignals(signals)
-			if err := o.publisher.PublishMessages(ctx, msgs); err != nil {
-				o.logger.ErrorContext(ctx, "publish telegram", "error", err)
+			if o.publish(ctx, c.Symbol, signals, candles) {
+				o.recordThrottle(c, signals)
 			}
 		}
 	}
 }
+
+// inSession reports whether the session filter allows scoring bar c.
+func (o *Orchestrator) inSession(ctx context.Context, c ports.Candle) bool {
+	if o.calendar == nil {
+		return true
+	}
+	ok, reason := o.calendar.Allow(c.Symbol, c.Time, o.sessions)
+	if !ok {
+		o.logger.DebugContext(ctx, "outside trading session", "symbol", c.Symbol, "bar", c.Time, "reason", reason)
+	}
+	return ok
+}
+
+// applyBlackout runs signals for bar c through the news blackout. Calendar
+// errors are logged and leave the signals unchanged.
+func (o *Orchestrator) applyBlackout(ctx context.Context, c ports.Candle, signals []entity.Signal) []entity.Signal {
+	if o.blackout == nil || len(signals) == 0 {
+		return signals
+	}
+	out, err := o.blackout.Apply(ctx, c.Symbol, c.Time, signals)
+	if err != nil {
+		o.logger.WarnContext(ctx, "news blackout unavailable", "symbol", c.Symbol, "error", err)
+		return signals
+	}
+	if len(out) == 0 {
+		o.logger.InfoContext(ctx, "signals suppressed by news blackout", "symbol", c.Symbol, "signals", len(signals))
+	}
+	return out
+}
+
+// filteredScan scans candles unless the regime filter vetoes them and
+// applies the filter's verdict to the resulting signals.
+func (o *Orchestrator) filteredScan(ctx context.Context, symbol string, candles []ports.Candle) []entity.Signal {
+	if o.regime == nil {
+		return o.scan(ctx, symbol, candles)
+	}
+	v := o.regime.Evaluate(symbol, candles)
+	if o.regime.Vetoes(v) {
+		o.logger.DebugContext(ctx, "outside volatility regime", "symbol", symbol, "reason", v.Reason)
+		return nil
+	}
+	return o.regime.Apply(v, o.scan(ctx, symbol, candles))
+}
+
+// throttled drops signals for bar c that the throttle suppresses, logging
+// and counting each one.
+func (o *Orchestrator) throttled(ctx context.Context, c ports.Candle, signals []entity.Signal) []entity.Signal {
+	if o.throttle == nil || len(signals) == 0 {
+		return signals
+	}
+	allowed, suppressed := o.throttle.Filter(c.Time, signals)
+	for _, s := range suppressed {
+		o.metrics.SignalSuppressed(s.Reason)
+		o.logger.InfoContext(ctx, "signal suppressed", "symbol", s.Signal.Symbol, "direction", s.Signal.Direction, "reason", s.Reason)
+	}
+	return allowed
+}
+
+func (o *Orchestrator) recordThrottle(c ports.Candle, signals []entity.Signal) {
+	if o.throttle != nil {
+		o.throttle.Record(c.Time, signals)
+	}
+}
+
+// bars returns the number of candles symbol needs before scoring and the
+// number kept in its buffer, which covers the indicators' warm-up.
+func (o *Orchestrator) bars(symbol string) (need, keep int) {
+	params := o.scorers.For(symbol).WithDefaults()
+	need = max(minBars, params.RSIDivergence.Lookback)
+	return need, max(keepBars, need, params.MinBars())
+}
+
+// scan computes indicators over candles and returns the detected signals.
+func (o *Orchestrator) scan(ctx context.Context, symbol string, candles []ports.Candle) []entity.Signal {
+	params := o.scorers.For(symbol)
+	start := time.Now()
+	rsi, emaFast, emaSlow := usecase.CalcIndicators(candles, params)
+	o.metrics.IndicatorLatency(time.Since(start))
+
+	signals, err := usecase.ScanSignalPatternsWithParams(ctx, o.logger, o.metrics, symbol, candles, rsi, emaFast, emaSlow, params)
+	if err != nil {
+		o.logger.ErrorContext(ctx, "scan patterns", "error", err)
+		return nil
+	}
+	return signals
+}
+
+// evaluateForming scores the closed buffer plus the forming candle c and
+// publishes signals not yet sent for that bar.
+func (o *Orchestrator) evaluateForming(ctx context.Context, buffer []ports.Candle, c ports.Candle, forming map[string]*formingBar) {
+	fb := forming[c.Symbol]
+	if fb == nil || !fb.time.Equal(c.Time) {
+		if fb != nil && len(fb.sent) > 0 {
+			o.logger.WarnContext(ctx, "forming bar replaced before close", "symbol", c.Symbol, "bar", fb.time)
+		}
+		fb = &formingBar{time: c.Time, sent: make(map[string]entity.Signal)}
+		forming[c.Symbol] = fb
+	}
+
+	need, keep := o.bars(c.Symbol)
+	candles := append(slices.Clip(buffer), c)
+	if len(candles) > keep {
+		candles = candles[len(candles)-keep:]
+	}
+	if len(candles) < need || !o.inSession(ctx, c) {
+		return
+	}
+
+	var fresh []entity.Signal
+	for _, s := range o.applyBlackout(ctx, c, o.filteredScan(ctx, c.Symbol, candles)) {
+		if _, ok := fb.sent[s.Direction]; ok {
+			continue
+		}
+		fresh = append(fresh, s)
+	}
+	fresh = o.throttled(ctx, c, fresh)
+	if len(fresh) == 0 {
+		return
+	}
+	early := make([]entity.Signal, len(fresh))
+	for i, s := range fresh {
+		s.Stage = entity.StageEarly
+		early[i] = s
+	}
+	if o.publish(ctx, c.Symbol, early, candles) {
+		o.recordThrottle(c, fresh)
+		for _, s := range fresh {
+			fb.sent[s.Direction] = s
+		}
+	}
+}
+
+// resolveForming confirms or cancels the early signals sent for the bar c
+// closes and returns the closed-bar signals that were not sent early.
+func (o *Orchestrator) resolveForming(ctx context.Context, c ports.Candle, signals []entity.Signal, forming map[string]*formingBar) []entity.Signal {
+	fb := forming[c.Symbol]
+	if fb == nil || !fb.time.Equal(c.Time) {
+		return signals
+	}
+	delete(forming, c.Symbol)
+	if len(fb.sent) == 0 {
+		return signals
+	}
+
+	var remaining []entity.Signal
+	confirmed := make(map[string]entity.Signal)
+	for _, s := range signals {
+		if _, ok := fb.sent[s.Direction]; ok {
+			confirmed[s.Direction] = s
+			continue
+		}
+		remaining = append(remaining, s)
+	}
+	dirs := make([]string, 0, len(fb.sent))
+	for d := range fb.sent {
+		dirs = append(dirs, d)
+	}
+	slices.Sort(dirs)
+	resolutions := make([]entity.Signal, len(dirs))
+	for i, d := range dirs {
+		s := fb.sent[d]
+		s.Stage = entity.StageCancelled
+		if closed, ok := confirmed[d]; ok {
+			// Confirmations enter at the close, like closed-bar signals.
+			s.Stage = entity.StageConfirmed
+			s.Price = closed.Price
+		}
+		resolutions[i] = s
+	}
+	o.publish(ctx, c.Symbol, resolutions, nil)
+	return remaining
+}
+
+// publish sends signals raised on candles unless the feed is unhealthy and
+// reports whether they were delivered, or queued when a delivery queue is
+// configured. candles is nil for signals that get no chart.
+func (o *Orchestrator) publish(ctx context.Context, symbol string, signals []entity.Signal, candles []ports.Candle) bool {
+	if o.paused() {
+		for range signals {
+			o.metrics.SignalSuppressed(SuppressPaused)
+		}
+		o.logger.InfoContext(ctx, "publishing paused, suppressing signals", "symbol", symbol, "signals", len(signals))
+		return false
+	}
+	if !o.feedHealthy() {
+		o.logger.WarnContext(ctx, "feed unhealthy, suppressing signals", "symbol", symbol, "signals", len(signals))
+		return false
+	}
+	err := o.deliver(ctx, symbol, signals, candles)
+	if !o.sinkStatus {
+		o.recordPublish(err)
+	}
+	if err != nil {
+		o.logger.ErrorContext(ctx, "publish signals", "symbol", symbol, "error", err)
+		return false
+	}
+	o.recordSignals(signals)
+	if o.outcomes != nil {
+		o.outcomes.Track(signals)
+	}
+	return true
+}
+
+// deliver sends signals to the sink, with charts of candles when charts are
+// enabled and the sink accepts them. Signals whose chart fails to render are
+// sent without one.
+func (o *Orchestrator) deliver(ctx context.Context, symbol string, signals []entity.Signal, candles []ports.Candle) error {
+	p, ok := o.sink.(ports.ChartPublisher)
+	if o.charts == nil || !ok || len(candles) == 0 {
+		return o.sink.PublishSignals(ctx, signals)
+	}
+	charts := make([][]byte, len(signals))
+	for i, s := range signals {
+		chart, err := o.charts.Render(s, candles)
+		if err != nil {
+			o.logger.WarnContext(ctx, "render chart", "symbol", symbol, "error", err)
+			continue
+		}
+		charts[i] = chart
+	}
+	return p.PublishSignalCharts(ctx, signals, charts)
+}
+
+// settle reports the outcomes of tracked signals that expire with bar c.
+func (o *Orchestrator) settle(ctx context.Context, c ports.Candle) {
+	if o.outcomes == nil {
+		return
+	}
+	outcomes := o.outcomes.Observe(c)
+	if len(outcomes) == 0 {
+		return
+	}
+	for _, out := range outcomes {
+		o.metrics.SignalSettled(c.Symbol, out.Outcome)
+		o.logger.InfoContext(ctx, "signal settled", "symbol", c.Symbol, "direction", out.Signal.Direction,
+			"outcome", out.Outcome, "entry", out.EntryPrice, "exit", out.ExitPrice)
+		if o.outcomeLog != nil {
+			if err := o.outcomeLog.Store(ctx, out); err != nil {
+				o.logger.ErrorContext(ctx, "store outcome", "symbol", c.Symbol, "error", err)
+			}
+		}
+	}
+	if o.paused() {
+		o.logger.InfoContext(ctx, "publishing paused, not publishing outcomes", "symbol", c.Symbol, "outcomes", len(outcomes))
+		return
+	}
+	if p, ok := o.sink.(ports.OutcomePublisher); ok {
+		if err := p.PublishOutcomes(ctx, outcomes); err != nil {
+			o.logger.ErrorContext(ctx, "publish outcomes", "symbol", c.Symbol, "error", err)
+		}
+	}
+}
+
+// Pause stops publishing signals and outcomes until Resume is called. Candles
+// are still buffered and scored, so signals resume as soon as publishing does.
+// Outcomes settled while paused are still stored and counted.
+func (o *Orchestrator) Pause() {
+	o.mu.Lock()
+	o.status.Paused = true
+	o.mu.Unlock()
+}
+
+// Resume undoes Pause.
+func (o *Orchestrator) Resume() {
+	o.mu.Lock()
+	o.status.Paused = false
+	o.mu.Unlock()
+}
+
+func (o *Orchestrator) paused() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.status.Paused
+}
+
+// feedHealthy reports whether the feed is currently healthy. Feeds that do not
+// implement ports.FeedHealthReporter are assumed healthy.
+func (o *Orchestrator) feedHealthy() bool {
+	h, ok := o.feed.(ports.FeedHealthReporter)
+	return !ok || h.FeedHealth().Healthy()
+}
+
+// Status returns a snapshot of the Orchestrator's per-symbol buffers and the
+// result of the last publish.
+func (o *Orchestrator) Status() OrchestratorStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	st := o.status
+	st.Symbols = make(map[string]SymbolStatus, len(o.status.Symbols))
+	for k, v := range o.status.Symbols {
+		st.Symbols[k] = v
+	}
+	st.RecentSignals = slices.Clone(o.status.RecentSignals)
+	return st
+}
+
+func (o *Orchestrator) setRunning(running bool) {
+	o.mu.Lock()
+	o.status.Running = running
+	o.mu.Unlock()
+}
+
+func (o *Orchestrator) recordCandle(c ports.Candle, buffered int) {
+	o.mu.Lock()
+	o.status.Symbols[c.Symbol] = SymbolStatus{LastCandle: c.Time, LastReceived: time.Now(), Buffered: buffered}
+	o.mu.Unlock()
+}
+
+func (o *Orchestrator) recordSignals(signals []entity.Signal) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	recent := append(o.status.RecentSignals, signals...)
+	if len(recent) > recentSignals {
+		recent = slices.Clone(recent[len(recent)-recentSignals:])
+	}
+	o.status.RecentSignals = recent
+}
+
+// recordPublish records the result of a delivery, from publish or, for the
+// default Telegram sink, once its queue or retries finish.
+func (o *Orchestrator) recordPublish(err error) {
+	o.metrics.PublishResult(err == nil)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.status.LastPublish = time.Now()
+	o.status.PublishError = ""
+	if err != nil {
+		o.status.PublishError = err.Error()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"reflect"
 	"testing"
 	"time"
 
@@ -74,3 +75,39 @@ func TestOrchestrator_ReconnectPublish(t *testing.T) {
 		t.Fatalf("expected publisher to be called")
 	}
 }
+
+func TestOrchestrator_PublishRetry(t *testing.T) {
+	ctx := context.Background()
+
+	seq := testutils.MakeCandles(true)
+	feed := &testutils.MockMarketFeed{Sequences: [][]ports.Candle{seq, seq}, Delay: 10 * time.Millisecond}
+	pub := &testutils.MockPublisher{FailFirst: true}
+	store := &testutils.MockDeadLetterStore{}
+	o := NewOrchestrator(feed, pub, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithPublishRetry(RetryOptions{MaxAttempts: 3, Backoff: fixedBackoff(time.Millisecond), DeadLetters: store}))
+
+	if err := o.Run(ctx, []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	// The failed first batch is retried, so every signal is delivered once
+	// and the retry repeats the first batch.
+	all := append(append([]ports.Candle{}, seq...), seq...)
+	want := expectedSignals(ctx, all) + len(pub.Messages[0])
+	got := 0
+	for _, m := range pub.Messages {
+		got += len(m)
+	}
+	if got != want {
+		t.Fatalf("expected %d messages, got %d", want, got)
+	}
+	if !reflect.DeepEqual(pub.Messages[0], pub.Messages[1]) {
+		t.Fatalf("expected first batch retried, got %q", pub.Messages[:2])
+	}
+	if letters, _ := store.List(ctx); len(letters) != 0 {
+		t.Fatalf("expected no dead letters, got %+v", letters)
+	}
+	if st := o.Status(); st.PublishError != "" {
+		t.Fatalf("expected successful publish status, got %q", st.PublishError)
+	}
+}
@@ -1,13 +1,21 @@
 package delivery
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"image/png"
 	"io"
 	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
 	"github.com/nomenarkt/signalengine/internal/usecase"
 )
 
@@ -90,3 +98,631 @@ func TestOrchestrator_Run(t *testing.T) {
 		})
 	}
 }
+
+type healthFeed struct {
+	mockFeed
+	state ports.FeedState
+}
+
+func (h *healthFeed) FeedHealth() ports.FeedHealth {
+	return ports.FeedHealth{State: h.state}
+}
+
+func (h *healthFeed) StateChanges(ctx context.Context) <-chan ports.FeedStateChange {
+	ch := make(chan ports.FeedStateChange)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch
+}
+
+func TestOrchestrator_SuppressUnhealthyFeed(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  ports.FeedState
+		expect bool
+	}{
+		{name: "subscribed", state: ports.FeedSubscribed, expect: true},
+		{name: "stale", state: ports.FeedStale, expect: false},
+		{name: "backing off", state: ports.FeedBackingOff, expect: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			feed := &healthFeed{mockFeed: mockFeed{candles: makeCandles(true)}, state: tt.state}
+			pub := &mockPublisher{}
+			o := NewOrchestrator(feed, pub, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if got := len(pub.msgs) > 0; got != tt.expect {
+				t.Fatalf("expected published=%v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Metrics(t *testing.T) {
+	candles := makeCandles(true)
+	metrics := testutils.NewMockMetrics()
+	pub := &testutils.MockPublisher{FailFirst: true}
+
+	feed := &testutils.MockMarketFeed{Sequences: [][]ports.Candle{candles, candles}}
+	o := NewOrchestrator(feed, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), WithMetrics(metrics))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if metrics.Candles["EURUSD"] != 2*len(candles) {
+		t.Fatalf("expected %d candles, got %d", 2*len(candles), metrics.Candles["EURUSD"])
+	}
+	if metrics.Buffers["EURUSD"] != 2*len(candles) {
+		t.Fatalf("expected buffer size %d, got %d", 2*len(candles), metrics.Buffers["EURUSD"])
+	}
+	if metrics.ScanObserved == 0 || metrics.ScanObserved != metrics.IndicatorObserved {
+		t.Fatalf("expected matching scan and indicator observations, got %d and %d", metrics.ScanObserved, metrics.IndicatorObserved)
+	}
+	if len(metrics.Signals) == 0 {
+		t.Fatalf("expected signals to be recorded")
+	}
+	if metrics.PublishFailed != 1 || metrics.PublishOK == 0 {
+		t.Fatalf("expected one failed and some successful publishes, got %d and %d", metrics.PublishFailed, metrics.PublishOK)
+	}
+}
+
+func TestOrchestrator_Status(t *testing.T) {
+	candles := makeCandles(true)
+	pub := &testutils.MockPublisher{FailFirst: true}
+	o := NewOrchestrator(&mockFeed{candles: candles}, pub, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	st := o.Status()
+	if st.Running {
+		t.Fatalf("expected orchestrator to be stopped")
+	}
+	ss, ok := st.Symbols["EURUSD"]
+	if !ok || ss.Buffered != len(candles) || !ss.LastCandle.Equal(candles[len(candles)-1].Time) {
+		t.Fatalf("unexpected symbol status %+v", ss)
+	}
+	if st.LastPublish.IsZero() || st.PublishError != "publish error" {
+		t.Fatalf("expected failed publish, got %+v", st)
+	}
+}
+
+func TestOrchestrator_IntrabarEvaluation(t *testing.T) {
+	bull := makeCandles(true)
+	closed := bull[:len(bull)-1]
+	up := bull[len(bull)-1]
+	down := makeCandles(false)[len(bull)-1]
+	down.Time = up.Time
+	partial := func(c ports.Candle) ports.Candle {
+		c.Partial = true
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		intrabar bool
+		forming  []ports.Candle
+		close    ports.Candle
+		want     []string
+	}{
+		{
+			name:     "confirmed",
+			intrabar: true,
+			forming:  []ports.Candle{partial(up), partial(up)},
+			close:    up,
+			want:     []string{"⏳ Early signal: EURUSD\n📈 Direction: UP", "⏳ Early signal: EURUSD\n📈 Direction: UP", "✅ Confirmed: EURUSD UP"},
+		},
+		{
+			name:     "cancelled",
+			intrabar: true,
+			forming:  []ports.Candle{partial(up)},
+			close:    down,
+			want:     []string{"⏳ Early signal: EURUSD\n📈 Direction: UP", "⏳ Early signal: EURUSD\n📈 Direction: UP", "❌ Cancelled: EURUSD UP", "⚡ Signal: EURUSD\n📈 Direction: DOWN"},
+		},
+		{
+			name:     "partials ignored",
+			intrabar: false,
+			forming:  []ports.Candle{partial(up)},
+			close:    down,
+			want:     []string{"⚡ Signal: EURUSD\n📈 Direction: DOWN"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			candles := append(append(append([]ports.Candle{}, closed...), tt.forming...), tt.close)
+			pub := &mockPublisher{}
+			var opts []OrchestratorOption
+			if tt.intrabar {
+				opts = append(opts, WithIntrabarEvaluation())
+			}
+			o := NewOrchestrator(&mockFeed{candles: candles}, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), opts...)
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if len(pub.msgs) != len(tt.want) {
+				t.Fatalf("expected %d messages, got %q", len(tt.want), pub.msgs)
+			}
+			for i, prefix := range tt.want {
+				if !strings.HasPrefix(pub.msgs[i], prefix) {
+					t.Fatalf("message %d: expected prefix %q, got %q", i, prefix, pub.msgs[i])
+				}
+			}
+			if got := o.Status().Symbols["EURUSD"].Buffered; got != len(closed)+1 {
+				t.Fatalf("expected partials to stay out of the buffer, got %d bars", got)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_SessionFilter(t *testing.T) {
+	cal := entity.NewSessionCalendar(entity.DefaultInstruments())
+	at := func(start time.Time) []ports.Candle {
+		candles := makeCandles(true)
+		shift := start.Sub(candles[0].Time)
+		for i := range candles {
+			candles[i].Time = candles[i].Time.Add(shift)
+		}
+		return candles
+	}
+
+	tests := []struct {
+		name   string
+		start  time.Time
+		filter entity.SessionFilter
+		expect bool
+	}{
+		{name: "london", start: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), filter: entity.SessionFilter{Sessions: []string{"London"}}, expect: true},
+		{name: "weekend", start: time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), expect: false},
+		{name: "outside london", start: time.Date(2024, 1, 3, 2, 0, 0, 0, time.UTC), filter: entity.SessionFilter{Sessions: []string{"London"}}, expect: false},
+		{name: "london warm-up", start: time.Date(2024, 1, 3, 6, 45, 0, 0, time.UTC), filter: entity.SessionFilter{Sessions: []string{"London"}, SkipAfterOpen: 30 * time.Minute}, expect: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &mockPublisher{}
+			o := NewOrchestrator(&mockFeed{candles: at(tt.start)}, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), WithSessionFilter(cal, tt.filter))
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if got := len(pub.msgs) > 0; got != tt.expect {
+				t.Fatalf("expected published=%v, got %q", tt.expect, pub.msgs)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_NewsBlackout(t *testing.T) {
+	candles := makeCandles(true)
+	release := candles[len(candles)-1].Time.Add(5 * time.Minute)
+	cal := &testutils.MockEconomicCalendar{List: []entity.EconomicEvent{
+		{Time: release, Currency: "USD", Impact: entity.ImpactHigh, Title: "CPI"},
+	}}
+
+	tests := []struct {
+		name    string
+		mode    usecase.BlackoutMode
+		publish bool
+	}{
+		{name: "suppress", mode: usecase.BlackoutSuppress, publish: false},
+		{name: "tag", mode: usecase.BlackoutTag, publish: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &mockPublisher{}
+			b := usecase.NewNewsBlackout(cal, nil, usecase.NewsBlackoutConfig{Mode: tt.mode})
+			o := NewOrchestrator(&mockFeed{candles: candles}, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), WithNewsBlackout(b))
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if got := len(pub.msgs) > 0; got != tt.publish {
+				t.Fatalf("expected published=%v, got %q", tt.publish, pub.msgs)
+			}
+			for _, m := range pub.msgs {
+				if !strings.Contains(m, "⚠️ news: USD CPI at") {
+					t.Fatalf("expected news tag, got %q", m)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RegimeFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  usecase.RegimeAction
+		publish bool
+	}{
+		{name: "veto", action: usecase.RegimeVeto, publish: false},
+		{name: "down-weight", action: usecase.RegimeDownWeight, publish: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := usecase.NewRegimeFilter(usecase.RegimeFilterConfig{Default: usecase.RegimeLimits{MinATR: 10}, Action: tt.action})
+			if err != nil {
+				t.Fatalf("new regime filter: %v", err)
+			}
+			pub := &mockPublisher{}
+			o := NewOrchestrator(&mockFeed{candles: makeCandles(true)}, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), WithRegimeFilter(f))
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if got := len(pub.msgs) > 0; got != tt.publish {
+				t.Fatalf("expected published=%v, got %q", tt.publish, pub.msgs)
+			}
+			for _, m := range pub.msgs {
+				if !strings.Contains(m, "⚠️ regime: ATR") {
+					t.Fatalf("expected regime tag, got %q", m)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Throttle(t *testing.T) {
+	candles := makeCandles(true)
+	repeat := candles[len(candles)-1]
+	repeat.Time = repeat.Time.Add(time.Minute)
+	candles = append(candles, repeat)
+
+	tests := []struct {
+		name   string
+		cfg    usecase.ThrottleConfig
+		msgs   int
+		reason string
+	}{
+		{name: "cooldown", cfg: usecase.ThrottleConfig{Cooldown: 5 * time.Minute}, msgs: 2, reason: usecase.SuppressCooldown},
+		{name: "within ttl", cfg: usecase.ThrottleConfig{SuppressWithinTTL: true}, msgs: 2, reason: usecase.SuppressActiveTTL},
+		{name: "global cap", cfg: usecase.ThrottleConfig{MaxSignals: 1}, msgs: 1, reason: usecase.SuppressRateLimit},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &mockPublisher{}
+			metrics := testutils.NewMockMetrics()
+			o := NewOrchestrator(&mockFeed{candles: candles}, pub, slog.New(slog.NewTextHandler(io.Discard, nil)),
+				WithMetrics(metrics), WithThrottle(usecase.NewSignalThrottle(tt.cfg)))
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if len(pub.msgs) != tt.msgs {
+				t.Fatalf("expected %d messages, got %q", tt.msgs, pub.msgs)
+			}
+			if metrics.Suppressed[tt.reason] == 0 {
+				t.Fatalf("expected %s suppressions, got %v", tt.reason, metrics.Suppressed)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_DeliveryQueue(t *testing.T) {
+	pub := &gatedPublisher{gate: make(chan struct{})}
+	q := NewDeliveryQueue(pub, DeliveryQueueOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	o := NewOrchestrator(&mockFeed{candles: makeCandles(true)}, &mockPublisher{}, slog.New(slog.NewTextHandler(io.Discard, nil)), WithDeliveryQueue(q))
+	done := make(chan error, 1)
+	go func() { done <- o.Run(ctx, []string{"EURUSD"}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("candle loop blocked by a slow publisher")
+	}
+
+	pub.gate <- struct{}{}
+	deadline := time.Now().Add(time.Second)
+	for o.Status().LastPublish.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pub.published(); len(got) == 0 || !strings.Contains(got[0], "⚡ Signal: EURUSD") {
+		t.Fatalf("expected queued signals delivered, got %q", got)
+	}
+}
+
+func TestOrchestrator_SignalPublisher(t *testing.T) {
+	closed := makeCandles(true)
+	last := closed[len(closed)-1]
+	partial := last
+	partial.Partial = true
+	partial.Close = last.Close - 0.05
+	candles := append(append(closed[:len(closed)-1:len(closed)-1], partial), last)
+
+	sink := &testutils.MockSignalPublisher{}
+	tg := &mockPublisher{}
+	o := NewOrchestrator(&mockFeed{candles: candles}, tg, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithIntrabarEvaluation(), WithSignalPublisher(sink))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(tg.msgs) != 0 {
+		t.Fatalf("expected no telegram messages, got %q", tg.msgs)
+	}
+	batches := sink.Batches()
+	if len(batches) != 2 {
+		t.Fatalf("expected early and resolution batches, got %+v", batches)
+	}
+	for _, s := range batches[0] {
+		if s.Stage != entity.StageEarly || s.Symbol != "EURUSD" || s.Price != partial.Close {
+			t.Fatalf("expected early signals at %g, got %+v", partial.Close, batches[0])
+		}
+	}
+	for _, s := range batches[1] {
+		if s.Stage != entity.StageConfirmed || s.Price != last.Close {
+			t.Fatalf("expected confirmations at the close %g, got %+v", last.Close, batches[1])
+		}
+	}
+	if o.Status().LastPublish.IsZero() {
+		t.Fatalf("expected publish status recorded")
+	}
+}
+
+func TestOrchestrator_SignalPublisherMetrics(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		ok, failed   int
+		publishError bool
+	}{
+		{name: "delivered", ok: 1},
+		{name: "failed", err: errors.New("webhook down"), failed: 1, publishError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := testutils.NewMockMetrics()
+			sink := &testutils.MockSignalPublisher{Err: tt.err}
+			o := NewOrchestrator(&mockFeed{candles: makeCandles(true)}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)),
+				WithMetrics(metrics), WithSignalPublisher(sink))
+			if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if metrics.PublishOK != tt.ok || metrics.PublishFailed != tt.failed {
+				t.Fatalf("expected %d ok and %d failed publishes, got %d and %d", tt.ok, tt.failed, metrics.PublishOK, metrics.PublishFailed)
+			}
+			if got := o.Status().PublishError != ""; got != tt.publishError {
+				t.Fatalf("expected publish error %v, got %q", tt.publishError, o.Status().PublishError)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Pause(t *testing.T) {
+	sink := &testutils.MockSignalPublisher{}
+	metrics := testutils.NewMockMetrics()
+	o := NewOrchestrator(&mockFeed{candles: makeCandles(true)}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithMetrics(metrics), WithSignalPublisher(sink))
+
+	o.Pause()
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(sink.Batches()) != 0 || metrics.Suppressed[SuppressPaused] == 0 {
+		t.Fatalf("expected paused signals suppressed, got %+v and %v", sink.Batches(), metrics.Suppressed)
+	}
+
+	o.Resume()
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := o.Status().RecentSignals; len(got) == 0 || !reflect.DeepEqual(got, sink.Signals()) {
+		t.Fatalf("expected recent signals %+v, got %+v", sink.Signals(), got)
+	}
+}
+
+func TestOrchestrator_PauseOutcomes(t *testing.T) {
+	feed := &mockFeed{candles: makeCandles(true)}
+	sink := &testutils.MockSignalPublisher{}
+	store := &testutils.MockOutcomeStore{}
+	metrics := testutils.NewMockMetrics()
+	tracker := usecase.NewOutcomeTracker(usecase.OutcomeTrackerOptions{})
+	o := NewOrchestrator(feed, nil, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithMetrics(metrics), WithSignalPublisher(sink), WithOutcomeTracking(tracker, store))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	pending := tracker.Pending()
+	if pending == 0 {
+		t.Fatal("expected tracked signals")
+	}
+
+	// Settle the tracked signals while paused.
+	last := feed.candles[len(feed.candles)-1]
+	feed.candles = nil
+	for i := 1; i <= 10; i++ {
+		feed.candles = append(feed.candles, ports.Candle{Symbol: "EURUSD", Time: last.Time.Add(time.Duration(i) * time.Minute), Open: 0.9, High: 0.9, Low: 0.9, Close: 0.9})
+	}
+	o.Pause()
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := sink.Outcomes(); len(got) != 0 {
+		t.Fatalf("expected no outcomes published while paused, got %+v", got)
+	}
+	if stored, _ := store.List(context.Background(), time.Time{}); len(stored) != pending {
+		t.Fatalf("expected %d stored outcomes, got %+v", pending, stored)
+	}
+	if metrics.Settled["EURUSD|WIN"] != pending {
+		t.Fatalf("expected settled metrics, got %v", metrics.Settled)
+	}
+}
+
+func TestOrchestrator_OutcomeTracking(t *testing.T) {
+	candles := makeCandles(true)
+	last := candles[len(candles)-1]
+	for i := 1; i <= 10; i++ {
+		candles = append(candles, ports.Candle{Symbol: "EURUSD", Time: last.Time.Add(time.Duration(i) * time.Minute), Open: 0.9, High: 0.9, Low: 0.9, Close: 0.9})
+	}
+	sink := &testutils.MockSignalPublisher{}
+	store := &testutils.MockOutcomeStore{}
+	metrics := testutils.NewMockMetrics()
+	tracker := usecase.NewOutcomeTracker(usecase.OutcomeTrackerOptions{})
+	o := NewOrchestrator(&mockFeed{candles: candles}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithMetrics(metrics), WithSignalPublisher(sink), WithOutcomeTracking(tracker, store))
+
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	signals := sink.Signals()
+	if len(signals) == 0 {
+		t.Fatal("expected signals")
+	}
+	outcomes := sink.Outcomes()
+	if len(outcomes) != len(signals) || tracker.Pending() != 0 {
+		t.Fatalf("expected %d outcomes and none pending, got %+v and %d pending", len(signals), outcomes, tracker.Pending())
+	}
+	for _, out := range outcomes {
+		if out.Outcome != "WIN" || out.ExitPrice != 0.9 || out.Signal.ID() == "" {
+			t.Fatalf("unexpected outcome %+v", out)
+		}
+	}
+	stored, _ := store.List(context.Background(), time.Time{})
+	if !reflect.DeepEqual(stored, outcomes) {
+		t.Fatalf("expected stored outcomes %+v, got %+v", outcomes, stored)
+	}
+	if metrics.Settled["EURUSD|WIN"] != len(outcomes) {
+		t.Fatalf("expected settled metrics, got %v", metrics.Settled)
+	}
+}
+
+func TestOrchestrator_CandleRecorder(t *testing.T) {
+	candles := makeCandles(true)
+	partial := candles[len(candles)-1]
+	partial.Time = partial.Time.Add(time.Minute)
+	partial.Partial = true
+	rec := &testutils.MockCandleRecorder{}
+	o := NewOrchestrator(&mockFeed{candles: append(candles, partial)}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithSignalPublisher(&testutils.MockSignalPublisher{}), WithCandleRecorder(rec))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := rec.Candles(); !reflect.DeepEqual(got, candles) {
+		t.Fatalf("expected the closed candles recorded, got %+v", got)
+	}
+
+	failing := NewOrchestrator(&mockFeed{candles: candles}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithSignalPublisher(&testutils.MockSignalPublisher{}), WithCandleRecorder(&testutils.MockCandleRecorder{Err: errors.New("disk full")}))
+	if err := failing.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("expected recorder errors to be logged only, got %v", err)
+	}
+}
+
+func TestOrchestrator_Charts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pub := &testutils.MockPhotoPublisher{}
+	o := NewOrchestrator(&mockFeed{candles: makeCandles(true)}, pub, logger, WithCharts(NewChartRenderer(ChartOptions{})))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(pub.Photos) == 0 || len(pub.Messages) != 0 {
+		t.Fatalf("expected only photos, got %d photos and messages %q", len(pub.Photos), pub.Messages)
+	}
+	for _, p := range pub.Photos {
+		if _, err := png.Decode(bytes.NewReader(p.Photo)); err != nil {
+			t.Fatalf("decode chart: %v", err)
+		}
+		if !strings.HasPrefix(p.Caption, "⚡ Signal: EURUSD") {
+			t.Fatalf("expected the signal message as caption, got %q", p.Caption)
+		}
+	}
+
+	plain := &testutils.MockPhotoPublisher{}
+	o = NewOrchestrator(&mockFeed{candles: makeCandles(true)}, plain, logger)
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(plain.Photos) != 0 || len(plain.Messages) != 1 || len(plain.Messages[0]) != len(pub.Photos) {
+		t.Fatalf("expected text messages without charts, got %d photos and messages %q", len(plain.Photos), plain.Messages)
+	}
+}
+
+func TestOrchestrator_ScorerParams(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	table, err := entity.NewScorerParamsTable(entity.ScorerParamsConfig{
+		AssetClasses: map[entity.AssetClass]entity.ScorerParams{
+			entity.AssetForex: {Candlestick: entity.CandlestickParams{Confidence: 0.65}},
+		},
+		Symbols: map[string]entity.ScorerParams{
+			"GBPUSD": {RSIDivergence: entity.RSIDivergenceParams{Lookback: 25}},
+		},
+		Instruments: entity.DefaultInstruments(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &testutils.MockSignalPublisher{}
+	o := NewOrchestrator(&mockFeed{candles: makeCandles(true)}, nil, logger, WithSignalPublisher(sink), WithScorerParams(table))
+	if err := o.Run(context.Background(), []string{"EURUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	i := slices.IndexFunc(sink.Signals(), func(s entity.Signal) bool { return s.Source == usecase.ScorerCandlestick })
+	if i < 0 || sink.Signals()[i].Confidence != 0.65 {
+		t.Fatalf("expected a candlestick signal with the forex confidence, got %+v", sink.Signals())
+	}
+
+	// GBPUSD waits for its 25-bar lookback.
+	gbp := makeCandles(true)
+	for i := range gbp {
+		gbp[i].Symbol = "GBPUSD"
+	}
+	sink = &testutils.MockSignalPublisher{}
+	o = NewOrchestrator(&mockFeed{candles: gbp}, nil, logger, WithSignalPublisher(sink), WithScorerParams(table))
+	if err := o.Run(context.Background(), []string{"GBPUSD"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := sink.Signals(); len(got) != 0 {
+		t.Fatalf("expected no signals before 25 bars, got %+v", got)
+	}
+}
+
+func TestOrchestrator_ScorerParamsWarmUp(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	table, err := entity.NewScorerParamsTable(entity.ScorerParamsConfig{
+		Symbols: map[string]entity.ScorerParams{
+			"EURUSD": {RSIDivergence: entity.RSIDivergenceParams{RSIPeriod: 60}},
+			"GBPUSD": {EMAInteraction: entity.EMAInteractionParams{SlowPeriod: 70}},
+		},
+		Instruments: entity.DefaultInstruments(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		symbol string
+		keep   int
+	}{
+		{symbol: "EURUSD", keep: 80},
+		{symbol: "GBPUSD", keep: 71},
+		{symbol: "USDJPY", keep: keepBars},
+	}
+	for _, tt := range tests {
+		base := time.Now()
+		candles := make([]ports.Candle, 120)
+		for i := range candles {
+			p := 1 + float64(i%7)/100
+			candles[i] = ports.Candle{Symbol: tt.symbol, Time: base.Add(time.Duration(i) * time.Minute), Open: p, High: p + 0.01, Low: p - 0.01, Close: p}
+		}
+		o := NewOrchestrator(&mockFeed{candles: candles}, nil, logger,
+			WithSignalPublisher(&testutils.MockSignalPublisher{}), WithScorerParams(table))
+		if err := o.Run(context.Background(), []string{tt.symbol}); err != nil {
+			t.Fatalf("%s: run: %v", tt.symbol, err)
+		}
+		// The buffer covers the RSI period before the lookback, or the slow
+		// EMA period, so the indicators are warm when scored.
+		if got := o.Status().Symbols[tt.symbol].Buffered; got != tt.keep {
+			t.Errorf("%s: expected %d buffered bars, got %d", tt.symbol, tt.keep, got)
+		}
+	}
+}
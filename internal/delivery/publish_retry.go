@@ -0,0 +1,184 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// RetryOptions configures publish retries. The zero value publishes once and
+// drops failed messages, as before.
+type RetryOptions struct {
+	// MaxAttempts is the total number of publish attempts. Zero or one
+	// disables retries.
+	MaxAttempts int
+	// Backoff computes the wait before each retry. Defaults to doubling from
+	// 500ms up to 10s.
+	Backoff ports.BackoffStrategy
+	// DeadLetters, when set, stores messages that could not be delivered.
+	DeadLetters ports.DeadLetterStore
+}
+
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 1
+	}
+	if o.Backoff == nil {
+		o.Backoff = defaultRetryBackoff{}
+	}
+	return o
+}
+
+// defaultRetryBackoff doubles from 500ms up to 10s.
+type defaultRetryBackoff struct{}
+
+func (defaultRetryBackoff) Next(retry int) time.Duration {
+	const base, maxWait = 500 * time.Millisecond, 10 * time.Second
+	if retry >= 5 {
+		return maxWait
+	}
+	return min(base<<retry, maxWait)
+}
+
+// publishWithRetry publishes d, retrying failures with backoff. Each retry
+// resumes after the messages already delivered and first drops messages that
+// would expire before it runs, so retries neither repeat alerts nor outlive
+// the signal TTL. Messages still undelivered and unexpired when it gives up
+// are stored as a dead letter. It returns the last publish error.
+func publishWithRetry(ctx context.Context, pub ports.TelegramPublisher, logger *slog.Logger, opts RetryOptions, d Delivery, now func() time.Time) error {
+	return sendWithRetry(ctx, logger, opts, d.Symbol, d.Messages, d.Messages, now,
+		func(ctx context.Context, msgs []OutboundMessage) (int, error) { return publishMessages(ctx, pub, msgs) })
+}
+
+// sendWithRetry sends items, described by the matching msgs, as
+// publishWithRetry does. send returns how many leading items it delivered.
+func sendWithRetry[T any](ctx context.Context, logger *slog.Logger, opts RetryOptions, symbol string, items []T, msgs []OutboundMessage, now func() time.Time, send func(context.Context, []T) (int, error)) error {
+	opts = opts.withDefaults()
+	var err error
+	attempts := 0
+	for {
+		attempts++
+		var sent int
+		if sent, err = send(ctx, items); err == nil {
+			return nil
+		}
+		items, msgs = items[sent:], msgs[sent:]
+		if attempts >= opts.MaxAttempts {
+			break
+		}
+
+		wait := opts.Backoff.Next(attempts - 1)
+		items, msgs = unexpired(items, msgs, now().Add(wait))
+		if len(items) == 0 {
+			err = fmt.Errorf("signal expired before retry: %w", err)
+			break
+		}
+		logger.WarnContext(ctx, "publish failed, retrying", "symbol", symbol, "attempt", attempts, "retry_in", wait, "error", err)
+
+		if !sleep(ctx, wait) {
+			err = fmt.Errorf("%w: %w", ctx.Err(), err)
+			break
+		}
+	}
+
+	_, msgs = unexpired(items, msgs, now())
+	if opts.DeadLetters != nil && len(msgs) > 0 {
+		texts := make([]string, len(msgs))
+		for i, m := range msgs {
+			texts[i] = m.Text
+		}
+		dl := ports.DeadLetter{Time: now().UTC(), Symbol: symbol, Messages: texts, Attempts: attempts, Error: err.Error()}
+		if serr := opts.DeadLetters.Store(context.WithoutCancel(ctx), dl); serr != nil {
+			logger.ErrorContext(ctx, "store dead letter", "symbol", symbol, "error", serr)
+		}
+	}
+	return err
+}
+
+// unexpired returns the items, and their msgs, still valid at t.
+func unexpired[T any](items []T, msgs []OutboundMessage, t time.Time) ([]T, []OutboundMessage) {
+	keptItems, keptMsgs := items[:0:0], msgs[:0:0]
+	for i, m := range msgs {
+		if !m.expired(t) {
+			keptItems, keptMsgs = append(keptItems, items[i]), append(keptMsgs, m)
+		}
+	}
+	return keptItems, keptMsgs
+}
+
+// publishMessages sends msgs in order and returns how many were delivered.
+// When pub implements ports.FormattedPublisher, each message is parsed with
+// its Markup; otherwise pub's own parse mode applies. Messages with a photo
+// are sent as photos when pub can send them; runs of other messages with the
+// same markup are sent in one call. A failed run counts the messages a
+// *ports.PartialPublishError reports as sent, and none otherwise.
+func publishMessages(ctx context.Context, pub ports.TelegramPublisher, msgs []OutboundMessage) (int, error) {
+	photos, _ := pub.(ports.PhotoPublisher)
+	formatted, _ := pub.(ports.FormattedPublisher)
+	sent := 0
+	var texts []string
+	var markup Markup
+	flush := func() error {
+		if len(texts) == 0 {
+			return nil
+		}
+		var err error
+		if formatted != nil {
+			err = formatted.PublishFormatted(ctx, texts, string(markup))
+		} else {
+			err = pub.PublishMessages(ctx, texts)
+		}
+		var partial *ports.PartialPublishError
+		switch {
+		case err == nil:
+			sent += len(texts)
+		case errors.As(err, &partial):
+			sent += min(partial.Sent, len(texts))
+		}
+		texts = nil
+		return err
+	}
+	for _, m := range msgs {
+		if m.Markup != markup {
+			if err := flush(); err != nil {
+				return sent, err
+			}
+			markup = m.Markup
+		}
+		if len(m.Photo) == 0 || (photos == nil && formatted == nil) {
+			texts = append(texts, m.Text)
+			continue
+		}
+		if err := flush(); err != nil {
+			return sent, err
+		}
+		var err error
+		if formatted != nil {
+			err = formatted.PublishFormattedPhoto(ctx, m.Photo, m.Text, string(m.Markup))
+		} else {
+			err = photos.PublishPhoto(ctx, m.Photo, m.Text)
+		}
+		if err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	err := flush()
+	return sent, err
+}
+
+// sleep waits for d and returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,187 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+type fixedBackoff time.Duration
+
+func (b fixedBackoff) Next(int) time.Duration { return time.Duration(b) }
+
+// flakyPublisher fails the first fail calls, after sending up to partial
+// messages of each.
+type flakyPublisher struct {
+	fail    int
+	partial int
+	calls   int
+	msgs    [][]string
+}
+
+func (p *flakyPublisher) PublishMessages(ctx context.Context, msgs []string) error {
+	p.calls++
+	if p.calls <= p.fail {
+		sent := min(p.partial, len(msgs))
+		if sent == 0 {
+			return errors.New("telegram unavailable")
+		}
+		p.msgs = append(p.msgs, msgs[:sent])
+		return &ports.PartialPublishError{Sent: sent, Err: errors.New("telegram unavailable")}
+	}
+	p.msgs = append(p.msgs, msgs)
+	return nil
+}
+
+func TestPublishWithRetry(t *testing.T) {
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	d := Delivery{Symbol: "EURUSD", Messages: []OutboundMessage{
+		{Text: "short", Expires: now.Add(5 * time.Millisecond)},
+		{Text: "long", Expires: now.Add(time.Minute)},
+		{Text: "resolution"},
+	}}
+
+	tests := []struct {
+		name      string
+		messages  []OutboundMessage
+		fail      int
+		partial   int
+		opts      RetryOptions
+		calls     int
+		delivered [][]string
+		err       string
+		attempts  int
+		letter    []string
+	}{
+		{name: "no retries", fail: 1, opts: RetryOptions{}, calls: 1, err: "telegram unavailable", attempts: 1, letter: []string{"short", "long", "resolution"}},
+		{name: "recovers", fail: 1, opts: RetryOptions{MaxAttempts: 3, Backoff: fixedBackoff(time.Millisecond)}, calls: 2, delivered: [][]string{{"short", "long", "resolution"}}},
+		{name: "exhausted", fail: 5, opts: RetryOptions{MaxAttempts: 3, Backoff: fixedBackoff(time.Millisecond)}, calls: 3, err: "telegram unavailable", attempts: 3, letter: []string{"short", "long", "resolution"}},
+		{name: "drops expiring messages", fail: 1, opts: RetryOptions{MaxAttempts: 3, Backoff: fixedBackoff(10 * time.Millisecond)}, calls: 2, delivered: [][]string{{"long", "resolution"}}},
+		{
+			name:      "resumes after partial delivery",
+			fail:      1,
+			partial:   1,
+			opts:      RetryOptions{MaxAttempts: 3, Backoff: fixedBackoff(time.Millisecond)},
+			calls:     2,
+			delivered: [][]string{{"short"}, {"long", "resolution"}},
+		},
+		{
+			name:      "dead letter keeps undelivered messages",
+			fail:      5,
+			partial:   1,
+			opts:      RetryOptions{MaxAttempts: 2, Backoff: fixedBackoff(time.Millisecond)},
+			calls:     2,
+			delivered: [][]string{{"short"}, {"long"}},
+			err:       "telegram unavailable",
+			attempts:  2,
+			letter:    []string{"resolution"},
+		},
+		{
+			name:     "bounded by ttl",
+			messages: d.Messages[:2],
+			fail:     5,
+			opts:     RetryOptions{MaxAttempts: 10, Backoff: fixedBackoff(time.Minute)},
+			calls:    1,
+			err:      "signal expired before retry",
+			attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			store := &testutils.MockDeadLetterStore{}
+			tt.opts.DeadLetters = store
+			pub := &flakyPublisher{fail: tt.fail, partial: tt.partial}
+			dd := d
+			if tt.messages != nil {
+				dd.Messages = tt.messages
+			}
+			err := publishWithRetry(context.Background(), pub, slog.New(slog.NewTextHandler(io.Discard, nil)), tt.opts, dd, clock)
+			if pub.calls != tt.calls || !reflect.DeepEqual(pub.msgs, tt.delivered) {
+				t.Fatalf("expected %d calls delivering %q, got %d delivering %q", tt.calls, tt.delivered, pub.calls, pub.msgs)
+			}
+			letters, _ := store.List(context.Background())
+			if tt.err == "" {
+				if err != nil || len(letters) != 0 {
+					t.Fatalf("expected delivery, got %v and dead letters %+v", err, letters)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+			if tt.letter == nil {
+				if len(letters) != 0 {
+					t.Fatalf("expected no dead letter once every message expired, got %+v", letters)
+				}
+				return
+			}
+			if len(letters) != 1 || letters[0].Attempts != tt.attempts || letters[0].Symbol != "EURUSD" || !reflect.DeepEqual(letters[0].Messages, tt.letter) {
+				t.Fatalf("expected a dead letter with %q, got %+v", tt.letter, letters)
+			}
+		})
+	}
+}
+
+func TestPublishWithRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	store := &testutils.MockDeadLetterStore{}
+	err := publishWithRetry(ctx, &flakyPublisher{fail: 5}, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		RetryOptions{MaxAttempts: 5, Backoff: fixedBackoff(time.Minute), DeadLetters: store},
+		Delivery{Symbol: "EURUSD", Messages: msgs("a")}, time.Now)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if letters, _ := store.List(context.Background()); len(letters) != 1 {
+		t.Fatalf("expected a dead letter, got %+v", letters)
+	}
+}
+
+func TestPublishMessages(t *testing.T) {
+	msgs := []OutboundMessage{{Text: "a"}, {Text: "b", Photo: []byte("B")}, {Text: "c", Photo: []byte("C")}, {Text: "d"}}
+
+	photos := &testutils.MockPhotoPublisher{}
+	if sent, err := publishMessages(context.Background(), photos, msgs); err != nil || sent != 4 {
+		t.Fatalf("expected 4 messages sent, got %d and %v", sent, err)
+	}
+	wantPhotos := []testutils.PublishedPhoto{{Photo: []byte("B"), Caption: "b"}, {Photo: []byte("C"), Caption: "c"}}
+	if !reflect.DeepEqual(photos.Messages, [][]string{{"a"}, {"d"}}) || !reflect.DeepEqual(photos.Photos, wantPhotos) {
+		t.Fatalf("expected texts around the photos, got %q and %+v", photos.Messages, photos.Photos)
+	}
+
+	text := &testutils.MockPublisher{}
+	if _, err := publishMessages(context.Background(), text, msgs); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(text.Messages, [][]string{{"a", "b", "c", "d"}}) {
+		t.Fatalf("expected captions as text, got %q", text.Messages)
+	}
+
+	failing := &testutils.MockPhotoPublisher{PhotoErr: errors.New("too large")}
+	if sent, err := publishMessages(context.Background(), failing, msgs); err == nil || sent != 1 {
+		t.Fatalf("expected the photo error after 1 message, got %d and %v", sent, err)
+	}
+	if !reflect.DeepEqual(failing.Messages, [][]string{{"a"}}) {
+		t.Fatalf("expected to stop at the failed photo, got %q", failing.Messages)
+	}
+
+	formatted := &testutils.MockFormattedPublisher{}
+	mixed := []OutboundMessage{{Text: "a", Markup: MarkupHTML}, {Text: "b", Markup: MarkupHTML}, {Text: "c"}, {Text: "d", Photo: []byte("D"), Markup: MarkupMarkdownV2}}
+	if sent, err := publishMessages(context.Background(), formatted, mixed); err != nil || sent != 4 {
+		t.Fatalf("expected 4 messages sent, got %d and %v", sent, err)
+	}
+	wantCalls := []testutils.FormattedCall{{Text: "a", ParseMode: "HTML"}, {Text: "b", ParseMode: "HTML"}, {Text: "c"}, {Text: "d", ParseMode: "MarkdownV2", Photo: true}}
+	if !reflect.DeepEqual(formatted.Formatted, wantCalls) || !reflect.DeepEqual(formatted.Messages, [][]string{{"a", "b"}, {"c"}}) {
+		t.Fatalf("expected runs split by markup, got %+v and %q", formatted.Formatted, formatted.Messages)
+	}
+}
@@ -0,0 +1,96 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// Router implements ports.SignalPublisher by routing signals to named
+// destinations according to an entity.RoutingTable, e.g. one Telegram group
+// per audience. Routing happens before any formatting. The table can be
+// replaced at any time with SetRules; publishes in flight keep the table
+// they started with.
+type Router struct {
+	fan      *FanOut
+	names    map[string]struct{}
+	calendar *entity.SessionCalendar
+	table    atomic.Pointer[entity.RoutingTable]
+}
+
+// NewRouter returns a router over destinations. Each destination's Filter
+// and Timeout still apply on top of its routing rules. calendar resolves
+// session rules and may be nil when no rule uses sessions. Until SetRules is
+// called no destination receives anything.
+func NewRouter(logger *slog.Logger, calendar *entity.SessionCalendar, destinations ...Sink) *Router {
+	r := &Router{
+		fan:      NewFanOut(logger, destinations...),
+		names:    make(map[string]struct{}, len(destinations)),
+		calendar: calendar,
+	}
+	for _, d := range destinations {
+		r.names[d.Name] = struct{}{}
+	}
+	r.table.Store(&entity.RoutingTable{})
+	return r
+}
+
+// SetRules replaces the routing table. It rejects tables naming unknown
+// destinations, or using sessions without a calendar, and keeps the current
+// table in that case.
+func (r *Router) SetRules(table entity.RoutingTable) error {
+	for name, rules := range table {
+		if _, ok := r.names[name]; !ok {
+			return fmt.Errorf("routing: unknown destination %q", name)
+		}
+		for _, rule := range rules {
+			if len(rule.Sessions) > 0 && r.calendar == nil {
+				return fmt.Errorf("routing: destination %q uses sessions but no session calendar is configured", name)
+			}
+		}
+	}
+	r.table.Store(&table)
+	return nil
+}
+
+// Rules returns the current routing table.
+func (r *Router) Rules() entity.RoutingTable {
+	return *r.table.Load()
+}
+
+// PublishSignals sends each destination the signals its rules match. Errors
+// are reported as for FanOut.PublishSignals.
+func (r *Router) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	table := r.Rules()
+	return dispatchSignals(ctx, r.fan, signals, func(d Sink, s entity.Signal) bool {
+		return table.Match(d.Name, s, r.calendar)
+	})
+}
+
+// PublishSignalCharts behaves like PublishSignals and passes each signal's
+// chart on to the destinations that implement ports.ChartPublisher.
+func (r *Router) PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error {
+	table := r.Rules()
+	return dispatchCharts(ctx, r.fan, signals, charts, func(d Sink, s entity.Signal) bool {
+		return table.Match(d.Name, s, r.calendar)
+	})
+}
+
+// PublishOutcomes sends outcomes to the destinations that implement
+// ports.OutcomePublisher and whose rules match the outcome's signal.
+func (r *Router) PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error {
+	table := r.Rules()
+	return dispatchOutcomes(ctx, r.fan, outcomes, func(d Sink, s entity.Signal) bool {
+		return table.Match(d.Name, s, r.calendar)
+	})
+}
+
+var (
+	_ ports.SignalPublisher  = (*Router)(nil)
+	_ ports.OutcomePublisher = (*Router)(nil)
+	_ ports.ChartPublisher   = (*Router)(nil)
+)
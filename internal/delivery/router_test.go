@@ -0,0 +1,96 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestRouter_PublishSignals(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.9},
+		{Symbol: "USDJPY", Direction: "DOWN", Confidence: 0.6},
+	}
+	majors := &testutils.MockSignalPublisher{}
+	confident := &testutils.MockSignalPublisher{}
+	vip := &testutils.MockSignalPublisher{}
+	r := NewRouter(logger, nil,
+		Sink{Name: "majors", Publisher: majors},
+		Sink{Name: "confident", Publisher: confident},
+		Sink{Name: "vip", Publisher: vip},
+	)
+
+	if err := r.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatal(err)
+	}
+	if len(majors.Batches())+len(confident.Batches())+len(vip.Batches()) != 0 {
+		t.Fatal("expected nothing routed before rules are set")
+	}
+
+	err := r.SetRules(entity.RoutingTable{
+		"majors":    {{Symbols: []string{"EURUSD", "GBPUSD"}}},
+		"confident": {{MinConfidence: 0.7}},
+		"vip":       {{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatal(err)
+	}
+	if got := majors.Signals(); !reflect.DeepEqual(got, signals[:1]) {
+		t.Errorf("majors got %+v", got)
+	}
+	if got := confident.Signals(); !reflect.DeepEqual(got, signals[:1]) {
+		t.Errorf("confident got %+v", got)
+	}
+	if got := vip.Signals(); !reflect.DeepEqual(got, signals) {
+		t.Errorf("vip got %+v", got)
+	}
+
+	// Reloading replaces the rules for the next publish.
+	if err := r.SetRules(entity.RoutingTable{"majors": {{Symbols: []string{"USDJPY"}}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatal(err)
+	}
+	if got := majors.Signals(); !reflect.DeepEqual(got, []entity.Signal{signals[0], signals[1]}) {
+		t.Errorf("majors after reload got %+v", got)
+	}
+	if got := vip.Batches(); len(got) != 1 {
+		t.Errorf("vip should have no rules after reload, got %d batches", len(got))
+	}
+}
+
+func TestRouter_SetRulesRejectsInvalid(t *testing.T) {
+	r := NewRouter(nil, nil, Sink{Name: "vip", Publisher: &testutils.MockSignalPublisher{}})
+	valid := entity.RoutingTable{"vip": {{}}}
+	if err := r.SetRules(valid); err != nil {
+		t.Fatal(err)
+	}
+	for name, table := range map[string]entity.RoutingTable{
+		"unknown destination":       {"nobody": {{}}},
+		"sessions without calendar": {"vip": {{Sessions: []string{"London"}}}},
+	} {
+		if err := r.SetRules(table); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if got := r.Rules(); !reflect.DeepEqual(got, valid) {
+		t.Errorf("rejected tables should keep the previous rules, got %+v", got)
+	}
+
+	cal := entity.NewSessionCalendar(entity.DefaultInstruments())
+	r = NewRouter(nil, cal, Sink{Name: "london", Publisher: &testutils.MockSignalPublisher{}, Timeout: time.Second})
+	if err := r.SetRules(entity.RoutingTable{"london": {{Sessions: []string{"London"}}}}); err != nil {
+		t.Fatal(err)
+	}
+}
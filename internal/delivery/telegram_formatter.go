@@ -1,10 +1,8 @@
 package delivery
 
 import (
-	"fmt"
-	"math"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/nomenarkt/signalengine/internal/entity"
 )
@@ -12,34 +10,81 @@ import (
 // FormatSignals converts trade signals into formatted strings suitable for
 // Telegram notifications. Each signal is represented as a multi-line message.
 func FormatSignals(signals []entity.Signal) []string {
+	return FormatSignalsWithInstruments(signals, nil)
+}
+
+// FormatSignalsWithInstruments behaves like FormatSignals but shows canonical
+// symbols and rounds prices to the precision registered in reg.
+func FormatSignalsWithInstruments(signals []entity.Signal, reg *entity.InstrumentRegistry) []string {
+	return formatSignals(entity.StageSignal, signals, reg)
+}
+
+// FormatFormingSignals formats signals raised on a bar that has not closed
+// yet. They are confirmed or cancelled once the bar closes.
+func FormatFormingSignals(signals []entity.Signal, reg *entity.InstrumentRegistry) []string {
+	return formatSignals(entity.StageEarly, signals, reg)
+}
+
+// FormatSignalResolution reports whether an early signal held when its bar
+// closed.
+func FormatSignalResolution(s entity.Signal, confirmed bool, reg *entity.InstrumentRegistry) string {
+	s.Stage = entity.StageCancelled
+	if confirmed {
+		s.Stage = entity.StageConfirmed
+	}
+	return FormatSignal(s, reg)
+}
+
+// FormatSignal formats s according to its stage: closed-bar and early signals
+// as full alerts, confirmations and cancellations as a single line. It uses
+// the built-in English layout of MessageTemplates.
+func FormatSignal(s entity.Signal, reg *entity.InstrumentRegistry) string {
+	return plainTemplates.withInstruments(reg).FormatSignal(s)
+}
+
+// FormatOutcome reports how a published signal settled, e.g.
+//
+//	🏆 WIN: EUR/USD UP (13:30 UTC)
+//	💵 1.08450 → 1.08500 (+5.0 pips)
+func FormatOutcome(o entity.SignalOutcome, reg *entity.InstrumentRegistry) string {
+	return plainTemplates.withInstruments(reg).FormatOutcome(o)
+}
+
+// FormatDigest summarises signals, which may belong to several symbols, in
+// one message with a line per signal, e.g.
+//
+//	📋 Digest: 2 low-confidence signals
+//	• 13:30 UTC EUR/USD UP 50% (candlestick pattern)
+//	❌ 13:32 UTC GBP/USD DOWN 55% (EMA interaction)
+func FormatDigest(signals []entity.Signal, reg *entity.InstrumentRegistry) string {
+	return plainTemplates.withInstruments(reg).FormatDigest(signals)
+}
+
+func formatSignals(stage entity.SignalStage, signals []entity.Signal, reg *entity.InstrumentRegistry) []string {
 	if len(signals) == 0 {
 		return nil
 	}
-
+	tmpl := plainTemplates.withInstruments(reg)
 	out := make([]string, 0, len(signals))
 	for _, s := range signals {
-		symbol := strings.ToUpper(s.Symbol)
-
-		confidence := int(math.Round((s.Confidence*100)/5) * 5)
-		if confidence > 100 {
-			confidence = 100
-		} else if confidence < 0 {
-			confidence = 0
-		}
-
-		minutes := int(s.TTL.Round(time.Minute) / time.Minute)
-		if minutes < 1 {
-			minutes = 1
-		}
-
-		msg := fmt.Sprintf(
-			"⚡ Signal: %s\n📈 Direction: %s\n🎯 Confidence: %d%%\n⏱️ Expires in: %dm",
-			symbol,
-			strings.ToUpper(s.Direction),
-			confidence,
-			minutes,
-		)
-		out = append(out, msg)
+		s.Stage = stage
+		out = append(out, tmpl.FormatSignal(s))
 	}
 	return out
 }
+
+// displaySymbol returns the canonical symbol when registered, otherwise the
+// upper-cased input.
+func displaySymbol(symbol string, reg *entity.InstrumentRegistry) string {
+	if inst, ok := reg.Lookup(symbol); ok {
+		return inst.Symbol
+	}
+	return strings.ToUpper(symbol)
+}
+
+func formatPrice(symbol string, price float64, reg *entity.InstrumentRegistry) string {
+	if inst, ok := reg.Lookup(symbol); ok {
+		return inst.FormatPrice(price)
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -47,3 +47,103 @@ func TestFormatSignals(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatFormingSignals(t *testing.T) {
+	s := entity.Signal{Symbol: "eurusd", Direction: "up", Confidence: 0.6, TTL: time.Minute}
+
+	got := FormatFormingSignals([]entity.Signal{s}, nil)
+	want := []string{"⏳ Early signal: EURUSD\n📈 Direction: UP\n🎯 Confidence: 60%\n⏱️ Expires in: 1m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := FormatSignalResolution(s, true, nil); got != "✅ Confirmed: EURUSD UP" {
+		t.Errorf("unexpected confirmation %q", got)
+	}
+	if got := FormatSignalResolution(s, false, nil); got != "❌ Cancelled: EURUSD UP" {
+		t.Errorf("unexpected cancellation %q", got)
+	}
+}
+
+func TestFormatSignalsWithInstruments(t *testing.T) {
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: time.Minute, Price: 1.0852349},
+		{Symbol: "xyz", Direction: "DOWN", Confidence: 0.5, TTL: time.Minute, Price: 12.5},
+	}
+	got := FormatSignalsWithInstruments(signals, entity.DefaultInstruments())
+	want := []string{
+		"⚡ Signal: EUR/USD\n📈 Direction: UP\n💵 Price: 1.08523\n🎯 Confidence: 80%\n⏱️ Expires in: 1m",
+		"⚡ Signal: XYZ\n📈 Direction: DOWN\n💵 Price: 12.5\n🎯 Confidence: 50%\n⏱️ Expires in: 1m",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := FormatSignalResolution(signals[0], true, entity.DefaultInstruments()); got != "✅ Confirmed: EUR/USD UP" {
+		t.Errorf("unexpected confirmation %q", got)
+	}
+}
+
+func TestFormatSignals_Tags(t *testing.T) {
+	got := FormatSignals([]entity.Signal{{Symbol: "eurusd", Direction: "up", Confidence: 0.5, TTL: time.Minute, Tags: []string{"news: USD CPI at 13:30 UTC"}}})
+	want := []string{"⚡ Signal: EURUSD\n📈 Direction: UP\n🎯 Confidence: 50%\n⏱️ Expires in: 1m\n⚠️ news: USD CPI at 13:30 UTC"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFormatOutcome(t *testing.T) {
+	bar := time.Date(2024, 5, 6, 13, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   entity.SignalOutcome
+		reg  *entity.InstrumentRegistry
+		want string
+	}{
+		{
+			name: "win with pips",
+			in:   entity.SignalOutcome{Signal: entity.Signal{Symbol: "EURUSD", Direction: "up", Time: bar}, EntryPrice: 1.0845, ExitPrice: 1.085, Pips: 5, Outcome: "WIN"},
+			reg:  entity.DefaultInstruments(),
+			want: "🏆 WIN: EUR/USD UP (13:30 UTC)\n💵 1.08450 → 1.08500 (+5.0 pips)",
+		},
+		{
+			name: "loss without instruments",
+			in:   entity.SignalOutcome{Signal: entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Time: bar}, EntryPrice: 1.0845, ExitPrice: 1.085, Outcome: "LOSS"},
+			want: "🔻 LOSS: EURUSD DOWN (13:30 UTC)\n💵 1.0845 → 1.085",
+		},
+		{
+			name: "neutral",
+			in:   entity.SignalOutcome{Signal: entity.Signal{Symbol: "USDJPY", Direction: "UP", Time: bar}, EntryPrice: 151.2, ExitPrice: 151.201, Pips: 0.1, Outcome: "NEUTRAL"},
+			reg:  entity.DefaultInstruments(),
+			want: "➖ NEUTRAL: USD/JPY UP (13:30 UTC)\n💵 151.200 → 151.201 (+0.1 pips)",
+		},
+	}
+	for _, tt := range tests {
+		if got := FormatOutcome(tt.in, tt.reg); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFormatDigest(t *testing.T) {
+	bar := time.Date(2024, 5, 6, 13, 30, 0, 0, time.UTC)
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.5, Time: bar, Source: "candlestick"},
+		{Symbol: "GBPUSD", Direction: "DOWN", Confidence: 0.55, Time: bar.Add(2 * time.Minute), Source: "ema_interaction", Stage: entity.StageCancelled},
+		{Symbol: "USDJPY", Direction: "UP", Confidence: 0.6, Stage: entity.StageEarly},
+	}
+	want := "📋 Digest: 3 low-confidence signals\n" +
+		"• 13:30 UTC EUR/USD UP 50% (candlestick pattern)\n" +
+		"❌ 13:32 UTC GBP/USD DOWN 55% (EMA interaction)\n" +
+		"⏳ USD/JPY UP 60%"
+	if got := FormatDigest(signals, entity.DefaultInstruments()); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	m, err := NewMessageTemplates(TemplateOptions{Locale: "pt", Markup: MarkupMarkdownV2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "📋 Resumo: 1 sinais de baixa confiança\n• 13:30 UTC EURUSD ALTA 50% \\(padrão de candlestick\\)"
+	if got := m.FormatDigest(signals[:1]); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// TelegramSinkOptions configures a TelegramSink.
+type TelegramSinkOptions struct {
+	// Instruments formats symbols and prices with the built-in layout.
+	Instruments *entity.InstrumentRegistry
+	// Templates formats signals and outcomes. Defaults to the built-in
+	// layout with Instruments; custom templates use their own Instruments.
+	// Messages are sent with the parse mode of their markup when the
+	// publisher implements ports.FormattedPublisher, as the Bot API chats
+	// do; other publishers must be configured to match it.
+	Templates *MessageTemplates
+	// Queue, when set, receives the messages instead of publishing inline.
+	Queue *DeliveryQueue
+	// Retry configures inline publish retries. Queued messages use the
+	// queue's retry options.
+	Retry RetryOptions
+	// Done, when set, is called with the result of every publish attempt,
+	// inline or queued.
+	Done   func(error)
+	Logger *slog.Logger
+}
+
+// TelegramSink implements ports.SignalPublisher by formatting signals as
+// Telegram messages for a ports.TelegramPublisher.
+type TelegramSink struct {
+	publisher ports.TelegramPublisher
+	opts      TelegramSinkOptions
+	now       func() time.Time
+}
+
+// NewTelegramSink returns a sink publishing to publisher.
+func NewTelegramSink(publisher ports.TelegramPublisher, opts TelegramSinkOptions) *TelegramSink {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.Templates == nil {
+		opts.Templates = plainTemplates.withInstruments(opts.Instruments)
+	}
+	return &TelegramSink{publisher: publisher, opts: opts, now: time.Now}
+}
+
+// PublishSignals formats signals and publishes them, or queues them when a
+// queue is configured. Signal and early-signal messages expire after the
+// signal's TTL; confirmations and cancellations never expire.
+func (t *TelegramSink) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	return t.PublishSignalCharts(ctx, signals, nil)
+}
+
+// PublishSignalCharts behaves like PublishSignals and sends each signal that
+// has a chart as a photo captioned with its message. The publisher must
+// implement ports.PhotoPublisher for charts to be sent.
+func (t *TelegramSink) PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	now := t.now()
+	msgs := make([]OutboundMessage, len(signals))
+	for i, s := range signals {
+		msgs[i] = t.message(t.opts.Templates.FormatSignal(s))
+		if i < len(charts) {
+			msgs[i].Photo = charts[i]
+		}
+		if (s.Stage == entity.StageSignal || s.Stage == entity.StageEarly) && s.TTL > 0 {
+			msgs[i].Expires = now.Add(s.TTL)
+		}
+	}
+	return t.deliver(ctx, Delivery{Symbol: signals[0].Symbol, Messages: msgs, Done: t.opts.Done})
+}
+
+// PublishDigest sends signals as a single summary message, queued when a
+// queue is configured. Digests never expire.
+func (t *TelegramSink) PublishDigest(ctx context.Context, signals []entity.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	var symbols []string
+	for _, s := range signals {
+		if !slices.Contains(symbols, s.Symbol) {
+			symbols = append(symbols, s.Symbol)
+		}
+	}
+	msg := t.message(t.opts.Templates.FormatDigest(signals))
+	return t.deliver(ctx, Delivery{Symbol: strings.Join(symbols, ","), Messages: []OutboundMessage{msg}, Done: t.opts.Done})
+}
+
+// message returns text rendered by the sink's templates as a message.
+func (t *TelegramSink) message(text string) OutboundMessage {
+	return OutboundMessage{Text: text, Markup: t.opts.Templates.Markup()}
+}
+
+// deliver queues d or publishes it inline, reporting the result to d.Done.
+func (t *TelegramSink) deliver(ctx context.Context, d Delivery) error {
+	if t.opts.Queue != nil {
+		return t.opts.Queue.Enqueue(ctx, d)
+	}
+	err := publishWithRetry(ctx, t.publisher, t.opts.Logger, t.opts.Retry, d, t.now)
+	if d.Done != nil {
+		d.Done(err)
+	}
+	return err
+}
+
+// PublishOutcomes sends one follow-up message per outcome, queued when a
+// queue is configured. Follow-ups never expire.
+func (t *TelegramSink) PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+	msgs := make([]OutboundMessage, len(outcomes))
+	for i, o := range outcomes {
+		msgs[i] = t.message(t.opts.Templates.FormatOutcome(o))
+	}
+	d := Delivery{Symbol: outcomes[0].Signal.Symbol, Messages: msgs}
+	if t.opts.Queue != nil {
+		return t.opts.Queue.Enqueue(ctx, d)
+	}
+	return publishWithRetry(ctx, t.publisher, t.opts.Logger, t.opts.Retry, d, t.now)
+}
+
+var (
+	_ ports.SignalPublisher  = (*TelegramSink)(nil)
+	_ ports.OutcomePublisher = (*TelegramSink)(nil)
+	_ ports.ChartPublisher   = (*TelegramSink)(nil)
+	_ ports.DigestPublisher  = (*TelegramSink)(nil)
+)
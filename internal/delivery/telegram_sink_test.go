@@ -0,0 +1,106 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestTelegramSink_PublishSignals(t *testing.T) {
+	now := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute},
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute, Stage: entity.StageEarly},
+		{Symbol: "EURUSD", Direction: "DOWN", TTL: 2 * time.Minute, Stage: entity.StageCancelled},
+	}
+	want := []OutboundMessage{
+		{Text: "⚡ Signal: EURUSD\n📈 Direction: UP\n🎯 Confidence: 80%\n⏱️ Expires in: 2m", Expires: now.Add(2 * time.Minute)},
+		{Text: "⏳ Early signal: EURUSD\n📈 Direction: UP\n🎯 Confidence: 80%\n⏱️ Expires in: 2m", Expires: now.Add(2 * time.Minute)},
+		{Text: "❌ Cancelled: EURUSD DOWN"},
+	}
+
+	q := NewDeliveryQueue(&gatedPublisher{}, DeliveryQueueOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	sink := NewTelegramSink(nil, TelegramSinkOptions{Queue: q})
+	sink.now = func() time.Time { return now }
+	if err := sink.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(q.items) != 1 || !reflect.DeepEqual(q.items[0].Messages, want) {
+		t.Fatalf("expected queued %+v, got %+v", want, q.items)
+	}
+
+	pub := &gatedPublisher{}
+	var result error = context.Canceled
+	inline := NewTelegramSink(pub, TelegramSinkOptions{Done: func(err error) { result = err }})
+	if err := inline.PublishSignals(context.Background(), signals[:1]); err != nil || result != nil {
+		t.Fatalf("publish: %v, done: %v", err, result)
+	}
+	if got := pub.published(); !reflect.DeepEqual(got, []string{want[0].Text}) {
+		t.Fatalf("expected inline publish, got %q", got)
+	}
+}
+
+func TestTelegramSink_Templates(t *testing.T) {
+	tmpl, err := NewMessageTemplates(TemplateOptions{Locale: "fr", Signal: `{{t "signal"}} {{.Symbol}} {{.DirectionText}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &gatedPublisher{}
+	sink := NewTelegramSink(pub, TelegramSinkOptions{Templates: tmpl})
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", TTL: 2 * time.Minute},
+		{Symbol: "EURUSD", Direction: "DOWN", TTL: 2 * time.Minute, Stage: entity.StageCancelled},
+	}
+	if err := sink.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pub.published(), []string{"Signal EURUSD HAUSSE", "❌ Annulé: EURUSD BAISSE"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTelegramSink_ParseMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		markup Markup
+	}{
+		{"plain", MarkupPlain},
+		{"markdown", MarkupMarkdownV2},
+		{"html", MarkupHTML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := NewMessageTemplates(TemplateOptions{Markup: tt.markup})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub := &testutils.MockFormattedPublisher{}
+			sink := NewTelegramSink(pub, TelegramSinkOptions{Templates: tmpl})
+			signals := []entity.Signal{
+				{Symbol: "EUR/USD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute},
+				{Symbol: "EUR/USD", Direction: "UP", TTL: 2 * time.Minute, Stage: entity.StageConfirmed},
+			}
+			if err := sink.PublishSignalCharts(context.Background(), signals, [][]byte{[]byte("png")}); err != nil {
+				t.Fatal(err)
+			}
+			if err := sink.PublishDigest(context.Background(), signals[:1]); err != nil {
+				t.Fatal(err)
+			}
+			want := []bool{true, false, false}
+			if len(pub.Formatted) != len(want) {
+				t.Fatalf("expected %d formatted sends, got %+v", len(want), pub.Formatted)
+			}
+			for i, c := range pub.Formatted {
+				if c.ParseMode != string(tt.markup) || c.Photo != want[i] {
+					t.Errorf("send %d: expected parse mode %q, photo %v, got %+v", i, tt.markup, want[i], c)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventImpact ranks how strongly an economic release is expected to move
+// prices.
+type EventImpact int
+
+// Impact levels, ordered from least to most disruptive.
+const (
+	ImpactLow EventImpact = iota + 1
+	ImpactMedium
+	ImpactHigh
+)
+
+// String returns the lower-case impact name.
+func (i EventImpact) String() string {
+	switch i {
+	case ImpactLow:
+		return "low"
+	case ImpactMedium:
+		return "medium"
+	case ImpactHigh:
+		return "high"
+	}
+	return fmt.Sprintf("impact(%d)", int(i))
+}
+
+// ParseEventImpact parses "low", "medium" or "high" in any case.
+func ParseEventImpact(s string) (EventImpact, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return ImpactLow, nil
+	case "medium":
+		return ImpactMedium, nil
+	case "high":
+		return ImpactHigh, nil
+	}
+	return 0, fmt.Errorf("unknown impact %q", s)
+}
+
+// EconomicEvent is a scheduled release such as NFP, CPI or a rate decision.
+type EconomicEvent struct {
+	Time     time.Time
+	Currency string
+	Impact   EventImpact
+	Title    string
+}
@@ -0,0 +1,213 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AssetClass groups instruments by market.
+type AssetClass string
+
+// Supported asset classes.
+const (
+	AssetForex     AssetClass = "forex"
+	AssetCrypto    AssetClass = "crypto"
+	AssetStock     AssetClass = "stock"
+	AssetIndex     AssetClass = "index"
+	AssetCommodity AssetClass = "commodity"
+)
+
+// TradingSession is a daily trading window in UTC. Open and Close are offsets
+// from midnight; a Close at or before Open wraps past midnight.
+type TradingSession struct {
+	Name  string
+	Open  time.Duration
+	Close time.Duration
+}
+
+// Contains reports whether t falls inside the session.
+func (s TradingSession) Contains(t time.Time) bool {
+	tod := timeOfDay(t)
+	if s.Open < s.Close {
+		return tod >= s.Open && tod < s.Close
+	}
+	return tod >= s.Open || tod < s.Close
+}
+
+// Instrument describes a tradable symbol.
+type Instrument struct {
+	// Symbol is the canonical name, e.g. "EUR/USD" or "BTC/USDT".
+	Symbol     string
+	AssetClass AssetClass
+	// PipSize is the price increment of one pip, e.g. 0.0001 for EUR/USD.
+	PipSize float64
+	// Precision is the number of decimals quoted by the market.
+	Precision int
+	Sessions  []TradingSession
+	// Codes maps provider names ("finage", "oanda", ...) to the symbol code
+	// that provider expects.
+	Codes map[string]string
+}
+
+// Code returns the provider-specific code for the instrument.
+func (i Instrument) Code(provider string) (string, bool) {
+	c, ok := i.Codes[provider]
+	return c, ok && c != ""
+}
+
+// Round rounds price to the instrument's precision.
+func (i Instrument) Round(price float64) float64 {
+	p := math.Pow10(i.Precision)
+	return math.Round(price*p) / p
+}
+
+// FormatPrice formats price with the instrument's precision.
+func (i Instrument) FormatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', i.Precision, 64)
+}
+
+// Pips converts a price difference into pips.
+func (i Instrument) Pips(delta float64) float64 {
+	if i.PipSize <= 0 {
+		return 0
+	}
+	return delta / i.PipSize
+}
+
+// SymbolKey reduces a symbol to upper case without separators so that
+// "EUR/USD", "eur_usd" and "EURUSD" compare equal.
+func SymbolKey(s string) string {
+	return strings.ToUpper(strings.NewReplacer("/", "", "_", "", "-", "", ":", "", " ", "").Replace(s))
+}
+
+// InstrumentRegistry resolves symbols in any common spelling to instruments.
+// A nil registry knows no instruments.
+type InstrumentRegistry struct {
+	byKey map[string]Instrument
+}
+
+// NewInstrumentRegistry validates instruments and indexes them by symbol.
+func NewInstrumentRegistry(instruments ...Instrument) (*InstrumentRegistry, error) {
+	r := &InstrumentRegistry{byKey: make(map[string]Instrument, len(instruments))}
+	for _, inst := range instruments {
+		if inst.Symbol == "" {
+			return nil, fmt.Errorf("instrument without symbol")
+		}
+		if inst.PipSize <= 0 {
+			return nil, fmt.Errorf("instrument %s: pip size must be positive", inst.Symbol)
+		}
+		if inst.Precision < 0 {
+			return nil, fmt.Errorf("instrument %s: negative precision", inst.Symbol)
+		}
+		key := SymbolKey(inst.Symbol)
+		if _, dup := r.byKey[key]; dup {
+			return nil, fmt.Errorf("duplicate instrument %s", inst.Symbol)
+		}
+		r.byKey[key] = inst
+	}
+	return r, nil
+}
+
+// Lookup returns the instrument for symbol.
+func (r *InstrumentRegistry) Lookup(symbol string) (Instrument, bool) {
+	if r == nil {
+		return Instrument{}, false
+	}
+	inst, ok := r.byKey[SymbolKey(symbol)]
+	return inst, ok
+}
+
+// Canonical returns the canonical form of symbol, or symbol unchanged when it
+// is not registered.
+func (r *InstrumentRegistry) Canonical(symbol string) string {
+	if inst, ok := r.Lookup(symbol); ok {
+		return inst.Symbol
+	}
+	return symbol
+}
+
+// Instruments returns all registered instruments ordered by symbol.
+func (r *InstrumentRegistry) Instruments() []Instrument {
+	if r == nil {
+		return nil
+	}
+	out := make([]Instrument, 0, len(r.byKey))
+	for _, inst := range r.byKey {
+		out = append(out, inst)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Symbol < out[j].Symbol })
+	return out
+}
+
+// Default forex sessions in UTC.
+var (
+	SessionAsia    = TradingSession{Name: "Asia", Open: 0, Close: 9 * time.Hour}
+	SessionLondon  = TradingSession{Name: "London", Open: 7 * time.Hour, Close: 16 * time.Hour}
+	SessionNewYork = TradingSession{Name: "New York", Open: 12 * time.Hour, Close: 21 * time.Hour}
+	Session24h     = TradingSession{Name: "24h", Open: 0, Close: 0}
+)
+
+// DefaultInstruments returns a registry with the major forex pairs, gold and
+// the main crypto pairs.
+func DefaultInstruments() *InstrumentRegistry {
+	fx := []TradingSession{SessionAsia, SessionLondon, SessionNewYork}
+	forex := func(base, quote string, pip float64, precision int) Instrument {
+		return Instrument{
+			Symbol:     base + "/" + quote,
+			AssetClass: AssetForex,
+			PipSize:    pip,
+			Precision:  precision,
+			Sessions:   fx,
+			Codes: map[string]string{
+				"finage":  base + quote,
+				"oanda":   base + "_" + quote,
+				"polygon": base + "/" + quote,
+			},
+		}
+	}
+	crypto := func(base, quote string) Instrument {
+		return Instrument{
+			Symbol:     base + "/" + quote,
+			AssetClass: AssetCrypto,
+			PipSize:    0.01,
+			Precision:  2,
+			Sessions:   []TradingSession{Session24h},
+			Codes: map[string]string{
+				"finage":  base + quote,
+				"binance": base + quote,
+				"polygon": base + "-" + quote,
+			},
+		}
+	}
+
+	r, err := NewInstrumentRegistry(
+		forex("EUR", "USD", 0.0001, 5),
+		forex("GBP", "USD", 0.0001, 5),
+		forex("AUD", "USD", 0.0001, 5),
+		forex("NZD", "USD", 0.0001, 5),
+		forex("USD", "CAD", 0.0001, 5),
+		forex("USD", "CHF", 0.0001, 5),
+		forex("EUR", "GBP", 0.0001, 5),
+		forex("USD", "JPY", 0.01, 3),
+		forex("EUR", "JPY", 0.01, 3),
+		forex("GBP", "JPY", 0.01, 3),
+		Instrument{
+			Symbol:     "XAU/USD",
+			AssetClass: AssetCommodity,
+			PipSize:    0.01,
+			Precision:  2,
+			Sessions:   fx,
+			Codes:      map[string]string{"finage": "XAUUSD", "oanda": "XAU_USD", "polygon": "XAU/USD"},
+		},
+		crypto("BTC", "USDT"),
+		crypto("ETH", "USDT"),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInstrumentRegistry_Lookup(t *testing.T) {
+	reg := DefaultInstruments()
+
+	tests := []struct {
+		in    string
+		want  string
+		found bool
+	}{
+		{in: "EUR/USD", want: "EUR/USD", found: true},
+		{in: "eurusd", want: "EUR/USD", found: true},
+		{in: "EUR_USD", want: "EUR/USD", found: true},
+		{in: "btc-usdt", want: "BTC/USDT", found: true},
+		{in: "AAPL", want: "AAPL", found: false},
+	}
+	for _, tt := range tests {
+		inst, ok := reg.Lookup(tt.in)
+		if ok != tt.found {
+			t.Fatalf("%s: expected found=%v", tt.in, tt.found)
+		}
+		if ok && inst.Symbol != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.in, tt.want, inst.Symbol)
+		}
+		if got := reg.Canonical(tt.in); got != tt.want {
+			t.Fatalf("%s: expected canonical %s, got %s", tt.in, tt.want, got)
+		}
+	}
+
+	var none *InstrumentRegistry
+	if _, ok := none.Lookup("EURUSD"); ok || none.Canonical("eurusd") != "eurusd" {
+		t.Fatalf("nil registry should know no instruments")
+	}
+}
+
+func TestInstrument_Prices(t *testing.T) {
+	reg := DefaultInstruments()
+	eur, _ := reg.Lookup("EURUSD")
+	jpy, _ := reg.Lookup("USDJPY")
+
+	if got := eur.FormatPrice(1.085234); got != "1.08523" {
+		t.Fatalf("unexpected EURUSD price %s", got)
+	}
+	if got := jpy.Round(149.98765); got != 149.988 {
+		t.Fatalf("unexpected USDJPY rounding %v", got)
+	}
+	if got := eur.Pips(0.0012); got < 11.999 || got > 12.001 {
+		t.Fatalf("expected 12 pips, got %v", got)
+	}
+	if code, ok := jpy.Code("oanda"); !ok || code != "USD_JPY" {
+		t.Fatalf("unexpected oanda code %q", code)
+	}
+	if _, ok := eur.Code("binance"); ok {
+		t.Fatalf("expected no binance code for EUR/USD")
+	}
+}
+
+func TestNewInstrumentRegistry_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []Instrument
+	}{
+		{name: "missing symbol", in: []Instrument{{PipSize: 1}}},
+		{name: "zero pip", in: []Instrument{{Symbol: "EUR/USD"}}},
+		{name: "duplicate", in: []Instrument{{Symbol: "EUR/USD", PipSize: 1}, {Symbol: "EURUSD", PipSize: 1}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewInstrumentRegistry(tt.in...); err == nil {
+			t.Fatalf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestTradingSession_Contains(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	overnight := TradingSession{Name: "Sydney", Open: 21 * time.Hour, Close: 6 * time.Hour}
+
+	tests := []struct {
+		s    TradingSession
+		at   time.Duration
+		want bool
+	}{
+		{SessionLondon, 7 * time.Hour, true},
+		{SessionLondon, 16 * time.Hour, false},
+		{overnight, 23 * time.Hour, true},
+		{overnight, 5 * time.Hour, true},
+		{overnight, 12 * time.Hour, false},
+		{Session24h, 13 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Contains(day.Add(tt.at)); got != tt.want {
+			t.Errorf("%s at %s: expected %v, got %v", tt.s.Name, tt.at, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "slices"
+
+// RoutingRule selects the signals a destination receives. Empty fields match
+// everything.
+type RoutingRule struct {
+	// Symbols in any common spelling, e.g. "EUR/USD" or "EURUSD".
+	Symbols    []string
+	Directions []string
+	// Sources lists scorer names, see Signal.Source.
+	Sources []string
+	// Sessions lists trading session names, e.g. "London". A signal matches
+	// when one of them is active at its bar time.
+	Sessions []string
+	// MinConfidence drops signals below this confidence. Confirmations and
+	// cancellations carry the confidence of their early signal, so they reach
+	// exactly the destinations the early signal did.
+	MinConfidence float64
+}
+
+// Match reports whether s passes the rule. cal resolves Sessions; when it is
+// nil, rules listing sessions match nothing.
+func (r RoutingRule) Match(s Signal, cal *SessionCalendar) bool {
+	if len(r.Symbols) > 0 && !slices.ContainsFunc(r.Symbols, func(sym string) bool {
+		return SymbolKey(sym) == SymbolKey(s.Symbol)
+	}) {
+		return false
+	}
+	if len(r.Directions) > 0 && !slices.Contains(r.Directions, s.Direction) {
+		return false
+	}
+	if len(r.Sources) > 0 && !slices.Contains(r.Sources, s.Source) {
+		return false
+	}
+	if len(r.Sessions) > 0 {
+		if cal == nil || !slices.ContainsFunc(cal.ActiveSessions(s.Symbol, s.Time), func(name string) bool {
+			return slices.Contains(r.Sessions, name)
+		}) {
+			return false
+		}
+	}
+	return s.Confidence >= r.MinConfidence
+}
+
+// RoutingTable maps destination names to their rules. A destination receives
+// a signal when any of its rules match, and nothing when it has no rules.
+type RoutingTable map[string][]RoutingRule
+
+// Match reports whether destination receives s.
+func (t RoutingTable) Match(destination string, s Signal, cal *SessionCalendar) bool {
+	return slices.ContainsFunc(t[destination], func(r RoutingRule) bool { return r.Match(s, cal) })
+}
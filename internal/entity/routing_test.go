@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoutingRule_Match(t *testing.T) {
+	cal := NewSessionCalendar(DefaultInstruments())
+	// 2024-01-03 13:00 UTC is a Wednesday in the London/New York overlap.
+	overlap := time.Date(2024, 1, 3, 13, 0, 0, 0, time.UTC)
+	up := Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.6, Source: "rsi_divergence", Time: overlap}
+
+	tests := []struct {
+		name   string
+		rule   RoutingRule
+		signal Signal
+		cal    *SessionCalendar
+		want   bool
+	}{
+		{name: "empty matches all", signal: up, want: true},
+		{name: "symbol spelling", rule: RoutingRule{Symbols: []string{"EUR/USD", "GBPUSD"}}, signal: up, want: true},
+		{name: "other symbol", rule: RoutingRule{Symbols: []string{"GBPUSD"}}, signal: up, want: false},
+		{name: "direction", rule: RoutingRule{Directions: []string{"DOWN"}}, signal: up, want: false},
+		{name: "source", rule: RoutingRule{Sources: []string{"rsi_divergence"}}, signal: up, want: true},
+		{name: "other source", rule: RoutingRule{Sources: []string{"candlestick"}}, signal: up, want: false},
+		{name: "session", rule: RoutingRule{Sessions: []string{"New York"}}, signal: up, cal: cal, want: true},
+		{name: "other session", rule: RoutingRule{Sessions: []string{"Asia"}}, signal: up, cal: cal, want: false},
+		{name: "session without calendar", rule: RoutingRule{Sessions: []string{"London"}}, signal: up, want: false},
+		{name: "below confidence", rule: RoutingRule{MinConfidence: 0.7}, signal: up, want: false},
+		{
+			name:   "resolution below confidence",
+			rule:   RoutingRule{MinConfidence: 0.7},
+			signal: Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.6, Stage: StageCancelled},
+			want:   false,
+		},
+		{
+			name:   "resolution at confidence",
+			rule:   RoutingRule{MinConfidence: 0.7},
+			signal: Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.7, Stage: StageCancelled},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Match(tt.signal, tt.cal); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRoutingTable_Match(t *testing.T) {
+	table := RoutingTable{
+		"majors": {{Symbols: []string{"EURUSD"}}, {Symbols: []string{"GBPUSD"}}},
+		"vip":    {{}},
+	}
+	gbp := Signal{Symbol: "GBPUSD", Direction: "UP"}
+	if !table.Match("majors", gbp, nil) || !table.Match("vip", gbp, nil) {
+		t.Fatal("expected any matching rule to route the signal")
+	}
+	if table.Match("unrouted", gbp, nil) {
+		t.Fatal("destination without rules should receive nothing")
+	}
+}
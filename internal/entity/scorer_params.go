@@ -0,0 +1,328 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// PinBarParams defines a pin bar by the shape of its candle, as fractions of
+// the bar's high-low range.
+type PinBarParams struct {
+	// MaxBody is the largest body. Defaults to 1/3.
+	MaxBody float64
+	// MinWick is the smallest rejection wick. Defaults to 2/3.
+	MinWick float64
+}
+
+func (p PinBarParams) inherit(base PinBarParams) PinBarParams {
+	p.MaxBody = orFloat(p.MaxBody, base.MaxBody)
+	p.MinWick = orFloat(p.MinWick, base.MinWick)
+	return p
+}
+
+func (p PinBarParams) validate() error {
+	if p.MaxBody < 0 || p.MinWick < 0 {
+		return fmt.Errorf("negative pin bar body %g or wick %g", p.MaxBody, p.MinWick)
+	}
+	if p.MaxBody+p.MinWick > 1 {
+		return fmt.Errorf("pin bar body %g and wick %g exceed the range", p.MaxBody, p.MinWick)
+	}
+	return nil
+}
+
+// RSIDivergenceParams configures the RSI divergence scorer.
+type RSIDivergenceParams struct {
+	// RSIPeriod is the RSI lookback. Defaults to 14.
+	RSIPeriod int
+	// Lookback is the number of bars, up to the latest, searched for the
+	// previous swing. Defaults to 20.
+	Lookback int
+	// ReversalBars is the number of latest bars searched for the reversal
+	// pattern confirming a divergence. They are left out of the swing
+	// search. Defaults to 3.
+	ReversalBars int
+	// PinBar defines the pin bars that confirm a divergence.
+	PinBar PinBarParams
+	// Confidence and TTL are those of the signals raised. They default to
+	// 0.8 and 2 minutes.
+	Confidence float64
+	TTL        time.Duration
+}
+
+func (p RSIDivergenceParams) inherit(base RSIDivergenceParams) RSIDivergenceParams {
+	p.RSIPeriod = orInt(p.RSIPeriod, base.RSIPeriod)
+	p.Lookback = orInt(p.Lookback, base.Lookback)
+	p.ReversalBars = orInt(p.ReversalBars, base.ReversalBars)
+	p.PinBar = p.PinBar.inherit(base.PinBar)
+	p.Confidence = orFloat(p.Confidence, base.Confidence)
+	p.TTL = orDuration(p.TTL, base.TTL)
+	return p
+}
+
+// WithDefaults returns p with unset fields taken from DefaultScorerParams.
+func (p RSIDivergenceParams) WithDefaults() RSIDivergenceParams {
+	return p.inherit(defaultScorerParams.RSIDivergence)
+}
+
+func (p RSIDivergenceParams) validate() error {
+	if err := validatePeriods(p.RSIPeriod, p.Lookback, p.ReversalBars); err != nil {
+		return err
+	}
+	if p.Lookback-p.ReversalBars < 2 {
+		return fmt.Errorf("lookback %d leaves fewer than 2 bars before the %d reversal bars", p.Lookback, p.ReversalBars)
+	}
+	if err := p.PinBar.validate(); err != nil {
+		return err
+	}
+	return validateSignal(p.Confidence, p.TTL)
+}
+
+// EMAInteractionParams configures the EMA crossover scorer.
+type EMAInteractionParams struct {
+	// FastPeriod and SlowPeriod are the crossing EMAs. They default to 8
+	// and 21.
+	FastPeriod int
+	SlowPeriod int
+	// Confidence and TTL are those of the signals raised. They default to
+	// 0.6 and one minute.
+	Confidence float64
+	TTL        time.Duration
+}
+
+func (p EMAInteractionParams) inherit(base EMAInteractionParams) EMAInteractionParams {
+	p.FastPeriod = orInt(p.FastPeriod, base.FastPeriod)
+	p.SlowPeriod = orInt(p.SlowPeriod, base.SlowPeriod)
+	p.Confidence = orFloat(p.Confidence, base.Confidence)
+	p.TTL = orDuration(p.TTL, base.TTL)
+	return p
+}
+
+// WithDefaults returns p with unset fields taken from DefaultScorerParams.
+func (p EMAInteractionParams) WithDefaults() EMAInteractionParams {
+	return p.inherit(defaultScorerParams.EMAInteraction)
+}
+
+func (p EMAInteractionParams) validate() error {
+	if err := validatePeriods(p.FastPeriod, p.SlowPeriod); err != nil {
+		return err
+	}
+	if p.FastPeriod >= p.SlowPeriod {
+		return fmt.Errorf("fast period %d not below slow period %d", p.FastPeriod, p.SlowPeriod)
+	}
+	return validateSignal(p.Confidence, p.TTL)
+}
+
+// CandlestickParams configures the candlestick pattern scorer.
+type CandlestickParams struct {
+	PinBar PinBarParams
+	// Confidence and TTL are those of the signals raised. They default to
+	// 0.5 and one minute.
+	Confidence float64
+	TTL        time.Duration
+}
+
+func (p CandlestickParams) inherit(base CandlestickParams) CandlestickParams {
+	p.PinBar = p.PinBar.inherit(base.PinBar)
+	p.Confidence = orFloat(p.Confidence, base.Confidence)
+	p.TTL = orDuration(p.TTL, base.TTL)
+	return p
+}
+
+// WithDefaults returns p with unset fields taken from DefaultScorerParams.
+func (p CandlestickParams) WithDefaults() CandlestickParams {
+	return p.inherit(defaultScorerParams.Candlestick)
+}
+
+func (p CandlestickParams) validate() error {
+	if err := p.PinBar.validate(); err != nil {
+		return err
+	}
+	return validateSignal(p.Confidence, p.TTL)
+}
+
+// ScorerParams configures every scorer. Zero fields are unset and take a
+// default; Validate rejects negative ones.
+type ScorerParams struct {
+	RSIDivergence  RSIDivergenceParams
+	EMAInteraction EMAInteractionParams
+	Candlestick    CandlestickParams
+}
+
+// defaultScorerParams suit forex majors on one-minute bars.
+var defaultScorerParams = ScorerParams{
+	RSIDivergence: RSIDivergenceParams{
+		RSIPeriod:    14,
+		Lookback:     20,
+		ReversalBars: 3,
+		PinBar:       PinBarParams{MaxBody: 1.0 / 3, MinWick: 2.0 / 3},
+		Confidence:   0.8,
+		TTL:          2 * time.Minute,
+	},
+	EMAInteraction: EMAInteractionParams{FastPeriod: 8, SlowPeriod: 21, Confidence: 0.6, TTL: time.Minute},
+	Candlestick: CandlestickParams{
+		PinBar:     PinBarParams{MaxBody: 1.0 / 3, MinWick: 2.0 / 3},
+		Confidence: 0.5,
+		TTL:        time.Minute,
+	},
+}
+
+// DefaultScorerParams returns the built-in parameters, which suit forex
+// majors on one-minute bars.
+func DefaultScorerParams() ScorerParams {
+	return defaultScorerParams
+}
+
+// Inherit returns p with unset fields taken from base.
+func (p ScorerParams) Inherit(base ScorerParams) ScorerParams {
+	p.RSIDivergence = p.RSIDivergence.inherit(base.RSIDivergence)
+	p.EMAInteraction = p.EMAInteraction.inherit(base.EMAInteraction)
+	p.Candlestick = p.Candlestick.inherit(base.Candlestick)
+	return p
+}
+
+// WithDefaults returns p with unset fields taken from DefaultScorerParams.
+func (p ScorerParams) WithDefaults() ScorerParams {
+	return p.Inherit(defaultScorerParams)
+}
+
+// Validate checks p once defaults are applied.
+func (p ScorerParams) Validate() error {
+	p = p.WithDefaults()
+	if err := p.RSIDivergence.validate(); err != nil {
+		return fmt.Errorf("rsi divergence: %w", err)
+	}
+	if err := p.EMAInteraction.validate(); err != nil {
+		return fmt.Errorf("ema interaction: %w", err)
+	}
+	if err := p.Candlestick.validate(); err != nil {
+		return fmt.Errorf("candlestick: %w", err)
+	}
+	return nil
+}
+
+// MinBars is the number of candles the indicators need to warm up: the RSI
+// period before the divergence lookback, or the slow EMA period, whichever
+// is longer.
+func (p ScorerParams) MinBars() int {
+	p = p.WithDefaults()
+	return max(p.RSIDivergence.Lookback+p.RSIDivergence.RSIPeriod, p.EMAInteraction.SlowPeriod+1)
+}
+
+// ScorerParamsConfig configures a ScorerParamsTable.
+type ScorerParamsConfig struct {
+	// Default applies to every symbol. Unset fields take the built-in
+	// defaults.
+	Default ScorerParams
+	// AssetClasses override Default for the instruments of a class. Unset
+	// fields inherit from Default.
+	AssetClasses map[AssetClass]ScorerParams
+	// Symbols override the asset class and Default per symbol, in any
+	// common spelling. Unset fields inherit from the symbol's asset class,
+	// then from Default.
+	Symbols map[string]ScorerParams
+	// Instruments resolves the asset class of symbols.
+	Instruments *InstrumentRegistry
+}
+
+// ScorerParamsTable selects scorer parameters per symbol or asset class. A
+// nil table yields the defaults for every symbol.
+type ScorerParamsTable struct {
+	def         ScorerParams
+	classes     map[AssetClass]ScorerParams
+	symbols     map[string]ScorerParams
+	instruments *InstrumentRegistry
+}
+
+// NewScorerParamsTable resolves the layers of cfg and validates the
+// parameters of every symbol and asset class.
+func NewScorerParamsTable(cfg ScorerParamsConfig) (*ScorerParamsTable, error) {
+	t := &ScorerParamsTable{
+		def:         cfg.Default.WithDefaults(),
+		classes:     make(map[AssetClass]ScorerParams, len(cfg.AssetClasses)),
+		symbols:     make(map[string]ScorerParams, len(cfg.Symbols)),
+		instruments: cfg.Instruments,
+	}
+	if err := t.def.Validate(); err != nil {
+		return nil, fmt.Errorf("scorer params: default: %w", err)
+	}
+	for class, p := range cfg.AssetClasses {
+		p = p.Inherit(t.def)
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("scorer params: %s: %w", class, err)
+		}
+		t.classes[class] = p
+	}
+	for sym, p := range cfg.Symbols {
+		key := SymbolKey(sym)
+		if _, dup := t.symbols[key]; dup {
+			return nil, fmt.Errorf("scorer params: duplicate symbol %s", sym)
+		}
+		p = p.Inherit(t.classParams(sym))
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("scorer params: %s: %w", sym, err)
+		}
+		t.symbols[key] = p
+	}
+	return t, nil
+}
+
+// For returns the parameters for symbol: its own, else its asset class's,
+// else the default.
+func (t *ScorerParamsTable) For(symbol string) ScorerParams {
+	if t == nil {
+		return defaultScorerParams
+	}
+	if p, ok := t.symbols[SymbolKey(symbol)]; ok {
+		return p
+	}
+	return t.classParams(symbol)
+}
+
+func (t *ScorerParamsTable) classParams(symbol string) ScorerParams {
+	if inst, ok := t.instruments.Lookup(symbol); ok {
+		if p, ok := t.classes[inst.AssetClass]; ok {
+			return p
+		}
+	}
+	return t.def
+}
+
+func validateSignal(confidence float64, ttl time.Duration) error {
+	if confidence < 0 || confidence > 1 {
+		return fmt.Errorf("confidence %g outside [0, 1]", confidence)
+	}
+	if ttl < 0 {
+		return fmt.Errorf("negative ttl %s", ttl)
+	}
+	return nil
+}
+
+func validatePeriods(periods ...int) error {
+	for _, n := range periods {
+		if n < 0 {
+			return fmt.Errorf("negative period %d", n)
+		}
+	}
+	return nil
+}
+
+func orInt(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func orFloat(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func orDuration(v, def time.Duration) time.Duration {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScorerParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  ScorerParams
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "crypto", params: ScorerParams{
+			RSIDivergence:  RSIDivergenceParams{Lookback: 30, ReversalBars: 5},
+			EMAInteraction: EMAInteractionParams{FastPeriod: 12, SlowPeriod: 26},
+		}},
+		{name: "fast above slow", params: ScorerParams{EMAInteraction: EMAInteractionParams{FastPeriod: 21, SlowPeriod: 8}}, wantErr: true},
+		{name: "fast above default slow", params: ScorerParams{EMAInteraction: EMAInteractionParams{FastPeriod: 30}}, wantErr: true},
+		{name: "lookback too short", params: ScorerParams{RSIDivergence: RSIDivergenceParams{Lookback: 4}}, wantErr: true},
+		{name: "pin bar wider than range", params: ScorerParams{Candlestick: CandlestickParams{PinBar: PinBarParams{MaxBody: 0.5}}}, wantErr: true},
+		{name: "confidence above 1", params: ScorerParams{RSIDivergence: RSIDivergenceParams{Confidence: 1.5}}, wantErr: true},
+		{name: "negative confidence", params: ScorerParams{Candlestick: CandlestickParams{Confidence: -0.5}}, wantErr: true},
+		{name: "negative ttl", params: ScorerParams{EMAInteraction: EMAInteractionParams{TTL: -time.Minute}}, wantErr: true},
+		{name: "negative period", params: ScorerParams{RSIDivergence: RSIDivergenceParams{RSIPeriod: -14}}, wantErr: true},
+		{name: "negative wick", params: ScorerParams{Candlestick: CandlestickParams{PinBar: PinBarParams{MinWick: -0.1}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	p := ScorerParams{RSIDivergence: RSIDivergenceParams{TTL: time.Minute}}.WithDefaults()
+	if p.RSIDivergence.Lookback != 20 || p.RSIDivergence.TTL != time.Minute || p.Candlestick.Confidence != 0.5 {
+		t.Fatalf("expected unset fields to take the defaults, got %+v", p)
+	}
+}
+
+func TestScorerParamsTable_For(t *testing.T) {
+	table, err := NewScorerParamsTable(ScorerParamsConfig{
+		Default: ScorerParams{Candlestick: CandlestickParams{Confidence: 0.55}},
+		AssetClasses: map[AssetClass]ScorerParams{
+			AssetCrypto: {RSIDivergence: RSIDivergenceParams{Lookback: 30}, Candlestick: CandlestickParams{TTL: 3 * time.Minute}},
+		},
+		Symbols: map[string]ScorerParams{
+			"btc_usdt": {Candlestick: CandlestickParams{Confidence: 0.45}},
+			"GBPUSD":   {EMAInteraction: EMAInteractionParams{SlowPeriod: 34}},
+		},
+		Instruments: DefaultInstruments(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		symbol     string
+		lookback   int
+		confidence float64
+		ttl        time.Duration
+		slow       int
+		bars       int
+	}{
+		{symbol: "EURUSD", lookback: 20, confidence: 0.55, ttl: time.Minute, slow: 21, bars: 34},
+		{symbol: "GBP/USD", lookback: 20, confidence: 0.55, ttl: time.Minute, slow: 34, bars: 35},
+		{symbol: "ETH/USDT", lookback: 30, confidence: 0.55, ttl: 3 * time.Minute, slow: 21, bars: 44},
+		{symbol: "BTCUSDT", lookback: 30, confidence: 0.45, ttl: 3 * time.Minute, slow: 21, bars: 44},
+		{symbol: "UNKNOWN", lookback: 20, confidence: 0.55, ttl: time.Minute, slow: 21, bars: 34},
+	}
+	for _, tt := range tests {
+		p := table.For(tt.symbol)
+		if p.RSIDivergence.Lookback != tt.lookback || p.Candlestick.Confidence != tt.confidence ||
+			p.Candlestick.TTL != tt.ttl || p.EMAInteraction.SlowPeriod != tt.slow {
+			t.Errorf("%s: unexpected params %+v", tt.symbol, p)
+		}
+		if p.MinBars() != tt.bars {
+			t.Errorf("%s: expected %d bars, got %d", tt.symbol, tt.bars, p.MinBars())
+		}
+	}
+
+	var none *ScorerParamsTable
+	if got := none.For("EURUSD"); got != DefaultScorerParams() {
+		t.Fatalf("expected defaults from a nil table, got %+v", got)
+	}
+
+	// A symbol override is validated against its asset class.
+	_, err = NewScorerParamsTable(ScorerParamsConfig{
+		AssetClasses: map[AssetClass]ScorerParams{AssetCrypto: {EMAInteraction: EMAInteractionParams{FastPeriod: 12, SlowPeriod: 26}}},
+		Symbols:      map[string]ScorerParams{"BTCUSDT": {EMAInteraction: EMAInteractionParams{SlowPeriod: 10}}},
+		Instruments:  DefaultInstruments(),
+	})
+	if err == nil {
+		t.Fatal("expected an error for a slow period below the class's fast period")
+	}
+}
@@ -0,0 +1,179 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// SessionOffHours names the bucket for times outside every session of an
+// instrument.
+const SessionOffHours = "Off-hours"
+
+// Weekly close for non-crypto markets: Friday 21:00 UTC to Sunday 21:00 UTC.
+const weeklyCloseHour = 21
+
+// Holiday closes the market for a full UTC day.
+type Holiday struct {
+	Date time.Time
+	Name string
+	// Symbols limits the holiday to these instruments. Empty applies to all
+	// non-crypto instruments.
+	Symbols []string
+}
+
+// SessionFilter restricts when signals may be raised.
+type SessionFilter struct {
+	// Sessions lists the allowed session names. Empty allows any session of
+	// the instrument.
+	Sessions []string
+	// SkipAfterOpen suppresses signals for this long after an allowed
+	// session opens or the market reopens after a weekend or holiday.
+	SkipAfterOpen time.Duration
+}
+
+// SessionCalendar answers market-hours questions for registered instruments.
+// Symbols missing from the registry are treated as always open.
+type SessionCalendar struct {
+	instruments *InstrumentRegistry
+	holidays    map[string][]Holiday
+}
+
+// NewSessionCalendar builds a calendar over instruments and holidays.
+func NewSessionCalendar(instruments *InstrumentRegistry, holidays ...Holiday) *SessionCalendar {
+	c := &SessionCalendar{instruments: instruments, holidays: make(map[string][]Holiday)}
+	for _, h := range holidays {
+		key := h.Date.UTC().Format(time.DateOnly)
+		c.holidays[key] = append(c.holidays[key], h)
+	}
+	return c
+}
+
+// MarketOpen reports whether symbol trades at t, taking the weekly close and
+// holidays into account.
+func (c *SessionCalendar) MarketOpen(symbol string, t time.Time) bool {
+	inst, ok := c.instruments.Lookup(symbol)
+	if !ok {
+		return true
+	}
+	return c.open(inst, t)
+}
+
+// Holiday returns the holiday closing symbol on t's UTC date, if any.
+func (c *SessionCalendar) Holiday(symbol string, t time.Time) (Holiday, bool) {
+	inst, ok := c.instruments.Lookup(symbol)
+	if !ok {
+		return Holiday{}, false
+	}
+	return c.holiday(inst, t)
+}
+
+// ActiveSessions returns the names of the instrument sessions containing t.
+// It returns SessionOffHours when the market is open outside every session
+// and nil when the market is closed or the symbol is unknown.
+func (c *SessionCalendar) ActiveSessions(symbol string, t time.Time) []string {
+	inst, ok := c.instruments.Lookup(symbol)
+	if !ok || !c.open(inst, t) {
+		return nil
+	}
+	var names []string
+	for _, s := range inst.Sessions {
+		if s.Contains(t) {
+			names = append(names, s.Name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{SessionOffHours}
+	}
+	return names
+}
+
+// Allow applies f to symbol at t and returns the reason when it is rejected.
+func (c *SessionCalendar) Allow(symbol string, t time.Time, f SessionFilter) (bool, string) {
+	inst, ok := c.instruments.Lookup(symbol)
+	if !ok {
+		return true, ""
+	}
+	if h, ok := c.holiday(inst, t); ok {
+		return false, "holiday: " + h.Name
+	}
+	if !c.open(inst, t) {
+		return false, "market closed"
+	}
+
+	var matched []TradingSession
+	for _, s := range inst.Sessions {
+		if s.Contains(t) && (len(f.Sessions) == 0 || slices.Contains(f.Sessions, s.Name)) {
+			matched = append(matched, s)
+		}
+	}
+	if len(inst.Sessions) > 0 && len(matched) == 0 {
+		return false, "outside sessions"
+	}
+
+	if f.SkipAfterOpen > 0 && c.justOpened(inst, t, f.SkipAfterOpen, matched) {
+		return false, fmt.Sprintf("within %s of open", f.SkipAfterOpen)
+	}
+	return true, ""
+}
+
+// justOpened reports whether one of sessions opened, or the market reopened,
+// less than window before t. Sessions without an open (24h) are ignored.
+func (c *SessionCalendar) justOpened(inst Instrument, t time.Time, window time.Duration, sessions []TradingSession) bool {
+	tod := timeOfDay(t)
+	for _, s := range sessions {
+		if s.Open == s.Close {
+			continue
+		}
+		if elapsed := (tod - s.Open + 24*time.Hour) % (24 * time.Hour); elapsed < window {
+			return true
+		}
+	}
+	for d := time.Minute; d <= window; d += time.Minute {
+		if !c.open(inst, t.Add(-d)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *SessionCalendar) open(inst Instrument, t time.Time) bool {
+	if _, ok := c.holiday(inst, t); ok {
+		return false
+	}
+	if inst.AssetClass == AssetCrypto {
+		return true
+	}
+	t = t.UTC()
+	switch t.Weekday() {
+	case time.Saturday:
+		return false
+	case time.Friday:
+		return t.Hour() < weeklyCloseHour
+	case time.Sunday:
+		return t.Hour() >= weeklyCloseHour
+	}
+	return true
+}
+
+func (c *SessionCalendar) holiday(inst Instrument, t time.Time) (Holiday, bool) {
+	for _, h := range c.holidays[t.UTC().Format(time.DateOnly)] {
+		if len(h.Symbols) == 0 {
+			if inst.AssetClass != AssetCrypto {
+				return h, true
+			}
+			continue
+		}
+		for _, s := range h.Symbols {
+			if SymbolKey(s) == SymbolKey(inst.Symbol) {
+				return h, true
+			}
+		}
+	}
+	return Holiday{}, false
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSessionCalendar(t *testing.T) {
+	christmas := time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)
+	cal := NewSessionCalendar(DefaultInstruments(),
+		Holiday{Date: christmas, Name: "Christmas"},
+		Holiday{Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Name: "Bank holiday", Symbols: []string{"GBPUSD"}},
+	)
+	// 2024-01-03 is a Wednesday.
+	wed := func(h, m int) time.Time { return time.Date(2024, 1, 3, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		symbol   string
+		at       time.Time
+		open     bool
+		sessions []string
+	}{
+		{name: "asia", symbol: "EURUSD", at: wed(3, 0), open: true, sessions: []string{"Asia"}},
+		{name: "london new york overlap", symbol: "EUR/USD", at: wed(13, 0), open: true, sessions: []string{"London", "New York"}},
+		{name: "off hours", symbol: "EURUSD", at: wed(22, 0), open: true, sessions: []string{SessionOffHours}},
+		{name: "friday close", symbol: "EURUSD", at: time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC), open: false},
+		{name: "saturday", symbol: "EURUSD", at: time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC), open: false},
+		{name: "sunday before open", symbol: "EURUSD", at: time.Date(2024, 1, 7, 20, 59, 0, 0, time.UTC), open: false},
+		{name: "sunday open", symbol: "EURUSD", at: time.Date(2024, 1, 7, 21, 0, 0, 0, time.UTC), open: true, sessions: []string{SessionOffHours}},
+		{name: "holiday", symbol: "USDJPY", at: christmas.Add(10 * time.Hour), open: false},
+		{name: "symbol holiday", symbol: "GBPUSD", at: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), open: false},
+		{name: "other symbol on symbol holiday", symbol: "EURUSD", at: time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC), open: true, sessions: []string{"Asia", "London"}},
+		{name: "crypto weekend", symbol: "BTCUSDT", at: time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC), open: true, sessions: []string{"24h"}},
+		{name: "crypto on christmas", symbol: "BTCUSDT", at: christmas, open: true, sessions: []string{"24h"}},
+		{name: "unknown symbol", symbol: "AAPL", at: time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC), open: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.MarketOpen(tt.symbol, tt.at); got != tt.open {
+				t.Fatalf("expected open=%v, got %v", tt.open, got)
+			}
+			if got := cal.ActiveSessions(tt.symbol, tt.at); !reflect.DeepEqual(got, tt.sessions) {
+				t.Fatalf("expected sessions %v, got %v", tt.sessions, got)
+			}
+		})
+	}
+}
+
+func TestSessionCalendar_Allow(t *testing.T) {
+	cal := NewSessionCalendar(DefaultInstruments(), Holiday{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Name: "New Year"})
+	london := SessionFilter{Sessions: []string{"London"}, SkipAfterOpen: 15 * time.Minute}
+
+	tests := []struct {
+		name   string
+		symbol string
+		at     time.Time
+		filter SessionFilter
+		allow  bool
+		reason string
+	}{
+		{name: "inside london", symbol: "EURUSD", at: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), filter: london, allow: true},
+		{name: "outside london", symbol: "EURUSD", at: time.Date(2024, 1, 3, 3, 0, 0, 0, time.UTC), filter: london, reason: "outside sessions"},
+		{name: "london warm-up", symbol: "EURUSD", at: time.Date(2024, 1, 3, 7, 10, 0, 0, time.UTC), filter: london, reason: "within 15m0s of open"},
+		{name: "london after warm-up", symbol: "EURUSD", at: time.Date(2024, 1, 3, 7, 15, 0, 0, time.UTC), filter: london, allow: true},
+		{name: "any session", symbol: "EURUSD", at: time.Date(2024, 1, 3, 3, 0, 0, 0, time.UTC), allow: true},
+		{name: "off hours", symbol: "EURUSD", at: time.Date(2024, 1, 3, 22, 0, 0, 0, time.UTC), reason: "outside sessions"},
+		{name: "holiday", symbol: "EURUSD", at: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), reason: "holiday: New Year"},
+		{name: "weekend", symbol: "EURUSD", at: time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), reason: "market closed"},
+		{name: "crypto after midnight", symbol: "BTCUSDT", at: time.Date(2024, 1, 3, 0, 5, 0, 0, time.UTC), filter: SessionFilter{SkipAfterOpen: 15 * time.Minute}, allow: true},
+		{name: "after holiday reopen", symbol: "EURUSD", at: time.Date(2024, 1, 2, 0, 30, 0, 0, time.UTC), filter: SessionFilter{SkipAfterOpen: time.Hour}, reason: "within 1h0m0s of open"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			allow, reason := cal.Allow(tt.symbol, tt.at, tt.filter)
+			if allow != tt.allow || reason != tt.reason {
+				t.Fatalf("expected (%v, %q), got (%v, %q)", tt.allow, tt.reason, allow, reason)
+			}
+		})
+	}
+}
@@ -1,6 +1,27 @@
 package entity
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SignalStage tells a signal apart from the follow-ups sent for early
+// signals.
+type SignalStage string
+
+// Signal stages. The zero value is StageSignal.
+const (
+	// StageSignal is a signal raised on a closed bar.
+	StageSignal SignalStage = ""
+	// StageEarly is a signal raised on a bar that has not closed yet.
+	StageEarly SignalStage = "early"
+	// StageConfirmed reports that an early signal held when its bar closed.
+	StageConfirmed SignalStage = "confirmed"
+	// StageCancelled reports that an early signal did not hold.
+	StageCancelled SignalStage = "cancelled"
+)
 
 // Signal represents a binary trade setup.
 type Signal struct {
@@ -8,4 +29,25 @@ type Signal struct {
 	Direction  string // "UP" or "DOWN"
 	Confidence float64
 	TTL        time.Duration
+	// Price is the close of the bar the signal was raised on. For early
+	// signals it is the bar's price when the signal was raised.
+	Price float64
+	// Time is the open time of that bar.
+	Time time.Time
+	// Source names the scorer that raised the signal, e.g. "rsi_divergence".
+	Source string
+	// Tags carries annotations added by filters, e.g. an upcoming news
+	// release.
+	Tags []string
+	// Stage distinguishes closed-bar signals from early signals and their
+	// resolutions.
+	Stage SignalStage
+}
+
+// ID identifies the setup behind s by symbol, direction, bar and TTL. An
+// early signal, its resolution and the closed-bar signal for the same setup
+// share an ID.
+func (s Signal) ID() string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%d|%d", SymbolKey(s.Symbol), s.Direction, s.Time.UnixNano(), s.TTL))
+	return hex.EncodeToString(sum[:8])
 }
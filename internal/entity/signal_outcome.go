@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// SignalOutcome is the settled result of a published signal.
+type SignalOutcome struct {
+	Signal Signal
+	// EntryTime is when the signal's bar closed and ExpiryTime when its TTL
+	// ran out.
+	EntryTime  time.Time
+	ExpiryTime time.Time
+	EntryPrice float64
+	ExitPrice  float64
+	// Pips is the move in the signal's direction. It is zero for symbols
+	// without a registered instrument.
+	Pips float64
+	// Outcome is "WIN", "LOSS" or "NEUTRAL".
+	Outcome string
+	Reason  string
+}
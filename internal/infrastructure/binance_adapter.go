@@ -0,0 +1,183 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// Default BinanceAdapter settings.
+const (
+	DefaultBinanceBaseURL      = "wss://stream.binance.com:9443"
+	DefaultBinanceReadDeadline = 30 * time.Second
+	DefaultBinanceStaleAfter   = 90 * time.Second
+	DefaultBinanceMaxCandleAge = 2 * time.Minute
+)
+
+// BinanceOptions configures a BinanceAdapter. Zero values fall back to the
+// defaults above.
+type BinanceOptions struct {
+	// BaseURL is the scheme and host of the market data stream.
+	BaseURL string
+	// ReadDeadline bounds how long a single read may block.
+	ReadDeadline time.Duration
+	// StaleAfter forces a reconnect when no candle arrives within the window.
+	StaleAfter time.Duration
+	// MaxCandleAge drops klines whose open time is older than this.
+	MaxCandleAge time.Duration
+	// Metrics records reconnects. Defaults to ports.NopMetrics.
+	Metrics ports.MetricsRecorder
+	// Instruments maps symbols to provider codes and canonical names. When
+	// nil, the adapter's built-in symbol conversion is used.
+	Instruments *entity.InstrumentRegistry
+	// EmitPartial also emits in-progress klines as Partial candles.
+	EmitPartial bool
+}
+
+func (o BinanceOptions) withDefaults() BinanceOptions {
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultBinanceBaseURL
+	}
+	if o.ReadDeadline <= 0 {
+		o.ReadDeadline = DefaultBinanceReadDeadline
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = DefaultBinanceStaleAfter
+	}
+	if o.MaxCandleAge <= 0 {
+		o.MaxCandleAge = DefaultBinanceMaxCandleAge
+	}
+	return o
+}
+
+// BinanceAdapter implements the MarketFeedAdapter using Binance 1m kline
+// streams. Only closed klines are emitted unless EmitPartial is set.
+type BinanceAdapter struct {
+	*wsFeed
+	baseURL     string
+	emitPartial bool
+}
+
+// NewBinanceAdapter initializes a BinanceAdapter. A nil dialer uses
+// websocket.DefaultDialer and a nil backoff uses ExponentialBackoff.
+func NewBinanceAdapter(logger *slog.Logger, dialer *websocket.Dialer, backoff ports.BackoffStrategy, opts BinanceOptions) *BinanceAdapter {
+	opts = opts.withDefaults()
+	feed := newWSFeed("binance", logger, dialer, backoff, opts.Metrics)
+	feed.instruments = opts.Instruments
+	feed.readDeadline = opts.ReadDeadline
+	feed.staleAfter = opts.StaleAfter
+	feed.maxCandleAge = opts.MaxCandleAge
+	return &BinanceAdapter{wsFeed: feed, baseURL: opts.BaseURL, emitPartial: opts.EmitPartial}
+}
+
+// endpoint builds the combined stream URL, e.g.
+// wss://stream.binance.com:9443/stream?streams=btcusdt@kline_1m/ethusdt@kline_1m.
+func (a *BinanceAdapter) endpoint(symbols []string) (string, error) {
+	u, err := url.Parse(a.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported base url scheme %q", u.Scheme)
+	}
+
+	streams := make([]string, len(symbols))
+	for i, s := range symbols {
+		code, _ := a.providerCode(s, func(s string) (string, error) { return normalizeSymbol(s), nil })
+		streams[i] = strings.ToLower(code) + "@kline_1m"
+	}
+	u.Path = path.Join("/", u.Path, "stream")
+	u.RawQuery = "streams=" + strings.Join(streams, "/")
+	return u.String(), nil
+}
+
+// StreamCandles connects to Binance and streams closed 1m klines for the
+// given symbols, preceded by Partial updates when EmitPartial is set.
+func (a *BinanceAdapter) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("no symbols provided")
+	}
+	endpoint, err := a.endpoint(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan ports.Candle)
+	go a.run(ctx, wsProtocol{
+		endpoint:  endpoint,
+		subscribe: func(*websocket.Conn) error { return nil },
+		decode: func(msg []byte) ([]ports.Candle, error) {
+			return decodeBinance(msg, a.emitPartial)
+		},
+	}, out)
+	return out, nil
+}
+
+// binanceKline models a combined-stream kline event.
+type binanceKline struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		Event  string `json:"e"`
+		Symbol string `json:"s"`
+		Kline  struct {
+			Start  int64  `json:"t"`
+			Open   string `json:"o"`
+			High   string `json:"h"`
+			Low    string `json:"l"`
+			Close  string `json:"c"`
+			Volume string `json:"v"`
+			Closed bool   `json:"x"`
+		} `json:"k"`
+	} `json:"data"`
+}
+
+// decodeBinance converts a kline event into a candle. Klines that have not
+// closed yet are skipped unless partial is set.
+func decodeBinance(msg []byte, partial bool) ([]ports.Candle, error) {
+	var ev binanceKline
+	if err := json.Unmarshal(msg, &ev); err != nil {
+		return nil, err
+	}
+	if ev.Data.Event != "kline" || (!ev.Data.Kline.Closed && !partial) {
+		return nil, nil
+	}
+
+	k := ev.Data.Kline
+	var prices [5]float64
+	for i, s := range []string{k.Open, k.High, k.Low, k.Close, k.Volume} {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse kline field: %w", err)
+		}
+		prices[i] = v
+	}
+	return []ports.Candle{{
+		Symbol:  normalizeSymbol(ev.Data.Symbol),
+		Time:    time.UnixMilli(k.Start),
+		Open:    prices[0],
+		High:    prices[1],
+		Low:     prices[2],
+		Close:   prices[3],
+		Volume:  prices[4],
+		Partial: !k.Closed,
+	}}, nil
+}
+
+var (
+	_ ports.MarketFeedAdapter  = (*BinanceAdapter)(nil)
+	_ ports.FeedHealthReporter = (*BinanceAdapter)(nil)
+)
@@ -0,0 +1,224 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// newLocalWSServer starts a plain WebSocket stand-in and returns its ws:// base
+// URL. handler receives the upgrade request and the connection.
+func newLocalWSServer(t *testing.T, handler func(*http.Request, *websocket.Conn)) (*httptest.Server, string) {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade error: %v", err)
+			return
+		}
+		defer c.Close()
+		handler(r, c)
+	}))
+	return srv, "ws://" + srv.Listener.Addr().String()
+}
+
+// receive reads n candles from ch or fails the test after a timeout.
+func receive(t *testing.T, ch <-chan ports.Candle, n int) []ports.Candle {
+	t.Helper()
+
+	out := make([]ports.Candle, 0, n)
+	for len(out) < n {
+		select {
+		case c, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed after %d candles", len(out))
+			}
+			out = append(out, c)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout after %d candles", len(out))
+		}
+	}
+	return out
+}
+
+func binanceKlineMsg(sym string, start time.Time, closed bool) []byte {
+	return []byte(fmt.Sprintf(`{"stream":"%s@kline_1m","data":{"e":"kline","s":"%s","k":{"t":%d,"o":"1.1","h":"1.3","l":"1.0","c":"1.2","v":"42.5","x":%t}}}`,
+		strings.ToLower(sym), sym, start.UnixMilli(), closed))
+}
+
+func TestBinanceAdapter_Endpoint(t *testing.T) {
+	t.Parallel()
+
+	a := NewBinanceAdapter(nil, nil, nil, BinanceOptions{})
+	got, err := a.endpoint([]string{"BTCUSDT", "eth/usdt"})
+	if err != nil {
+		t.Fatalf("endpoint: %v", err)
+	}
+	want := "wss://stream.binance.com:9443/stream?streams=btcusdt@kline_1m/ethusdt@kline_1m"
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	a = NewBinanceAdapter(nil, nil, nil, BinanceOptions{BaseURL: "https://example.com"})
+	if _, err := a.endpoint([]string{"BTCUSDT"}); err == nil {
+		t.Fatalf("expected scheme error")
+	}
+}
+
+func TestBinanceAdapter_StreamCandles(t *testing.T) {
+	start := time.Now().Truncate(time.Minute).Add(-time.Minute)
+
+	var (
+		mu    sync.Mutex
+		query string
+	)
+	srv, base := newLocalWSServer(t, func(r *http.Request, c *websocket.Conn) {
+		mu.Lock()
+		query = r.URL.RawQuery
+		mu.Unlock()
+		msgs := [][]byte{
+			binanceKlineMsg("BTCUSDT", start, false),
+			binanceKlineMsg("BTCUSDT", start, true),
+			binanceKlineMsg("BTCUSDT", start, true),
+			[]byte(`{"stream":"btcusdt@kline_1m","data":{"e":"kline","s":"BTCUSDT","k":{"t":1,"o":"bad","x":true}}}`),
+			binanceKlineMsg("ETHUSDT", start, true),
+		}
+		for _, m := range msgs {
+			if err := c.WriteMessage(websocket.TextMessage, m); err != nil {
+				t.Errorf("write message: %v", err)
+				return
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	})
+	defer srv.Close()
+
+	a := NewBinanceAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, &mockBackoff{}, BinanceOptions{BaseURL: base})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"BTCUSDT", "ETHUSDT"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	got := receive(t, ch, 2)
+	want := ports.Candle{Symbol: "BTCUSDT", Time: time.UnixMilli(start.UnixMilli()), Open: 1.1, High: 1.3, Low: 1.0, Close: 1.2, Volume: 42.5}
+	if got[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, got[0])
+	}
+	if got[1].Symbol != "ETHUSDT" {
+		t.Fatalf("expected ETHUSDT, got %s", got[1].Symbol)
+	}
+
+	h := a.FeedHealth()
+	if h.Counters.DroppedDuplicate != 1 || h.Counters.DroppedInvalid != 1 {
+		t.Fatalf("unexpected counters %+v", h.Counters)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if query != "streams=btcusdt@kline_1m/ethusdt@kline_1m" {
+		t.Fatalf("unexpected query %q", query)
+	}
+}
+
+func TestBinanceAdapter_EmitPartial(t *testing.T) {
+	start := time.Now().Truncate(time.Minute).Add(-time.Minute)
+
+	srv, base := newLocalWSServer(t, func(r *http.Request, c *websocket.Conn) {
+		for _, m := range [][]byte{
+			binanceKlineMsg("BTCUSDT", start, false),
+			binanceKlineMsg("BTCUSDT", start, false),
+			binanceKlineMsg("BTCUSDT", start, true),
+			binanceKlineMsg("BTCUSDT", start, false),
+		} {
+			if err := c.WriteMessage(websocket.TextMessage, m); err != nil {
+				t.Errorf("write message: %v", err)
+				return
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	})
+	defer srv.Close()
+
+	a := NewBinanceAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, &mockBackoff{}, BinanceOptions{BaseURL: base, EmitPartial: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"BTCUSDT"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	got := receive(t, ch, 3)
+	for i, want := range []bool{true, true, false} {
+		if got[i].Partial != want {
+			t.Fatalf("candle %d: expected partial %v, got %+v", i, want, got[i])
+		}
+	}
+	cancel()
+	for range ch {
+	}
+	if h := a.FeedHealth(); h.Counters.DroppedDuplicate != 1 {
+		t.Fatalf("expected late partial to be dropped, got %+v", h.Counters)
+	}
+}
+
+func TestBinanceAdapter_Instruments(t *testing.T) {
+	start := time.Now().Truncate(time.Minute).Add(-time.Minute)
+
+	var (
+		mu    sync.Mutex
+		query string
+	)
+	srv, base := newLocalWSServer(t, func(r *http.Request, c *websocket.Conn) {
+		mu.Lock()
+		query = r.URL.RawQuery
+		mu.Unlock()
+		if err := c.WriteMessage(websocket.TextMessage, binanceKlineMsg("BTCUSDT", start, true)); err != nil {
+			t.Errorf("write message: %v", err)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	})
+	defer srv.Close()
+
+	a := NewBinanceAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, &mockBackoff{}, BinanceOptions{
+		BaseURL:     base,
+		Instruments: entity.DefaultInstruments(),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"BTC/USDT"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if got := receive(t, ch, 1)[0]; got.Symbol != "BTC/USDT" {
+		t.Fatalf("expected canonical symbol, got %s", got.Symbol)
+	}
+	if _, ok := a.FeedHealth().LastMessage["BTC/USDT"]; !ok {
+		t.Fatalf("expected health keyed by canonical symbol")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if query != "streams=btcusdt@kline_1m" {
+		t.Fatalf("unexpected query %q", query)
+	}
+}
@@ -0,0 +1,104 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// candleRecord is the JSON line written per candle.
+type candleRecord struct {
+	Symbol string    `json:"symbol"`
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume,omitempty"`
+	Spread float64   `json:"spread,omitempty"`
+}
+
+// FileCandleRecorder implements ports.CandleRecorder as an append-only JSON
+// Lines file that LoadCandleFile reads back.
+type FileCandleRecorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCandleRecorder returns a recorder writing to path. The file is
+// created on the first candle.
+func NewFileCandleRecorder(path string) *FileCandleRecorder {
+	return &FileCandleRecorder{path: path}
+}
+
+// RecordCandle appends c to the file. Partial candles are skipped.
+func (r *FileCandleRecorder) RecordCandle(ctx context.Context, c ports.Candle) error {
+	if c.Partial {
+		return nil
+	}
+	b, err := json.Marshal(candleRecord{Symbol: c.Symbol, Time: c.Time.UTC(), Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume, Spread: c.Spread})
+	if err != nil {
+		return fmt.Errorf("encode candle: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open candles: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write candle: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadCandleFile reads candles written by FileCandleRecorder, grouped by
+// symbol and sorted by time. When a bar was recorded more than once, e.g.
+// across a restart, the last record wins.
+func LoadCandleFile(path string) (map[string][]ports.Candle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open candles: %w", err)
+	}
+	defer f.Close()
+
+	bars := make(map[string]map[time.Time]ports.Candle)
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec candleRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("candles %s line %d: %w", path, line, err)
+		}
+		if bars[rec.Symbol] == nil {
+			bars[rec.Symbol] = make(map[time.Time]ports.Candle)
+		}
+		bars[rec.Symbol][rec.Time] = ports.Candle{Symbol: rec.Symbol, Time: rec.Time, Open: rec.Open, High: rec.High, Low: rec.Low, Close: rec.Close, Volume: rec.Volume, Spread: rec.Spread}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read candles: %w", err)
+	}
+
+	data := make(map[string][]ports.Candle, len(bars))
+	for symbol, byTime := range bars {
+		candles := make([]ports.Candle, 0, len(byTime))
+		for _, c := range byTime {
+			candles = append(candles, c)
+		}
+		sort.Slice(candles, func(i, j int) bool { return candles[i].Time.Before(candles[j].Time) })
+		data[symbol] = candles
+	}
+	return data, nil
+}
+
+var _ ports.CandleRecorder = (*FileCandleRecorder)(nil)
@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestFileCandleRecorder(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "candles.jsonl")
+	r := NewFileCandleRecorder(path)
+
+	bar := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	candle := func(sym string, minute int, close float64) ports.Candle {
+		return ports.Candle{Symbol: sym, Time: bar.Add(time.Duration(minute) * time.Minute), Open: 1, High: 2, Low: 0.5, Close: close, Volume: 10}
+	}
+	for _, c := range []ports.Candle{
+		candle("EURUSD", 1, 1.1),
+		candle("GBPUSD", 0, 1.3),
+		candle("EURUSD", 0, 1.0),
+		{Symbol: "EURUSD", Time: bar.Add(2 * time.Minute), Close: 9, Partial: true},
+		candle("EURUSD", 1, 1.2), // recorded again after a restart
+	} {
+		if err := r.RecordCandle(ctx, c); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	got, err := LoadCandleFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := map[string][]ports.Candle{
+		"EURUSD": {candle("EURUSD", 0, 1.0), candle("EURUSD", 1, 1.2)},
+		"GBPUSD": {candle("GBPUSD", 0, 1.3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := LoadCandleFile(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Fatalf("expected error for a missing file")
+	}
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := LoadCandleFile(path); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
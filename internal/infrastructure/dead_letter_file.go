@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// deadLetterRecord is the JSON line written per dead letter.
+type deadLetterRecord struct {
+	Time     time.Time `json:"time"`
+	Symbol   string    `json:"symbol"`
+	Messages []string  `json:"messages"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+}
+
+// FileDeadLetterStore implements ports.DeadLetterStore as an append-only
+// JSON Lines file.
+type FileDeadLetterStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDeadLetterStore returns a store writing to path. The file is created
+// on the first Store.
+func NewFileDeadLetterStore(path string) *FileDeadLetterStore {
+	return &FileDeadLetterStore{path: path}
+}
+
+// Store appends dl to the file.
+func (s *FileDeadLetterStore) Store(ctx context.Context, dl ports.DeadLetter) error {
+	b, err := json.Marshal(deadLetterRecord(dl))
+	if err != nil {
+		return fmt.Errorf("encode dead letter: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open dead letters: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write dead letter: %w", err)
+	}
+	return f.Close()
+}
+
+// List reads every dead letter in the file. A missing file holds none.
+func (s *FileDeadLetterStore) List(ctx context.Context) ([]ports.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open dead letters: %w", err)
+	}
+	defer f.Close()
+
+	var out []ports.DeadLetter
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec deadLetterRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("dead letters %s line %d: %w", s.path, line, err)
+		}
+		out = append(out, ports.DeadLetter(rec))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read dead letters: %w", err)
+	}
+	return out, nil
+}
+
+var _ ports.DeadLetterStore = (*FileDeadLetterStore)(nil)
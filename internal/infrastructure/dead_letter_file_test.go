@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestFileDeadLetterStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "deadletters.jsonl")
+	s := NewFileDeadLetterStore(path)
+
+	got, err := s.List(ctx)
+	if err != nil || got != nil {
+		t.Fatalf("expected empty store, got %v, %v", got, err)
+	}
+
+	want := []ports.DeadLetter{
+		{Time: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), Symbol: "EURUSD", Messages: []string{"⚡ Signal: EURUSD"}, Attempts: 3, Error: "telegram: 502"},
+		{Time: time.Date(2024, 1, 3, 10, 5, 0, 0, time.UTC), Symbol: "GBPUSD", Messages: []string{"a", "b"}, Attempts: 1, Error: "context canceled"},
+	}
+	for _, dl := range want {
+		if err := s.Store(ctx, dl); err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	got, err = NewFileDeadLetterStore(path).List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := s.List(ctx); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// FileEconomicCalendar implements ports.EconomicCalendarPort over events
+// loaded once from a local CSV or JSON file.
+type FileEconomicCalendar struct {
+	events []entity.EconomicEvent
+}
+
+// NewFileEconomicCalendar loads events from path. The format follows the file
+// extension:
+//
+//   - .csv: header "time,currency,impact,title" with RFC 3339 times
+//   - .json: an array of {"time", "currency", "impact", "title"} objects
+func NewFileEconomicCalendar(path string) (*FileEconomicCalendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open economic calendar: %w", err)
+	}
+	defer f.Close()
+
+	var events []entity.EconomicEvent
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		events, err = decodeEventsCSV(f)
+	case ".json":
+		events, err = decodeEventsJSON(f)
+	default:
+		return nil, fmt.Errorf("economic calendar: unsupported format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("economic calendar %s: %w", path, err)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return &FileEconomicCalendar{events: events}, nil
+}
+
+// Events returns the loaded events scheduled in [from, to).
+func (c *FileEconomicCalendar) Events(ctx context.Context, from, to time.Time) ([]entity.EconomicEvent, error) {
+	i := sort.Search(len(c.events), func(i int) bool { return !c.events[i].Time.Before(from) })
+	j := sort.Search(len(c.events), func(i int) bool { return !c.events[i].Time.Before(to) })
+	if i >= j {
+		return nil, nil
+	}
+	return append([]entity.EconomicEvent(nil), c.events[i:j]...), nil
+}
+
+func decodeEventsCSV(r io.Reader) ([]entity.EconomicEvent, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range []string{"time", "currency", "impact", "title"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var events []entity.EconomicEvent
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ev, err := newEconomicEvent(rec[col["time"]], rec[col["currency"]], rec[col["impact"]], rec[col["title"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, ev)
+	}
+}
+
+func decodeEventsJSON(r io.Reader) ([]entity.EconomicEvent, error) {
+	var raw []struct {
+		Time     string `json:"time"`
+		Currency string `json:"currency"`
+		Impact   string `json:"impact"`
+		Title    string `json:"title"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	events := make([]entity.EconomicEvent, 0, len(raw))
+	for i, e := range raw {
+		ev, err := newEconomicEvent(e.Time, e.Currency, e.Impact, e.Title)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+func newEconomicEvent(ts, currency, impact, title string) (entity.EconomicEvent, error) {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(ts))
+	if err != nil {
+		return entity.EconomicEvent{}, fmt.Errorf("invalid time %q", ts)
+	}
+	imp, err := entity.ParseEventImpact(impact)
+	if err != nil {
+		return entity.EconomicEvent{}, err
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return entity.EconomicEvent{}, errors.New("missing currency")
+	}
+	return entity.EconomicEvent{Time: t.UTC(), Currency: currency, Impact: imp, Title: strings.TrimSpace(title)}, nil
+}
+
+var _ ports.EconomicCalendarPort = (*FileEconomicCalendar)(nil)
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestFileEconomicCalendar(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return p
+	}
+
+	files := map[string]string{
+		"events.csv": "time,currency,impact,title\n" +
+			"2024-01-10T13:30:00Z,usd,High,CPI\n" +
+			"2024-01-05T13:30:00Z,USD,high,Non-Farm Payrolls\n" +
+			"2024-01-25T13:15:00Z,EUR,medium,ECB Rate Decision\n",
+		"events.json": `[
+			{"time":"2024-01-10T13:30:00Z","currency":"USD","impact":"high","title":"CPI"},
+			{"time":"2024-01-05T13:30:00Z","currency":"USD","impact":"high","title":"Non-Farm Payrolls"},
+			{"time":"2024-01-25T14:15:00+01:00","currency":"EUR","impact":"medium","title":"ECB Rate Decision"}
+		]`,
+	}
+	for name, body := range files {
+		name, body := name, body
+		t.Run(name, func(t *testing.T) {
+			cal, err := NewFileEconomicCalendar(write(name, body))
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			got, err := cal.Events(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 25, 13, 15, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("events: %v", err)
+			}
+			if len(got) != 2 || got[0].Title != "Non-Farm Payrolls" || got[1].Currency != "USD" || got[1].Impact != entity.ImpactHigh {
+				t.Fatalf("unexpected events %+v", got)
+			}
+			all, _ := cal.Events(context.Background(), time.Time{}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			if len(all) != 3 || !all[2].Time.Equal(time.Date(2024, 1, 25, 13, 15, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected events %+v", all)
+			}
+		})
+	}
+
+	for name, body := range map[string]string{
+		"bad_impact.csv": "time,currency,impact,title\n2024-01-10T13:30:00Z,USD,huge,CPI\n",
+		"bad_time.json":  `[{"time":"10/01/2024","currency":"USD","impact":"high","title":"CPI"}]`,
+		"no_column.csv":  "time,currency,title\n",
+		"events.txt":     "",
+	} {
+		if _, err := NewFileEconomicCalendar(write(name, body)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// DefaultFailoverStaleAfter is how long the active provider for a symbol may
+// stay silent before a backup provider takes over.
+const DefaultFailoverStaleAfter = 90 * time.Second
+
+// NamedFeed pairs a market feed with a provider name used in logs.
+type NamedFeed struct {
+	Name string
+	Feed ports.MarketFeedPort
+}
+
+// FailoverFeed implements ports.MarketFeedPort over several providers listed
+// in priority order. All providers stream concurrently; for each symbol the
+// highest-priority provider that is delivering fresh candles is active and
+// candles are de-duplicated by symbol and timestamp so consumers see a single
+// monotonic stream.
+type FailoverFeed struct {
+	feeds      []NamedFeed
+	staleAfter time.Duration
+	logger     *slog.Logger
+	now        func() time.Time
+	health     *feedTracker
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// NewFailoverFeed returns a FailoverFeed over feeds, the first being the
+// primary. A non-positive staleAfter uses DefaultFailoverStaleAfter.
+func NewFailoverFeed(logger *slog.Logger, staleAfter time.Duration, feeds ...NamedFeed) *FailoverFeed {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if staleAfter <= 0 {
+		staleAfter = DefaultFailoverStaleAfter
+	}
+	f := &FailoverFeed{
+		feeds:      feeds,
+		staleAfter: staleAfter,
+		logger:     logger,
+		now:        time.Now,
+		active:     make(map[string]int),
+	}
+	f.health = newFeedTracker(func() time.Time { return f.now() })
+	return f
+}
+
+// Active returns the name of the provider currently active for symbol, or ""
+// if no candle has been emitted for it yet.
+func (f *FailoverFeed) Active(symbol string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	idx, ok := f.active[symbol]
+	if !ok {
+		return ""
+	}
+	return f.feeds[idx].Name
+}
+
+// taggedCandle carries a candle from a provider, or with closed set, notice
+// that the provider's stream ended.
+type taggedCandle struct {
+	provider int
+	candle   ports.Candle
+	closed   bool
+}
+
+// StreamCandles starts every provider and merges their candles. It fails only
+// if no provider could be started.
+func (f *FailoverFeed) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
+	if len(f.feeds) == 0 {
+		return nil, errors.New("no feeds configured")
+	}
+
+	in := make(chan taggedCandle)
+	down := make(map[int]bool)
+	var wg sync.WaitGroup
+	var errs []error
+	for i, nf := range f.feeds {
+		ch, err := nf.Feed.StreamCandles(ctx, symbols)
+		if err != nil {
+			f.logger.ErrorContext(ctx, "start feed", "provider", nf.Name, "error", err)
+			errs = append(errs, err)
+			down[i] = true
+			continue
+		}
+		if r, ok := nf.Feed.(ports.FeedHealthReporter); ok {
+			go f.watchProvider(ctx, r)
+		}
+		wg.Add(1)
+		go func(idx int, ch <-chan ports.Candle) {
+			defer wg.Done()
+			for c := range ch {
+				select {
+				case in <- taggedCandle{provider: idx, candle: c}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			f.logger.WarnContext(ctx, "feed closed", "provider", f.feeds[idx].Name)
+			select {
+			case in <- taggedCandle{provider: idx, closed: true}:
+			case <-ctx.Done():
+			}
+		}(i, ch)
+	}
+	if len(errs) == len(f.feeds) {
+		return nil, errors.Join(errs...)
+	}
+
+	go func() {
+		wg.Wait()
+		close(in)
+	}()
+
+	out := make(chan ports.Candle)
+	f.health.setState(ports.FeedConnecting)
+	go f.merge(ctx, f.now(), down, in, out)
+	return out, nil
+}
+
+// merge forwards candles from in to out. Providers in down are treated as
+// permanently stale, as is any provider whose stream closes.
+func (f *FailoverFeed) merge(ctx context.Context, start time.Time, down map[int]bool, in <-chan taggedCandle, out chan<- ports.Candle) {
+	defer close(out)
+	defer f.health.setState(ports.FeedStopped)
+
+	lastEmitted := make(map[string]time.Time)
+	lastSeen := make(map[string]map[int]time.Time)
+
+	seen := func(sym string, idx int) time.Time {
+		if down[idx] {
+			return time.Time{}
+		}
+		if t, ok := lastSeen[sym][idx]; ok {
+			return t
+		}
+		return start
+	}
+
+	for {
+		var tc taggedCandle
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case tc, ok = <-in:
+			if !ok {
+				return
+			}
+		}
+		if tc.closed {
+			down[tc.provider] = true
+			continue
+		}
+
+		c := tc.candle
+		now := f.now()
+		f.health.update(func(fc *ports.FeedCounters) { fc.Received++ })
+		if lastSeen[c.Symbol] == nil {
+			lastSeen[c.Symbol] = make(map[int]time.Time)
+		}
+		lastSeen[c.Symbol][tc.provider] = now
+
+		if prev, ok := lastEmitted[c.Symbol]; ok && !c.Time.After(prev) {
+			f.health.update(func(fc *ports.FeedCounters) { fc.DroppedDuplicate++ })
+			continue
+		}
+
+		f.mu.Lock()
+		active, known := f.active[c.Symbol]
+		f.mu.Unlock()
+		// Lower-priority providers only take over once the active provider
+		// (the primary before any candle was emitted) has gone stale.
+		if tc.provider > active && now.Sub(seen(c.Symbol, active)) <= f.staleAfter {
+			f.health.update(func(fc *ports.FeedCounters) { fc.DroppedDuplicate++ })
+			continue
+		}
+		if known && tc.provider != active {
+			f.logger.WarnContext(ctx, "feed failover", "symbol", c.Symbol, "from", f.feeds[active].Name, "to", f.feeds[tc.provider].Name)
+			f.health.update(func(fc *ports.FeedCounters) { fc.Failovers++ })
+		}
+		f.mu.Lock()
+		f.active[c.Symbol] = tc.provider
+		f.mu.Unlock()
+
+		if !c.Partial {
+			lastEmitted[c.Symbol] = c.Time
+		}
+		f.health.seen(c.Symbol)
+		f.health.update(func(fc *ports.FeedCounters) { fc.Decoded++ })
+		if f.health.current() != ports.FeedSubscribed {
+			f.refreshState()
+		}
+
+		select {
+		case out <- c:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchProvider refreshes the composite state whenever a provider changes state.
+func (f *FailoverFeed) watchProvider(ctx context.Context, r ports.FeedHealthReporter) {
+	for range r.StateChanges(ctx) {
+		f.refreshState()
+	}
+}
+
+// refreshState derives the composite state: subscribed while any provider is
+// healthy, otherwise the primary reporter's state. Feeds without health
+// reporting count as subscribed once they deliver candles.
+func (f *FailoverFeed) refreshState() {
+	if f.health.current() == ports.FeedStopped {
+		return
+	}
+	var primary ports.FeedState
+	reporters := 0
+	for _, nf := range f.feeds {
+		r, ok := nf.Feed.(ports.FeedHealthReporter)
+		if !ok {
+			continue
+		}
+		h := r.FeedHealth()
+		if h.Healthy() {
+			f.health.setState(ports.FeedSubscribed)
+			return
+		}
+		if reporters == 0 {
+			primary = h.State
+		}
+		reporters++
+	}
+	if reporters == len(f.feeds) && primary != ports.FeedIdle {
+		f.health.setState(primary)
+		return
+	}
+	if f.health.hasMessages() {
+		f.health.setState(ports.FeedSubscribed)
+	}
+}
+
+// FeedHealth returns the composite state and counters. Reconnects are summed
+// across providers that report health.
+func (f *FailoverFeed) FeedHealth() ports.FeedHealth {
+	h := f.health.snapshot()
+	for _, nf := range f.feeds {
+		if r, ok := nf.Feed.(ports.FeedHealthReporter); ok {
+			h.Counters.Reconnects += r.FeedHealth().Counters.Reconnects
+		}
+	}
+	return h
+}
+
+// StateChanges returns a channel receiving composite state transitions until
+// ctx is canceled.
+func (f *FailoverFeed) StateChanges(ctx context.Context) <-chan ports.FeedStateChange {
+	return f.health.watch(ctx)
+}
+
+var (
+	_ ports.MarketFeedAdapter  = (*FailoverFeed)(nil)
+	_ ports.FeedHealthReporter = (*FailoverFeed)(nil)
+)
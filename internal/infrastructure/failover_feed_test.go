@@ -0,0 +1,211 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+type chanFeed struct {
+	ch  chan ports.Candle
+	err error
+}
+
+func (f *chanFeed) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.ch, nil
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestFailoverFeed(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	bar := func(i int) ports.Candle {
+		return ports.Candle{Symbol: "EURUSD", Time: base.Add(time.Duration(i) * time.Minute), Open: 1, High: 1, Low: 1, Close: 1}
+	}
+
+	primary := &chanFeed{ch: make(chan ports.Candle)}
+	backup := &chanFeed{ch: make(chan ports.Candle)}
+	clock := &fakeClock{now: base}
+
+	f := NewFailoverFeed(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Minute,
+		NamedFeed{Name: "primary", Feed: primary},
+		NamedFeed{Name: "backup", Feed: backup},
+	)
+	f.now = clock.Now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, err := f.StreamCandles(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	send := func(ch chan ports.Candle, c ports.Candle) {
+		t.Helper()
+		select {
+		case ch <- c:
+		case <-time.After(time.Second):
+			t.Fatalf("timeout sending candle")
+		}
+	}
+	expect := func(want ports.Candle, provider string) {
+		t.Helper()
+		select {
+		case got := <-out:
+			if !got.Time.Equal(want.Time) {
+				t.Fatalf("expected candle at %s, got %s", want.Time, got.Time)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for candle at %s", want.Time)
+		}
+		if got := f.Active("EURUSD"); got != provider {
+			t.Fatalf("expected active provider %s, got %s", provider, got)
+		}
+	}
+
+	// Both providers deliver bar 0; the backup copy is dropped.
+	send(primary.ch, bar(0))
+	expect(bar(0), "primary")
+	send(backup.ch, bar(0))
+
+	// Backup is ahead but the primary is still fresh.
+	clock.Advance(30 * time.Second)
+	send(backup.ch, bar(1))
+	send(primary.ch, bar(1))
+	expect(bar(1), "primary")
+
+	// Primary goes silent; backup takes over once it is stale.
+	clock.Advance(2 * time.Minute)
+	send(backup.ch, bar(2))
+	expect(bar(2), "backup")
+	send(backup.ch, bar(3))
+	expect(bar(3), "backup")
+
+	// Primary recovers with an old bar (dropped) and then a new one.
+	send(primary.ch, bar(3))
+	send(primary.ch, bar(4))
+	expect(bar(4), "primary")
+
+	h := f.FeedHealth()
+	want := ports.FeedCounters{Received: 8, Decoded: 5, DroppedDuplicate: 3, Failovers: 2}
+	if h.Counters != want {
+		t.Fatalf("expected counters %+v, got %+v", want, h.Counters)
+	}
+	if h.State != ports.FeedSubscribed {
+		t.Fatalf("expected subscribed state, got %s", h.State)
+	}
+
+	cancel()
+	for range out {
+	}
+	if h := f.FeedHealth(); h.State != ports.FeedStopped {
+		t.Fatalf("expected stopped state, got %s", h.State)
+	}
+}
+
+func TestFailoverFeed_StartErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("no feeds", func(t *testing.T) {
+		if _, err := NewFailoverFeed(logger, 0).StreamCandles(context.Background(), []string{"EURUSD"}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("all feeds fail", func(t *testing.T) {
+		f := NewFailoverFeed(logger, 0,
+			NamedFeed{Name: "a", Feed: &chanFeed{err: errors.New("a down")}},
+			NamedFeed{Name: "b", Feed: &chanFeed{err: errors.New("b down")}},
+		)
+		if _, err := f.StreamCandles(context.Background(), []string{"EURUSD"}); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("backup only", func(t *testing.T) {
+		backup := &chanFeed{ch: make(chan ports.Candle, 1)}
+		f := NewFailoverFeed(logger, time.Minute,
+			NamedFeed{Name: "a", Feed: &chanFeed{err: errors.New("a down")}},
+			NamedFeed{Name: "b", Feed: backup},
+		)
+		clock := &fakeClock{now: time.Now()}
+		f.now = clock.Now
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		out, err := f.StreamCandles(ctx, []string{"EURUSD"})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		backup.ch <- ports.Candle{Symbol: "EURUSD", Time: clock.Now()}
+		close(backup.ch)
+
+		select {
+		case _, ok := <-out:
+			if !ok {
+				t.Fatalf("channel closed early")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for candle")
+		}
+		if _, ok := <-out; ok {
+			t.Fatalf("expected closed channel after all feeds closed")
+		}
+	})
+}
+
+func TestFailoverFeed_Partial(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	primary := &chanFeed{ch: make(chan ports.Candle, 4)}
+	f := NewFailoverFeed(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Minute,
+		NamedFeed{Name: "primary", Feed: primary},
+	)
+	f.now = func() time.Time { return base }
+
+	bar := ports.Candle{Symbol: "EURUSD", Time: base, Open: 1, High: 1, Low: 1, Close: 1}
+	forming := bar
+	forming.Partial = true
+	for _, c := range []ports.Candle{forming, forming, bar, forming} {
+		primary.ch <- c
+	}
+	close(primary.ch)
+
+	out, err := f.StreamCandles(context.Background(), []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var got []bool
+	for c := range out {
+		got = append(got, c.Partial)
+	}
+	if len(got) != 3 || !got[0] || !got[1] || got[2] {
+		t.Fatalf("expected two partial updates then the closed bar, got %v", got)
+	}
+	if d := f.FeedHealth().Counters.DroppedDuplicate; d != 1 {
+		t.Fatalf("expected the partial after close to be dropped, got %d", d)
+	}
+}
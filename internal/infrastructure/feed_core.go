@@ -0,0 +1,179 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// errSessionRejected marks provider responses, such as failed
+// authentication, after which the adapter backs off before reconnecting.
+var errSessionRejected = errors.New("session rejected")
+
+// sessionEnd tells the connection loop how a streaming session finished.
+type sessionEnd int
+
+const (
+	sessionStop sessionEnd = iota
+	sessionReconnect
+	sessionRejected
+)
+
+// feedCore holds the retry, health and candle filtering state shared by all
+// market feed adapters regardless of transport.
+type feedCore struct {
+	provider     string
+	logger       *slog.Logger
+	backoff      ports.BackoffStrategy
+	health       *feedTracker
+	metrics      ports.MetricsRecorder
+	now          func() time.Time
+	readDeadline time.Duration
+	staleAfter   time.Duration
+	maxCandleAge time.Duration
+	// instruments, when set, supplies provider codes for subscriptions and
+	// the canonical symbols emitted on candles and ticks.
+	instruments *entity.InstrumentRegistry
+}
+
+func newFeedCore(provider string, logger *slog.Logger, backoff ports.BackoffStrategy, metrics ports.MetricsRecorder) *feedCore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if backoff == nil {
+		backoff = ExponentialBackoff{Base: time.Second, Max: 30 * time.Second}
+	}
+	if metrics == nil {
+		metrics = ports.NopMetrics{}
+	}
+	f := &feedCore{
+		provider: provider,
+		logger:   logger,
+		backoff:  backoff,
+		metrics:  metrics,
+		now:      time.Now,
+	}
+	f.health = newFeedTracker(func() time.Time { return f.now() })
+	return f
+}
+
+// FeedHealth returns a snapshot of the adapter's connection state, message
+// counters and the last message time per symbol.
+func (f *feedCore) FeedHealth() ports.FeedHealth {
+	return f.health.snapshot()
+}
+
+// StateChanges returns a channel receiving connection state transitions until
+// ctx is canceled.
+func (f *feedCore) StateChanges(ctx context.Context) <-chan ports.FeedStateChange {
+	return f.health.watch(ctx)
+}
+
+// retrier tracks connection attempts for one streaming run.
+type retrier struct {
+	retries int
+	started bool
+}
+
+// connecting records a connection attempt, counting every attempt after the
+// first as a reconnect.
+func (f *feedCore) connecting(ctx context.Context, r *retrier) {
+	if r.started {
+		f.health.update(func(c *ports.FeedCounters) { c.Reconnects++ })
+		f.metrics.FeedReconnect(f.provider)
+	}
+	r.started = true
+	f.health.setState(ports.FeedConnecting)
+	f.logger.InfoContext(ctx, "connecting to market feed", "provider", f.provider)
+}
+
+// wait sleeps for the next backoff interval. It returns false if ctx is done.
+func (f *feedCore) wait(ctx context.Context, r *retrier) bool {
+	f.health.setState(ports.FeedBackingOff)
+	d := f.backoff.Next(r.retries)
+	r.retries++
+	return sleep(ctx, d)
+}
+
+// accept applies the validity, age and duplicate filters to c. Partial
+// updates pass only while their bar is newer than the last closed one and do
+// not advance lastTS.
+func (f *feedCore) accept(c ports.Candle, lastTS map[string]time.Time) bool {
+	if c.Time.IsZero() || c.Symbol == "" || (c.Open == 0 && c.Close == 0 && c.High == 0 && c.Low == 0) {
+		f.health.update(func(fc *ports.FeedCounters) { fc.DroppedInvalid++ })
+		return false
+	}
+	f.health.seen(c.Symbol)
+	if f.now().Sub(c.Time) > f.maxCandleAge {
+		f.health.update(func(fc *ports.FeedCounters) { fc.DroppedStale++ })
+		return false
+	}
+	prev, ok := lastTS[c.Symbol]
+	if ok && (prev.Equal(c.Time) || (c.Partial && c.Time.Before(prev))) {
+		f.health.update(func(fc *ports.FeedCounters) { fc.DroppedDuplicate++ })
+		return false
+	}
+	if !c.Partial {
+		lastTS[c.Symbol] = c.Time
+	}
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// providerCode returns the code registered for symbol with this provider, or
+// fallback(symbol) when the registry has none.
+func (f *feedCore) providerCode(symbol string, fallback func(string) (string, error)) (string, error) {
+	if inst, ok := f.instruments.Lookup(symbol); ok {
+		if code, ok := inst.Code(f.provider); ok {
+			return code, nil
+		}
+	}
+	return fallback(symbol)
+}
+
+// normalizeSymbol converts provider symbols such as "EUR_USD", "eur/usd" or
+// "BTC-USD" into the upper-case, separator-free form used in ports.Candle.
+func normalizeSymbol(s string) string {
+	return strings.ToUpper(strings.NewReplacer("/", "", "_", "", "-", "", ":", "").Replace(s))
+}
+
+// splitPair splits a currency pair into base and quote. Symbols containing a
+// separator are split on it; six-letter symbols are split in half; otherwise
+// a known quote currency suffix is used.
+func splitPair(s string) (base, quote string, ok bool) {
+	s = strings.ToUpper(s)
+	for _, sep := range []string{"/", "_", "-"} {
+		if b, q, found := strings.Cut(s, sep); found {
+			return b, q, b != "" && q != ""
+		}
+	}
+	for _, q := range []string{"USDT", "USDC"} {
+		if strings.HasSuffix(s, q) && len(s) > len(q) {
+			return strings.TrimSuffix(s, q), q, true
+		}
+	}
+	if len(s) == 6 {
+		return s[:3], s[3:], true
+	}
+	for _, q := range []string{"USD", "EUR", "GBP", "JPY", "BTC", "ETH"} {
+		if strings.HasSuffix(s, q) && len(s) > len(q) {
+			return strings.TrimSuffix(s, q), q, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// feedTracker records connection state, counters and per-symbol message times
+// for a market feed adapter. It is safe for concurrent use.
+type feedTracker struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	state    ports.FeedState
+	since    time.Time
+	counters ports.FeedCounters
+	last     map[string]time.Time
+	watchers map[chan ports.FeedStateChange]struct{}
+}
+
+func newFeedTracker(now func() time.Time) *feedTracker {
+	return &feedTracker{
+		now:      now,
+		state:    ports.FeedIdle,
+		since:    now(),
+		last:     make(map[string]time.Time),
+		watchers: make(map[chan ports.FeedStateChange]struct{}),
+	}
+}
+
+// setState transitions to s and notifies watchers. Repeated states are ignored.
+func (t *feedTracker) setState(s ports.FeedState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state == s {
+		return
+	}
+	change := ports.FeedStateChange{From: t.state, To: s, At: t.now()}
+	t.state = s
+	t.since = change.At
+	for ch := range t.watchers {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+// update applies fn to the counters under lock.
+func (t *feedTracker) update(fn func(c *ports.FeedCounters)) {
+	t.mu.Lock()
+	fn(&t.counters)
+	t.mu.Unlock()
+}
+
+// seen records that a message for symbol arrived.
+func (t *feedTracker) seen(symbol string) {
+	t.mu.Lock()
+	t.last[symbol] = t.now()
+	t.mu.Unlock()
+}
+
+func (t *feedTracker) current() ports.FeedState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+func (t *feedTracker) hasMessages() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.last) > 0
+}
+
+func (t *feedTracker) snapshot() ports.FeedHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := make(map[string]time.Time, len(t.last))
+	for k, v := range t.last {
+		last[k] = v
+	}
+	return ports.FeedHealth{
+		State:       t.state,
+		Since:       t.since,
+		Counters:    t.counters,
+		LastMessage: last,
+	}
+}
+
+func (t *feedTracker) watch(ctx context.Context) <-chan ports.FeedStateChange {
+	ch := make(chan ports.FeedStateChange, 16)
+	t.mu.Lock()
+	t.watchers[ch] = struct{}{}
+	t.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		t.mu.Lock()
+		delete(t.watchers, ch)
+		close(ch)
+		t.mu.Unlock()
+	}()
+	return ch
+}
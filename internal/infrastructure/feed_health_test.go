@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestFeedTracker_StateChanges(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tr := newFeedTracker(func() time.Time { return now })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := tr.watch(ctx)
+
+	tr.setState(ports.FeedConnecting)
+	tr.setState(ports.FeedConnecting)
+	tr.setState(ports.FeedSubscribed)
+
+	want := []ports.FeedStateChange{
+		{From: ports.FeedIdle, To: ports.FeedConnecting, At: now},
+		{From: ports.FeedConnecting, To: ports.FeedSubscribed, At: now},
+	}
+	for i, w := range want {
+		select {
+		case got := <-ch:
+			if got != w {
+				t.Fatalf("change %d: expected %+v, got %+v", i, w, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for change %d", i)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatalf("expected closed channel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for close")
+	}
+
+	h := tr.snapshot()
+	if h.State != ports.FeedSubscribed || !h.Healthy() {
+		t.Fatalf("expected subscribed, got %s", h.State)
+	}
+}
+
+func TestFeedTracker_Snapshot(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tr := newFeedTracker(func() time.Time { return now })
+
+	tr.update(func(c *ports.FeedCounters) { c.Received += 2 })
+	tr.seen("EURUSD")
+
+	h := tr.snapshot()
+	h.LastMessage["GBPUSD"] = now
+
+	if h.Counters.Received != 2 {
+		t.Fatalf("expected 2 received, got %d", h.Counters.Received)
+	}
+	if got := tr.snapshot().LastMessage; len(got) != 1 || !got["EURUSD"].Equal(now) {
+		t.Fatalf("unexpected last message map %v", got)
+	}
+}
@@ -4,28 +4,100 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
+	"path"
 	"strings"
-	"sync"
 	"time"
 
-	"log/slog"
-
 	"github.com/gorilla/websocket"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 )
 
+// FinageAssetClass selects which Finage aggregate feed to stream.
+type FinageAssetClass string
+
+// Supported Finage asset classes.
+const (
+	FinageForex  FinageAssetClass = "forex"
+	FinageCrypto FinageAssetClass = "crypto"
+	FinageStock  FinageAssetClass = "stock"
+	FinageIndex  FinageAssetClass = "index"
+)
+
+// Default FinageAdapter settings.
+const (
+	DefaultFinageBaseURL      = "wss://api.finage.co.uk"
+	DefaultFinageAPIKeyEnv    = "FINAGE_API_KEY"
+	DefaultFinageReadDeadline = 15 * time.Second
+	DefaultFinageStaleAfter   = 30 * time.Second
+	DefaultFinageMaxCandleAge = 5 * time.Second
+)
+
+// FinageOptions configures a FinageAdapter. Zero values fall back to the
+// defaults above.
+type FinageOptions struct {
+	// BaseURL is the scheme and host of the WebSocket API, e.g.
+	// "wss://api.finage.co.uk" or "ws://127.0.0.1:8080" for a local stand-in.
+	BaseURL string
+	// AssetClass selects the aggregate feed. Defaults to FinageForex.
+	AssetClass FinageAssetClass
+	// APIKey is used as-is when set.
+	APIKey string
+	// APIKeyEnv names the environment variable read when APIKey is empty.
+	APIKeyEnv string
+	// ReadDeadline bounds how long a single read may block.
+	ReadDeadline time.Duration
+	// StaleAfter forces a reconnect when no candle arrives within the window.
+	StaleAfter time.Duration
+	// MaxCandleAge drops candles whose timestamp is older than this.
+	MaxCandleAge time.Duration
+	// Metrics records reconnects. Defaults to ports.NopMetrics.
+	Metrics ports.MetricsRecorder
+	// Instruments maps symbols to provider codes and canonical names. When
+	// nil, the adapter's built-in symbol conversion is used.
+	Instruments *entity.InstrumentRegistry
+}
+
+func (o FinageOptions) withDefaults() FinageOptions {
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultFinageBaseURL
+	}
+	if o.AssetClass == "" {
+		o.AssetClass = FinageForex
+	}
+	if o.APIKeyEnv == "" {
+		o.APIKeyEnv = DefaultFinageAPIKeyEnv
+	}
+	if o.APIKey == "" {
+		o.APIKey = os.Getenv(o.APIKeyEnv)
+	}
+	if o.ReadDeadline <= 0 {
+		o.ReadDeadline = DefaultFinageReadDeadline
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = DefaultFinageStaleAfter
+	}
+	if o.MaxCandleAge <= 0 {
+		o.MaxCandleAge = DefaultFinageMaxCandleAge
+	}
+	if o.Metrics == nil {
+		o.Metrics = ports.NopMetrics{}
+	}
+	return o
+}
+
 // FinageAdapter implements the MarketFeedAdapter using the Finage WebSocket API.
 type FinageAdapter struct {
+	*wsFeed
 	apiKey     string
+	apiKeyEnv  string
 	baseURL    string
-	logger     *slog.Logger
-	dialer     *websocket.Dialer
-	now        func() time.Time
-	staleAfter time.Duration
-	backoff    ports.BackoffStrategy
+	assetClass FinageAssetClass
 }
 
 // ExponentialBackoff implements a simple exponential backoff strategy.
@@ -48,21 +120,54 @@ func (e ExponentialBackoff) Next(retry int) time.Duration {
 // Optionally a custom websocket.Dialer can be supplied; otherwise the
 // websocket.DefaultDialer is used.
 func NewFinageAdapter(logger *slog.Logger, dialer *websocket.Dialer, backoff ports.BackoffStrategy) *FinageAdapter {
-	if dialer == nil {
-		dialer = websocket.DefaultDialer
+	return NewFinageAdapterWithOptions(logger, dialer, backoff, FinageOptions{})
+}
+
+// NewFinageAdapterWithOptions initializes a FinageAdapter using opts for the
+// endpoint, asset class, API key source and timing thresholds.
+func NewFinageAdapterWithOptions(logger *slog.Logger, dialer *websocket.Dialer, backoff ports.BackoffStrategy, opts FinageOptions) *FinageAdapter {
+	opts = opts.withDefaults()
+	feed := newWSFeed("finage", logger, dialer, backoff, opts.Metrics)
+	feed.instruments = opts.Instruments
+	feed.readDeadline = opts.ReadDeadline
+	feed.staleAfter = opts.StaleAfter
+	feed.maxCandleAge = opts.MaxCandleAge
+	return &FinageAdapter{
+		wsFeed:     feed,
+		apiKey:     opts.APIKey,
+		apiKeyEnv:  opts.APIKeyEnv,
+		baseURL:    opts.BaseURL,
+		assetClass: opts.AssetClass,
 	}
-	if backoff == nil {
-		backoff = ExponentialBackoff{Base: time.Second, Max: 30 * time.Second}
+}
+
+// endpoint builds the aggregate feed URL for the configured base URL and
+// asset class, e.g. wss://api.finage.co.uk/agg/forex?apikey=KEY.
+func (a *FinageAdapter) endpoint() (string, error) {
+	switch a.assetClass {
+	case FinageForex, FinageCrypto, FinageStock, FinageIndex:
+	default:
+		return "", fmt.Errorf("unsupported asset class %q", a.assetClass)
 	}
-	return &FinageAdapter{
-		apiKey:     os.Getenv("FINAGE_API_KEY"),
-		baseURL:    "wss://api.finage.co.uk/agg/forex",
-		logger:     logger,
-		dialer:     dialer,
-		now:        time.Now,
-		staleAfter: 30 * time.Second,
-		backoff:    backoff,
+
+	u, err := url.Parse(a.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported base url scheme %q", u.Scheme)
 	}
+	if u.Host == "" {
+		return "", fmt.Errorf("base url %q has no host", a.baseURL)
+	}
+
+	u.Path = path.Join("/", u.Path, "agg", string(a.assetClass))
+	q := u.Query()
+	q.Set("apikey", a.apiKey)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
 
 // finageCandle models the JSON payload from Finage.
@@ -81,138 +186,56 @@ type finageCandle struct {
 // fails after retries.
 func (a *FinageAdapter) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
 	if a.apiKey == "" {
-		return nil, errors.New("missing FINAGE_API_KEY")
+		return nil, fmt.Errorf("missing %s", a.apiKeyEnv)
 	}
 	if len(symbols) == 0 {
 		return nil, errors.New("no symbols provided")
 	}
+	endpoint, err := a.endpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.InfoContext(ctx, "streaming from Finage", "asset_class", a.assetClass, "base_url", a.baseURL)
 
 	out := make(chan ports.Candle)
-	go a.run(ctx, symbols, out)
+	go a.run(ctx, wsProtocol{
+		endpoint:  endpoint,
+		subscribe: func(conn *websocket.Conn) error { return a.subscribe(conn, symbols) },
+		decode:    decodeFinage,
+	}, out)
 	return out, nil
 }
 
-func (a *FinageAdapter) run(ctx context.Context, symbols []string, out chan ports.Candle) {
-	defer close(out)
-
-	lastTS := make(map[string]time.Time)
-	var mu sync.Mutex
-
-	retries := 0
-
-	for {
-		if ctx.Err() != nil {
-			return
-		}
-
-		u := url.URL{Scheme: "wss", Host: "api.finage.co.uk", Path: "/agg/forex", RawQuery: "apikey=" + url.QueryEscape(a.apiKey)}
-		a.logger.With("url", u.String()).InfoContext(ctx, "connecting to Finage")
-
-		conn, _, err := a.dialer.DialContext(ctx, u.String(), nil)
-		if err != nil {
-			a.logger.ErrorContext(ctx, "connection failed", "error", err)
-			wait := a.backoff.Next(retries)
-			retries++
-			if !sleep(ctx, wait) {
-				return
-			}
-			continue
-		}
-
-		retries = 0
-		if err := a.subscribe(conn, symbols); err != nil {
-			a.logger.ErrorContext(ctx, "subscription failed", "error", err)
-			conn.Close()
-			wait := a.backoff.Next(retries)
-			retries++
-			if !sleep(ctx, wait) {
-				return
-			}
-			continue
-		}
-
-		lastRecv := a.now()
-
-		for {
-			if ctx.Err() != nil {
-				conn.Close()
-				return
-			}
-
-			conn.SetReadDeadline(time.Now().Add(15 * time.Second))
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				a.logger.ErrorContext(ctx, "read error", "error", err)
-				conn.Close()
-				break
-			}
-
-			var fc finageCandle
-			if err := json.Unmarshal(message, &fc); err != nil {
-				a.logger.ErrorContext(ctx, "decode error", "error", err)
-				continue
-			}
-
-			ts := time.Unix(0, fc.Timestamp*int64(time.Millisecond))
-			if ts.IsZero() || fc.Symbol == "" || (fc.Open == 0 && fc.Close == 0 && fc.High == 0 && fc.Low == 0) {
-				continue
-			}
-			if time.Since(ts) > 5*time.Second {
-				continue
-			}
-
-			mu.Lock()
-			if prev, ok := lastTS[fc.Symbol]; ok && prev.Equal(ts) {
-				mu.Unlock()
-				continue
-			}
-			lastTS[fc.Symbol] = ts
-			mu.Unlock()
-
-			c := ports.Candle{
-				Symbol: fc.Symbol,
-				Time:   ts,
-				Open:   fc.Open,
-				High:   fc.High,
-				Low:    fc.Low,
-				Close:  fc.Close,
-				Volume: fc.Volume,
-			}
-
-			select {
-			case out <- c:
-			case <-ctx.Done():
-				conn.Close()
-				return
-			}
-
-			if a.now().Sub(lastRecv) > a.staleAfter {
-				a.logger.WarnContext(ctx, "stale stream detected, reconnecting")
-				conn.Close()
-				break
-			}
-
-			lastRecv = a.now()
-		}
-
+func decodeFinage(msg []byte) ([]ports.Candle, error) {
+	var fc finageCandle
+	if err := json.Unmarshal(msg, &fc); err != nil {
+		return nil, err
 	}
+	return []ports.Candle{{
+		Symbol: fc.Symbol,
+		Time:   time.Unix(0, fc.Timestamp*int64(time.Millisecond)),
+		Open:   fc.Open,
+		High:   fc.High,
+		Low:    fc.Low,
+		Close:  fc.Close,
+		Volume: fc.Volume,
+	}}, nil
 }
 
-func sleep(ctx context.Context, d time.Duration) bool {
-	t := time.NewTimer(d)
-	defer t.Stop()
-	select {
-	case <-ctx.Done():
-		return false
-	case <-t.C:
-		return true
-	}
-}
+var (
+	_ ports.MarketFeedAdapter  = (*FinageAdapter)(nil)
+	_ ports.FeedHealthReporter = (*FinageAdapter)(nil)
+)
 
 func (a *FinageAdapter) subscribe(conn *websocket.Conn, symbols []string) error {
+	codes := make([]string, len(symbols))
+	for i, s := range symbols {
+		codes[i], _ = a.providerCode(s, func(s string) (string, error) { return s, nil })
+	}
 	msg := map[string]any{
 		"action":  "subscribe",
-		"symbols": strings.Join(symbols, ","),
+		"symbols": strings.Join(codes, ","),
 	}
 	return conn.WriteJSON(msg)
 }
@@ -14,6 +14,9 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
 )
 
 func TestNewFinageAdapter_Dialer(t *testing.T) {
@@ -371,3 +374,259 @@ func TestFinageAdapter_StreamCandles(t *testing.T) {
 		}
 	})
 }
+
+func TestFinageAdapter_Endpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    FinageOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			opts: FinageOptions{APIKey: "k"},
+			want: "wss://api.finage.co.uk/agg/forex?apikey=k",
+		},
+		{
+			name: "crypto",
+			opts: FinageOptions{APIKey: "k", AssetClass: FinageCrypto},
+			want: "wss://api.finage.co.uk/agg/crypto?apikey=k",
+		},
+		{
+			name: "local base url with prefix",
+			opts: FinageOptions{APIKey: "a&b", BaseURL: "ws://127.0.0.1:9000/feed/", AssetClass: FinageIndex},
+			want: "ws://127.0.0.1:9000/feed/agg/index?apikey=a%26b",
+		},
+		{
+			name:    "unknown asset class",
+			opts:    FinageOptions{APIKey: "k", AssetClass: "bonds"},
+			wantErr: true,
+		},
+		{
+			name:    "http scheme",
+			opts:    FinageOptions{APIKey: "k", BaseURL: "https://api.finage.co.uk"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := NewFinageAdapterWithOptions(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, tt.opts)
+			got, err := a.endpoint()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("endpoint: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFinageAdapter_Options(t *testing.T) {
+	t.Setenv("CUSTOM_FINAGE_KEY", "from-env")
+
+	a := NewFinageAdapterWithOptions(nil, nil, nil, FinageOptions{APIKeyEnv: "CUSTOM_FINAGE_KEY"})
+	if a.apiKey != "from-env" {
+		t.Fatalf("expected key from env, got %q", a.apiKey)
+	}
+	if a.readDeadline != DefaultFinageReadDeadline || a.staleAfter != DefaultFinageStaleAfter || a.maxCandleAge != DefaultFinageMaxCandleAge {
+		t.Fatalf("expected default timings, got %v %v %v", a.readDeadline, a.staleAfter, a.maxCandleAge)
+	}
+
+	t.Setenv("CUSTOM_FINAGE_KEY", "")
+	a = NewFinageAdapterWithOptions(nil, nil, nil, FinageOptions{APIKeyEnv: "CUSTOM_FINAGE_KEY"})
+	if _, err := a.StreamCandles(context.Background(), []string{"EURUSD"}); err == nil || err.Error() != "missing CUSTOM_FINAGE_KEY" {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+}
+
+func TestFinageAdapter_LocalServer(t *testing.T) {
+	now := time.Now()
+
+	var (
+		mu        sync.Mutex
+		path, key string
+	)
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		path = r.URL.Path
+		key = r.URL.Query().Get("apikey")
+		mu.Unlock()
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade error: %v", err)
+			return
+		}
+		defer c.Close()
+		c.ReadMessage()
+		_ = c.WriteMessage(websocket.TextMessage, candleMsg("BTCUSD", now))
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	a := NewFinageAdapterWithOptions(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, FinageOptions{
+		BaseURL:    "ws://" + srv.Listener.Addr().String(),
+		AssetClass: FinageCrypto,
+		APIKey:     "local",
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"BTCUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	select {
+	case c, ok := <-ch:
+		if !ok {
+			t.Fatalf("channel closed early")
+		}
+		if c.Symbol != "BTCUSD" {
+			t.Fatalf("unexpected symbol %s", c.Symbol)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for candle")
+	}
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/agg/crypto" || key != "local" {
+		t.Fatalf("unexpected request path %q key %q", path, key)
+	}
+}
+
+func TestFinageAdapter_FeedHealth(t *testing.T) {
+	t.Setenv("FINAGE_API_KEY", "test")
+
+	now := time.Now()
+	handler := func(c *websocket.Conn) {
+		defer c.Close()
+		c.ReadMessage()
+		msgs := [][]byte{
+			[]byte("not json"),
+			candleMsg("EURUSD", now.Add(-time.Minute)),
+			candleMsg("EURUSD", now),
+			candleMsg("EURUSD", now),
+			candleMsg("", now),
+			candleMsg("EURUSD", now.Add(time.Second)),
+		}
+		for _, m := range msgs {
+			if err := c.WriteMessage(websocket.TextMessage, m); err != nil {
+				t.Errorf("write message: %v", err)
+				return
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	srv, dialer := newWSServerNoFail(t, handler)
+	defer srv.Close()
+
+	a := NewFinageAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), dialer, &mockBackoff{})
+	if h := a.FeedHealth(); h.State != ports.FeedIdle {
+		t.Fatalf("expected idle state, got %s", h.State)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := a.StateChanges(ctx)
+
+	ch, err := a.StreamCandles(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for candle %d", i)
+		case <-ch:
+		}
+	}
+
+	h := a.FeedHealth()
+	want := ports.FeedCounters{Received: 6, Decoded: 5, DroppedStale: 1, DroppedDuplicate: 1, DroppedInvalid: 2}
+	if h.Counters != want {
+		t.Fatalf("expected counters %+v, got %+v", want, h.Counters)
+	}
+	if h.State != ports.FeedSubscribed {
+		t.Fatalf("expected subscribed, got %s", h.State)
+	}
+	if _, ok := h.LastMessage["EURUSD"]; !ok {
+		t.Fatalf("expected last message time for EURUSD")
+	}
+
+	var got []ports.FeedState
+	for len(got) < 2 {
+		select {
+		case c := <-changes:
+			got = append(got, c.To)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for state changes, got %v", got)
+		}
+	}
+	if got[0] != ports.FeedConnecting || got[1] != ports.FeedSubscribed {
+		t.Fatalf("unexpected state changes %v", got)
+	}
+
+	cancel()
+	for range ch {
+	}
+	if h := a.FeedHealth(); h.State != ports.FeedStopped {
+		t.Fatalf("expected stopped state, got %s", h.State)
+	}
+}
+
+func TestFinageAdapter_ReconnectMetrics(t *testing.T) {
+	now := time.Now()
+	handler := func(c *websocket.Conn) {
+		defer c.Close()
+		c.ReadMessage()
+		_ = c.WriteMessage(websocket.TextMessage, candleMsg("EURUSD", now))
+	}
+
+	srv, dialer := newFailDialServer(t, handler)
+	defer srv.Close()
+
+	metrics := testutils.NewMockMetrics()
+	a := NewFinageAdapterWithOptions(slog.New(slog.NewTextHandler(io.Discard, nil)), dialer, &mockBackoff{}, FinageOptions{APIKey: "test", Metrics: metrics})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for candle")
+	}
+	cancel()
+	for range ch {
+	}
+
+	got := a.FeedHealth().Counters.Reconnects
+	if got == 0 {
+		t.Fatalf("expected reconnects in health counters")
+	}
+	if metrics.Reconnects["finage"] != int(got) {
+		t.Fatalf("expected %d reconnect metrics, got %d", got, metrics.Reconnects["finage"])
+	}
+}
@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// instrumentFile is the JSON layout read by LoadInstruments.
+type instrumentFile struct {
+	Instruments []struct {
+		Symbol     string            `json:"symbol"`
+		AssetClass string            `json:"asset_class"`
+		PipSize    float64           `json:"pip_size"`
+		Precision  int               `json:"precision"`
+		Codes      map[string]string `json:"codes"`
+		Sessions   []struct {
+			Name  string `json:"name"`
+			Open  string `json:"open"`
+			Close string `json:"close"`
+		} `json:"sessions"`
+	} `json:"instruments"`
+	Holidays []struct {
+		Date    string   `json:"date"`
+		Name    string   `json:"name"`
+		Symbols []string `json:"symbols"`
+	} `json:"holidays"`
+}
+
+// LoadInstruments reads an instrument registry from a JSON file. Session
+// times are "HH:MM" in UTC.
+func LoadInstruments(path string) (*entity.InstrumentRegistry, error) {
+	f, err := readInstrumentFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.registry()
+}
+
+// LoadSessionCalendar reads instruments and the optional "holidays" list
+// (dates as YYYY-MM-DD) from a JSON file and builds a session calendar.
+func LoadSessionCalendar(path string) (*entity.SessionCalendar, error) {
+	f, err := readInstrumentFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := f.registry()
+	if err != nil {
+		return nil, err
+	}
+	holidays := make([]entity.Holiday, 0, len(f.Holidays))
+	for _, h := range f.Holidays {
+		d, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, fmt.Errorf("holiday %s: invalid date %q", h.Name, h.Date)
+		}
+		holidays = append(holidays, entity.Holiday{Date: d, Name: h.Name, Symbols: h.Symbols})
+	}
+	return entity.NewSessionCalendar(reg, holidays...), nil
+}
+
+func readInstrumentFile(path string) (instrumentFile, error) {
+	var f instrumentFile
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("read instruments: %w", err)
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return f, fmt.Errorf("decode instruments: %w", err)
+	}
+	return f, nil
+}
+
+func (f instrumentFile) registry() (*entity.InstrumentRegistry, error) {
+	instruments := make([]entity.Instrument, 0, len(f.Instruments))
+	for _, in := range f.Instruments {
+		inst := entity.Instrument{
+			Symbol:     in.Symbol,
+			AssetClass: entity.AssetClass(in.AssetClass),
+			PipSize:    in.PipSize,
+			Precision:  in.Precision,
+			Codes:      in.Codes,
+		}
+		for _, s := range in.Sessions {
+			open, err := parseTimeOfDay(s.Open)
+			if err != nil {
+				return nil, fmt.Errorf("instrument %s session %s: %w", in.Symbol, s.Name, err)
+			}
+			closeAt, err := parseTimeOfDay(s.Close)
+			if err != nil {
+				return nil, fmt.Errorf("instrument %s session %s: %w", in.Symbol, s.Name, err)
+			}
+			inst.Sessions = append(inst.Sessions, entity.TradingSession{Name: s.Name, Open: open, Close: closeAt})
+		}
+		instruments = append(instruments, inst)
+	}
+	return entity.NewInstrumentRegistry(instruments...)
+}
+
+// parseTimeOfDay converts "HH:MM" into an offset from midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadInstruments(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return p
+	}
+
+	good := write("good.json", `{"instruments":[{"symbol":"EUR/USD","asset_class":"forex","pip_size":0.0001,"precision":5,
+		"codes":{"oanda":"EUR_USD"},"sessions":[{"name":"London","open":"07:00","close":"16:00"}]}]}`)
+	reg, err := LoadInstruments(good)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	inst, ok := reg.Lookup("eurusd")
+	if !ok || inst.Precision != 5 || len(inst.Sessions) != 1 || inst.Sessions[0].Open != 7*time.Hour {
+		t.Fatalf("unexpected instrument %+v", inst)
+	}
+
+	for name, body := range map[string]string{
+		"bad_json.json":    `{`,
+		"bad_session.json": `{"instruments":[{"symbol":"EUR/USD","pip_size":0.0001,"sessions":[{"name":"x","open":"7am","close":"16:00"}]}]}`,
+		"bad_pip.json":     `{"instruments":[{"symbol":"EUR/USD"}]}`,
+	} {
+		if _, err := LoadInstruments(write(name, body)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if _, err := LoadInstruments(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
+
+func TestLoadSessionCalendar(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "calendar.json")
+	body := `{"instruments":[{"symbol":"EUR/USD","asset_class":"forex","pip_size":0.0001,"precision":5,
+		"sessions":[{"name":"London","open":"07:00","close":"16:00"}]}],
+		"holidays":[{"date":"2024-12-25","name":"Christmas"}]}`
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cal, err := LoadSessionCalendar(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cal.MarketOpen("EURUSD", time.Date(2024, 12, 25, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected market closed on holiday")
+	}
+	if h, ok := cal.Holiday("EURUSD", time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)); !ok || h.Name != "Christmas" {
+		t.Fatalf("unexpected holiday %+v", h)
+	}
+
+	if err := os.WriteFile(p, []byte(`{"holidays":[{"date":"25/12/2024","name":"x"}]}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := LoadSessionCalendar(p); err == nil {
+		t.Fatalf("expected invalid date error")
+	}
+}
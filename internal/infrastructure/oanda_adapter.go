@@ -0,0 +1,411 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+// Default OandaAdapter settings.
+const (
+	DefaultOandaBaseURL      = "https://stream-fxpractice.oanda.com"
+	DefaultOandaAPIKeyEnv    = "OANDA_API_KEY"
+	DefaultOandaAccountEnv   = "OANDA_ACCOUNT_ID"
+	DefaultOandaReadDeadline = 20 * time.Second
+	DefaultOandaMaxCandleAge = 2 * time.Minute
+)
+
+// OandaOptions configures an OandaAdapter. Zero values fall back to the
+// defaults above.
+type OandaOptions struct {
+	// BaseURL is the scheme and host of the streaming API.
+	BaseURL string
+	// APIKey is used as-is when set.
+	APIKey string
+	// APIKeyEnv names the environment variable read when APIKey is empty.
+	APIKeyEnv string
+	// AccountID is used as-is when set.
+	AccountID string
+	// AccountEnv names the environment variable read when AccountID is empty.
+	AccountEnv string
+	// Client performs the streaming request. Defaults to http.DefaultClient.
+	Client *http.Client
+	// ReadDeadline reconnects when no line, including heartbeats, arrives
+	// within the window.
+	ReadDeadline time.Duration
+	// MaxCandleAge drops aggregated candles whose start time is older than this.
+	MaxCandleAge time.Duration
+	// Metrics records reconnects. Defaults to ports.NopMetrics.
+	Metrics ports.MetricsRecorder
+	// Instruments maps symbols to provider codes and canonical names. When
+	// nil, the adapter's built-in symbol conversion is used.
+	Instruments *entity.InstrumentRegistry
+	// Candles configures the default candle builder: one-minute bars unless
+	// set otherwise.
+	Candles usecase.TickAggregatorConfig
+	// NewCandleBuilder returns the aggregator for each StreamCandles call.
+	// Defaults to a usecase.TickAggregator configured by Candles.
+	NewCandleBuilder func() (ports.CandleBuilder, error)
+}
+
+func (o OandaOptions) withDefaults() OandaOptions {
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultOandaBaseURL
+	}
+	if o.APIKeyEnv == "" {
+		o.APIKeyEnv = DefaultOandaAPIKeyEnv
+	}
+	if o.APIKey == "" {
+		o.APIKey = os.Getenv(o.APIKeyEnv)
+	}
+	if o.AccountEnv == "" {
+		o.AccountEnv = DefaultOandaAccountEnv
+	}
+	if o.AccountID == "" {
+		o.AccountID = os.Getenv(o.AccountEnv)
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	if o.ReadDeadline <= 0 {
+		o.ReadDeadline = DefaultOandaReadDeadline
+	}
+	if o.MaxCandleAge <= 0 {
+		o.MaxCandleAge = DefaultOandaMaxCandleAge
+	}
+	if o.NewCandleBuilder == nil {
+		cfg := o.Candles
+		o.NewCandleBuilder = func() (ports.CandleBuilder, error) { return usecase.NewTickAggregator(cfg) }
+	}
+	return o
+}
+
+// OandaAdapter implements the MarketFeedAdapter and ports.TickFeedPort using
+// the OANDA v20 pricing stream. For candles, ticks are aggregated by the
+// configured CandleBuilder, and bars are emitted once a later tick or
+// heartbeat shows they have closed.
+type OandaAdapter struct {
+	*feedCore
+	newBuilder func() (ports.CandleBuilder, error)
+	client     *http.Client
+	apiKey     string
+	apiKeyEnv  string
+	accountID  string
+	accountEnv string
+	baseURL    string
+}
+
+// NewOandaAdapter initializes an OandaAdapter. A nil backoff uses
+// ExponentialBackoff.
+func NewOandaAdapter(logger *slog.Logger, backoff ports.BackoffStrategy, opts OandaOptions) *OandaAdapter {
+	opts = opts.withDefaults()
+	core := newFeedCore("oanda", logger, backoff, opts.Metrics)
+	core.instruments = opts.Instruments
+	core.readDeadline = opts.ReadDeadline
+	core.maxCandleAge = opts.MaxCandleAge
+	return &OandaAdapter{
+		feedCore:   core,
+		newBuilder: opts.NewCandleBuilder,
+		client:     opts.Client,
+		apiKey:     opts.APIKey,
+		apiKeyEnv:  opts.APIKeyEnv,
+		accountID:  opts.AccountID,
+		accountEnv: opts.AccountEnv,
+		baseURL:    opts.BaseURL,
+	}
+}
+
+// endpoint builds the pricing stream URL, e.g.
+// https://stream-fxpractice.oanda.com/v3/accounts/ID/pricing/stream?instruments=EUR_USD.
+func (a *OandaAdapter) endpoint(symbols []string) (string, error) {
+	u, err := url.Parse(a.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("unsupported base url scheme %q", u.Scheme)
+	}
+
+	instruments := make([]string, len(symbols))
+	for i, s := range symbols {
+		code, err := a.providerCode(s, func(s string) (string, error) {
+			base, quote, ok := splitPair(s)
+			if !ok {
+				return "", fmt.Errorf("cannot split pair %q", s)
+			}
+			return base + "_" + quote, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		instruments[i] = code
+	}
+	u.Path = path.Join("/", u.Path, "v3", "accounts", a.accountID, "pricing", "stream")
+	u.RawQuery = url.Values{"instruments": {strings.Join(instruments, ",")}}.Encode()
+	return u.String(), nil
+}
+
+// validate checks credentials and symbols and returns the stream endpoint.
+func (a *OandaAdapter) validate(symbols []string) (string, error) {
+	if a.apiKey == "" {
+		return "", fmt.Errorf("missing %s", a.apiKeyEnv)
+	}
+	if a.accountID == "" {
+		return "", fmt.Errorf("missing %s", a.accountEnv)
+	}
+	if len(symbols) == 0 {
+		return "", errors.New("no symbols provided")
+	}
+	return a.endpoint(symbols)
+}
+
+// StreamCandles opens the pricing stream and emits candles built from the
+// given symbols' ticks by the configured CandleBuilder.
+func (a *OandaAdapter) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
+	endpoint, err := a.validate(symbols)
+	if err != nil {
+		return nil, err
+	}
+	agg, err := a.newBuilder()
+	if err != nil {
+		return nil, fmt.Errorf("oanda: candle builder: %w", err)
+	}
+
+	out := make(chan ports.Candle)
+	go func() {
+		defer close(out)
+		a.run(ctx, endpoint, &oandaCandleSink{
+			adapter: a,
+			agg:     agg,
+			lastTS:  make(map[string]time.Time),
+			out:     out,
+		})
+	}()
+	return out, nil
+}
+
+// StreamTicks opens the pricing stream and emits every price update for the
+// given symbols as a tick carrying the best bid and ask.
+func (a *OandaAdapter) StreamTicks(ctx context.Context, symbols []string) (<-chan ports.Tick, error) {
+	endpoint, err := a.validate(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan ports.Tick)
+	go func() {
+		defer close(out)
+		a.run(ctx, endpoint, oandaTickSink{out: out})
+	}()
+	return out, nil
+}
+
+// oandaSink receives decoded stream events. Methods return false once ctx is
+// done.
+type oandaSink interface {
+	price(ctx context.Context, t ports.Tick) bool
+	heartbeat(ctx context.Context, ts time.Time) bool
+}
+
+type oandaTickSink struct{ out chan<- ports.Tick }
+
+func (s oandaTickSink) price(ctx context.Context, t ports.Tick) bool {
+	select {
+	case s.out <- t:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s oandaTickSink) heartbeat(context.Context, time.Time) bool { return true }
+
+type oandaCandleSink struct {
+	adapter *OandaAdapter
+	agg     ports.CandleBuilder
+	lastTS  map[string]time.Time
+	out     chan<- ports.Candle
+}
+
+func (s *oandaCandleSink) price(ctx context.Context, t ports.Tick) bool {
+	done, err := s.agg.Add(t)
+	switch {
+	case errors.Is(err, ports.ErrLateTick):
+		s.adapter.health.update(func(c *ports.FeedCounters) { c.DroppedStale++ })
+	case err != nil:
+		s.adapter.health.update(func(c *ports.FeedCounters) { c.DroppedInvalid++ })
+	}
+	return s.emit(ctx, done)
+}
+
+func (s *oandaCandleSink) heartbeat(ctx context.Context, ts time.Time) bool {
+	return s.emit(ctx, s.agg.Flush(ts))
+}
+
+func (s *oandaCandleSink) emit(ctx context.Context, candles []ports.Candle) bool {
+	for _, c := range candles {
+		if !s.adapter.accept(c, s.lastTS) {
+			continue
+		}
+		select {
+		case s.out <- c:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+func (a *OandaAdapter) run(ctx context.Context, endpoint string, sink oandaSink) {
+	defer a.health.setState(ports.FeedStopped)
+
+	var r retrier
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		a.connecting(ctx, &r)
+
+		switch a.session(ctx, endpoint, &r, sink) {
+		case sessionStop:
+			return
+		case sessionRejected:
+			if !a.wait(ctx, &r) {
+				return
+			}
+		}
+	}
+}
+
+// session performs one streaming request and consumes it until it fails or
+// stays silent for longer than readDeadline.
+func (a *OandaAdapter) session(ctx context.Context, endpoint string, r *retrier, sink oandaSink) sessionEnd {
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "build request", "provider", a.provider, "error", err)
+		return sessionRejected
+	}
+	req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	req.Header.Set("Accept-Datetime-Format", "RFC3339")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "connection failed", "provider", a.provider, "error", err)
+		if ctx.Err() != nil {
+			return sessionStop
+		}
+		return sessionRejected
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		a.logger.ErrorContext(ctx, "connection failed", "provider", a.provider, "status", resp.StatusCode)
+		return sessionRejected
+	}
+
+	r.retries = 0
+	a.health.setState(ports.FeedSubscribed)
+
+	idle := time.AfterFunc(a.readDeadline, func() {
+		a.logger.WarnContext(ctx, "stale stream detected, reconnecting", "provider", a.provider)
+		a.health.setState(ports.FeedStale)
+		cancel()
+	})
+	defer idle.Stop()
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		idle.Reset(a.readDeadline)
+		a.health.update(func(c *ports.FeedCounters) { c.Received++ })
+
+		var ev oandaEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			a.logger.ErrorContext(ctx, "decode error", "provider", a.provider, "error", err)
+			a.health.update(func(c *ports.FeedCounters) { c.DroppedInvalid++ })
+			continue
+		}
+
+		ok := true
+		switch ev.Type {
+		case "HEARTBEAT":
+			a.health.update(func(c *ports.FeedCounters) { c.Decoded++ })
+			ok = sink.heartbeat(ctx, ev.Time)
+		case "PRICE":
+			tick, err := ev.tick()
+			if err != nil {
+				a.logger.ErrorContext(ctx, "decode error", "provider", a.provider, "error", err)
+				a.health.update(func(c *ports.FeedCounters) { c.DroppedInvalid++ })
+				continue
+			}
+			a.health.update(func(c *ports.FeedCounters) { c.Decoded++ })
+			tick.Symbol = a.instruments.Canonical(tick.Symbol)
+			a.health.seen(tick.Symbol)
+			ok = sink.price(ctx, tick)
+		}
+		if !ok {
+			return sessionStop
+		}
+	}
+
+	if ctx.Err() != nil {
+		return sessionStop
+	}
+	if err := sc.Err(); err != nil {
+		a.logger.ErrorContext(ctx, "read error", "provider", a.provider, "error", err)
+	}
+	return sessionReconnect
+}
+
+// oandaEvent models PRICE and HEARTBEAT lines from the pricing stream.
+type oandaEvent struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Instrument string    `json:"instrument"`
+	Bids       []struct {
+		Price string `json:"price"`
+	} `json:"bids"`
+	Asks []struct {
+		Price string `json:"price"`
+	} `json:"asks"`
+}
+
+// tick converts a PRICE event into a tick with the best bid and ask.
+func (e oandaEvent) tick() (ports.Tick, error) {
+	if len(e.Bids) == 0 || len(e.Asks) == 0 {
+		return ports.Tick{}, errors.New("price without bids or asks")
+	}
+	bid, err := strconv.ParseFloat(e.Bids[0].Price, 64)
+	if err != nil {
+		return ports.Tick{}, fmt.Errorf("parse bid: %w", err)
+	}
+	ask, err := strconv.ParseFloat(e.Asks[0].Price, 64)
+	if err != nil {
+		return ports.Tick{}, fmt.Errorf("parse ask: %w", err)
+	}
+	return ports.Tick{Symbol: normalizeSymbol(e.Instrument), Time: e.Time, Bid: bid, Ask: ask}, nil
+}
+
+var (
+	_ ports.MarketFeedAdapter  = (*OandaAdapter)(nil)
+	_ ports.TickFeedPort       = (*OandaAdapter)(nil)
+	_ ports.FeedHealthReporter = (*OandaAdapter)(nil)
+)
@@ -0,0 +1,221 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/usecase"
+)
+
+func oandaPrice(inst string, ts time.Time, bid, ask float64) string {
+	return fmt.Sprintf(`{"type":"PRICE","time":"%s","instrument":"%s","bids":[{"price":"%g"}],"asks":[{"price":"%g"}]}`,
+		ts.Format(time.RFC3339Nano), inst, bid, ask)
+}
+
+func oandaHeartbeat(ts time.Time) string {
+	return fmt.Sprintf(`{"type":"HEARTBEAT","time":"%s"}`, ts.Format(time.RFC3339Nano))
+}
+
+func TestOandaAdapter_StreamCandles(t *testing.T) {
+	minute := time.Now().UTC().Truncate(time.Minute).Add(-time.Minute)
+
+	var (
+		mu     sync.Mutex
+		auth   string
+		reqURL string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth = r.Header.Get("Authorization")
+		reqURL = r.URL.String()
+		mu.Unlock()
+
+		flusher := w.(http.Flusher)
+		lines := []string{
+			oandaPrice("EUR_USD", minute.Add(time.Second), 1.0999, 1.1001),
+			oandaPrice("EUR_USD", minute.Add(30*time.Second), 1.1199, 1.1201),
+			oandaPrice("USD_JPY", minute.Add(40*time.Second), 149.99, 150.01),
+			"not json",
+			oandaPrice("EUR_USD", minute.Add(61*time.Second), 1.0499, 1.0501),
+			oandaPrice("EUR_USD", minute.Add(20*time.Second), 1.1299, 1.1301),
+			oandaHeartbeat(minute.Add(65 * time.Second)),
+		}
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+			flusher.Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	a := NewOandaAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockBackoff{}, OandaOptions{
+		BaseURL:   srv.URL,
+		APIKey:    "token",
+		AccountID: "001-001",
+		NewCandleBuilder: func() (ports.CandleBuilder, error) {
+			return usecase.NewTickAggregator(usecase.TickAggregatorConfig{})
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"EURUSD", "USD/JPY"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	got := receive(t, ch, 2)
+	bySym := map[string]ports.Candle{}
+	for _, c := range got {
+		bySym[c.Symbol] = c
+	}
+	eur := bySym["EURUSD"]
+	if !eur.Time.Equal(minute) || eur.Open != 1.1 || eur.High != 1.12 || eur.Low != 1.1 || eur.Close != 1.12 || eur.Volume != 2 {
+		t.Fatalf("unexpected EURUSD candle %+v", eur)
+	}
+	if jpy := bySym["USDJPY"]; jpy.Close != 150 || jpy.Volume != 1 {
+		t.Fatalf("unexpected USDJPY candle %+v", jpy)
+	}
+
+	if h := a.FeedHealth(); h.State != ports.FeedSubscribed || h.Counters.DroppedInvalid != 1 || h.Counters.DroppedStale != 1 {
+		t.Fatalf("unexpected health %+v", h)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if auth != "Bearer token" {
+		t.Fatalf("unexpected auth header %q", auth)
+	}
+	if reqURL != "/v3/accounts/001-001/pricing/stream?instruments=EUR_USD%2CUSD_JPY" {
+		t.Fatalf("unexpected url %q", reqURL)
+	}
+}
+
+func TestOandaAdapter_StreamCandlesDefaultBuilder(t *testing.T) {
+	minute := time.Now().UTC().Truncate(time.Minute).Add(-time.Minute)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, l := range []string{
+			oandaPrice("EUR_USD", minute.Add(time.Second), 1.0999, 1.1001),
+			oandaPrice("EUR_USD", minute.Add(61*time.Second), 1.1009, 1.1011),
+		} {
+			fmt.Fprintln(w, l)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	t.Setenv(DefaultOandaAPIKeyEnv, "token")
+	t.Setenv(DefaultOandaAccountEnv, "001")
+
+	a := NewOandaAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockBackoff{}, OandaOptions{BaseURL: srv.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := a.StreamCandles(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if c := receive(t, ch, 1)[0]; c.Symbol != "EURUSD" || !c.Time.Equal(minute) || c.Close != 1.1 {
+		t.Fatalf("unexpected one-minute candle %+v", c)
+	}
+}
+
+func TestOandaAdapter_StreamTicks(t *testing.T) {
+	now := time.Now().UTC()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, l := range []string{
+			oandaPrice("EUR_USD", now, 1.0999, 1.1001),
+			oandaHeartbeat(now),
+			oandaPrice("EUR_USD", now.Add(time.Second), 1.1009, 1.1011),
+		} {
+			fmt.Fprintln(w, l)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	a := NewOandaAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), &mockBackoff{}, OandaOptions{
+		BaseURL:   srv.URL,
+		APIKey:    "token",
+		AccountID: "001",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := a.StreamTicks(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var got []ports.Tick
+	for len(got) < 2 {
+		select {
+		case tk := <-ch:
+			got = append(got, tk)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for ticks, got %v", got)
+		}
+	}
+	if got[0].Symbol != "EURUSD" || got[0].Bid != 1.0999 || got[0].Ask != 1.1001 || !got[0].Time.Equal(now) {
+		t.Fatalf("unexpected tick %+v", got[0])
+	}
+	if got[1].Price() != 1.101 {
+		t.Fatalf("unexpected mid %v", got[1].Price())
+	}
+}
+
+func TestOandaAdapter_ReconnectOnIdle(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		conns int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns++
+		n := conns
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	mb := &mockBackoff{}
+	a := NewOandaAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), mb, OandaOptions{
+		BaseURL:      srv.URL,
+		APIKey:       "token",
+		AccountID:    "001",
+		ReadDeadline: 20 * time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	ch, err := a.StreamTicks(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	for range ch {
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns < 3 {
+		t.Fatalf("expected reconnects after rejection and idle timeout, got %d connections", conns)
+	}
+	if len(mb.calls) == 0 {
+		t.Fatalf("expected backoff after rejected request")
+	}
+	if h := a.FeedHealth(); h.State != ports.FeedStopped || h.Counters.Reconnects == 0 {
+		t.Fatalf("unexpected health %+v", h)
+	}
+}
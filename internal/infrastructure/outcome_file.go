@@ -0,0 +1,153 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// outcomeRecord is the JSON line written per outcome.
+type outcomeRecord struct {
+	ID         string    `json:"id"`
+	Symbol     string    `json:"symbol"`
+	Direction  string    `json:"direction"`
+	Confidence float64   `json:"confidence"`
+	TTLSeconds int       `json:"ttl_seconds"`
+	Source     string    `json:"source,omitempty"`
+	Stage      string    `json:"stage"`
+	Tags       []string  `json:"tags,omitempty"`
+	BarTime    time.Time `json:"bar_time"`
+	EntryTime  time.Time `json:"entry_time"`
+	ExpiryTime time.Time `json:"expiry_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Pips       float64   `json:"pips"`
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason"`
+}
+
+func newOutcomeRecord(o entity.SignalOutcome) outcomeRecord {
+	s := o.Signal
+	return outcomeRecord{
+		ID:         s.ID(),
+		Symbol:     s.Symbol,
+		Direction:  s.Direction,
+		Confidence: s.Confidence,
+		TTLSeconds: int(s.TTL / time.Second),
+		Source:     s.Source,
+		Stage:      stageName(s.Stage),
+		Tags:       s.Tags,
+		BarTime:    s.Time,
+		EntryTime:  o.EntryTime,
+		ExpiryTime: o.ExpiryTime,
+		EntryPrice: o.EntryPrice,
+		ExitPrice:  o.ExitPrice,
+		Pips:       o.Pips,
+		Outcome:    o.Outcome,
+		Reason:     o.Reason,
+	}
+}
+
+func (r outcomeRecord) outcome() entity.SignalOutcome {
+	stage := entity.SignalStage(r.Stage)
+	if r.Stage == "signal" {
+		stage = entity.StageSignal
+	}
+	return entity.SignalOutcome{
+		Signal: entity.Signal{
+			Symbol:     r.Symbol,
+			Direction:  r.Direction,
+			Confidence: r.Confidence,
+			TTL:        time.Duration(r.TTLSeconds) * time.Second,
+			Price:      r.EntryPrice,
+			Time:       r.BarTime,
+			Source:     r.Source,
+			Tags:       r.Tags,
+			Stage:      stage,
+		},
+		EntryTime:  r.EntryTime,
+		ExpiryTime: r.ExpiryTime,
+		EntryPrice: r.EntryPrice,
+		ExitPrice:  r.ExitPrice,
+		Pips:       r.Pips,
+		Outcome:    r.Outcome,
+		Reason:     r.Reason,
+	}
+}
+
+// FileOutcomeStore implements ports.OutcomeStore as an append-only JSON Lines
+// file.
+type FileOutcomeStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileOutcomeStore returns a store writing to path. The file is created on
+// the first Store.
+func NewFileOutcomeStore(path string) *FileOutcomeStore {
+	return &FileOutcomeStore{path: path}
+}
+
+// Store appends o to the file.
+func (s *FileOutcomeStore) Store(ctx context.Context, o entity.SignalOutcome) error {
+	b, err := json.Marshal(newOutcomeRecord(o))
+	if err != nil {
+		return fmt.Errorf("encode outcome: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open outcomes: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write outcome: %w", err)
+	}
+	return f.Close()
+}
+
+// List reads the outcomes that expired at or after since. A missing file
+// holds none.
+func (s *FileOutcomeStore) List(ctx context.Context, since time.Time) ([]entity.SignalOutcome, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open outcomes: %w", err)
+	}
+	defer f.Close()
+
+	var out []entity.SignalOutcome
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec outcomeRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("outcomes %s line %d: %w", s.path, line, err)
+		}
+		if !rec.ExpiryTime.Before(since) {
+			out = append(out, rec.outcome())
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read outcomes: %w", err)
+	}
+	return out, nil
+}
+
+var _ ports.OutcomeStore = (*FileOutcomeStore)(nil)
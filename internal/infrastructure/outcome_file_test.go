@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestFileOutcomeStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outcomes.jsonl")
+	s := NewFileOutcomeStore(path)
+
+	got, err := s.List(ctx, time.Time{})
+	if err != nil || got != nil {
+		t.Fatalf("expected empty store, got %v, %v", got, err)
+	}
+
+	bar := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	outcome := func(sym string, minute int, stage entity.SignalStage, result string) entity.SignalOutcome {
+		s := entity.Signal{Symbol: sym, Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute, Price: 1.1,
+			Time: bar.Add(time.Duration(minute) * time.Minute), Source: "rsi_divergence", Tags: []string{"trend"}, Stage: stage}
+		entry := s.Time.Add(time.Minute)
+		return entity.SignalOutcome{Signal: s, EntryTime: entry, ExpiryTime: entry.Add(s.TTL), EntryPrice: 1.1, ExitPrice: 1.2,
+			Pips: 1000, Outcome: result, Reason: "closed above entry"}
+	}
+	all := []entity.SignalOutcome{
+		outcome("EURUSD", 0, entity.StageSignal, "WIN"),
+		outcome("GBPUSD", 10, entity.StageConfirmed, "LOSS"),
+	}
+	for _, o := range all {
+		if err := s.Store(ctx, o); err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	got, err = NewFileOutcomeStore(path).List(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !reflect.DeepEqual(got, all) {
+		t.Fatalf("expected %+v, got %+v", all, got)
+	}
+	if got[0].Signal.ID() != all[0].Signal.ID() {
+		t.Fatalf("expected the signal ID to survive a round trip")
+	}
+	got, _ = s.List(ctx, all[1].ExpiryTime)
+	if !reflect.DeepEqual(got, all[1:]) {
+		t.Fatalf("expected outcomes since %v, got %+v", all[1].ExpiryTime, got)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := s.List(ctx, time.Time{}); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
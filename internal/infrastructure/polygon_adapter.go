@@ -0,0 +1,249 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// PolygonMarket selects which Polygon WebSocket cluster to stream from.
+type PolygonMarket string
+
+// Supported Polygon markets.
+const (
+	PolygonStocks PolygonMarket = "stocks"
+	PolygonForex  PolygonMarket = "forex"
+	PolygonCrypto PolygonMarket = "crypto"
+)
+
+// Default PolygonAdapter settings.
+const (
+	DefaultPolygonBaseURL      = "wss://socket.polygon.io"
+	DefaultPolygonAPIKeyEnv    = "POLYGON_API_KEY"
+	DefaultPolygonReadDeadline = 30 * time.Second
+	DefaultPolygonStaleAfter   = 90 * time.Second
+	DefaultPolygonMaxCandleAge = 2 * time.Minute
+)
+
+// PolygonOptions configures a PolygonAdapter. Zero values fall back to the
+// defaults above.
+type PolygonOptions struct {
+	// BaseURL is the scheme and host of the WebSocket API.
+	BaseURL string
+	// Market selects the cluster. Defaults to PolygonForex.
+	Market PolygonMarket
+	// APIKey is used as-is when set.
+	APIKey string
+	// APIKeyEnv names the environment variable read when APIKey is empty.
+	APIKeyEnv string
+	// ReadDeadline bounds how long a single read may block.
+	ReadDeadline time.Duration
+	// StaleAfter forces a reconnect when no candle arrives within the window.
+	StaleAfter time.Duration
+	// MaxCandleAge drops aggregates whose start time is older than this.
+	MaxCandleAge time.Duration
+	// Metrics records reconnects. Defaults to ports.NopMetrics.
+	Metrics ports.MetricsRecorder
+	// Instruments maps symbols to provider codes and canonical names. When
+	// nil, the adapter's built-in symbol conversion is used.
+	Instruments *entity.InstrumentRegistry
+}
+
+func (o PolygonOptions) withDefaults() PolygonOptions {
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultPolygonBaseURL
+	}
+	if o.Market == "" {
+		o.Market = PolygonForex
+	}
+	if o.APIKeyEnv == "" {
+		o.APIKeyEnv = DefaultPolygonAPIKeyEnv
+	}
+	if o.APIKey == "" {
+		o.APIKey = os.Getenv(o.APIKeyEnv)
+	}
+	if o.ReadDeadline <= 0 {
+		o.ReadDeadline = DefaultPolygonReadDeadline
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = DefaultPolygonStaleAfter
+	}
+	if o.MaxCandleAge <= 0 {
+		o.MaxCandleAge = DefaultPolygonMaxCandleAge
+	}
+	return o
+}
+
+// PolygonAdapter implements the MarketFeedAdapter using Polygon per-minute
+// aggregate streams (AM for stocks, CA for forex, XA for crypto).
+type PolygonAdapter struct {
+	*wsFeed
+	apiKey    string
+	apiKeyEnv string
+	baseURL   string
+	market    PolygonMarket
+}
+
+// NewPolygonAdapter initializes a PolygonAdapter. A nil dialer uses
+// websocket.DefaultDialer and a nil backoff uses ExponentialBackoff.
+func NewPolygonAdapter(logger *slog.Logger, dialer *websocket.Dialer, backoff ports.BackoffStrategy, opts PolygonOptions) *PolygonAdapter {
+	opts = opts.withDefaults()
+	feed := newWSFeed("polygon", logger, dialer, backoff, opts.Metrics)
+	feed.instruments = opts.Instruments
+	feed.readDeadline = opts.ReadDeadline
+	feed.staleAfter = opts.StaleAfter
+	feed.maxCandleAge = opts.MaxCandleAge
+	return &PolygonAdapter{
+		wsFeed:    feed,
+		apiKey:    opts.APIKey,
+		apiKeyEnv: opts.APIKeyEnv,
+		baseURL:   opts.BaseURL,
+		market:    opts.Market,
+	}
+}
+
+// endpoint returns the cluster URL, e.g. wss://socket.polygon.io/forex.
+func (a *PolygonAdapter) endpoint() (string, error) {
+	switch a.market {
+	case PolygonStocks, PolygonForex, PolygonCrypto:
+	default:
+		return "", fmt.Errorf("unsupported market %q", a.market)
+	}
+	u, err := url.Parse(a.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported base url scheme %q", u.Scheme)
+	}
+	u.Path = path.Join("/", u.Path, string(a.market))
+	return u.String(), nil
+}
+
+// params converts symbols into subscription parameters such as "AM.AAPL",
+// "CA.EUR/USD" or "XA.BTC-USD".
+func (a *PolygonAdapter) params(symbols []string) (string, error) {
+	out := make([]string, len(symbols))
+	prefix, sep := "XA.", "-"
+	switch a.market {
+	case PolygonStocks:
+		prefix = "AM."
+	case PolygonForex:
+		prefix, sep = "CA.", "/"
+	}
+	for i, s := range symbols {
+		code, err := a.providerCode(s, func(s string) (string, error) {
+			if a.market == PolygonStocks {
+				return strings.ToUpper(s), nil
+			}
+			base, quote, ok := splitPair(s)
+			if !ok {
+				return "", fmt.Errorf("cannot split pair %q", s)
+			}
+			return base + sep + quote, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		out[i] = prefix + code
+	}
+	return strings.Join(out, ","), nil
+}
+
+// StreamCandles authenticates with Polygon and streams per-minute aggregates
+// for the given symbols.
+func (a *PolygonAdapter) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
+	if a.apiKey == "" {
+		return nil, fmt.Errorf("missing %s", a.apiKeyEnv)
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("no symbols provided")
+	}
+	endpoint, err := a.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	params, err := a.params(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan ports.Candle)
+	go a.run(ctx, wsProtocol{
+		endpoint: endpoint,
+		subscribe: func(conn *websocket.Conn) error {
+			if err := conn.WriteJSON(map[string]string{"action": "auth", "params": a.apiKey}); err != nil {
+				return err
+			}
+			return conn.WriteJSON(map[string]string{"action": "subscribe", "params": params})
+		},
+		decode: decodePolygon,
+	}, out)
+	return out, nil
+}
+
+// polygonEvent models the union of status and aggregate events.
+type polygonEvent struct {
+	Event   string  `json:"ev"`
+	Status  string  `json:"status"`
+	Message string  `json:"message"`
+	Sym     string  `json:"sym"`
+	Pair    string  `json:"pair"`
+	Open    float64 `json:"o"`
+	High    float64 `json:"h"`
+	Low     float64 `json:"l"`
+	Close   float64 `json:"c"`
+	Volume  float64 `json:"v"`
+	Start   int64   `json:"s"`
+}
+
+func decodePolygon(msg []byte) ([]ports.Candle, error) {
+	var events []polygonEvent
+	if err := json.Unmarshal(msg, &events); err != nil {
+		return nil, err
+	}
+
+	var out []ports.Candle
+	for _, ev := range events {
+		switch ev.Event {
+		case "status":
+			if ev.Status == "auth_failed" {
+				return nil, fmt.Errorf("%w: %s", errSessionRejected, ev.Message)
+			}
+		case "AM", "CA", "XA":
+			sym := ev.Sym
+			if ev.Event != "AM" {
+				sym = ev.Pair
+			}
+			out = append(out, ports.Candle{
+				Symbol: normalizeSymbol(sym),
+				Time:   time.UnixMilli(ev.Start),
+				Open:   ev.Open,
+				High:   ev.High,
+				Low:    ev.Low,
+				Close:  ev.Close,
+				Volume: ev.Volume,
+			})
+		}
+	}
+	return out, nil
+}
+
+var (
+	_ ports.MarketFeedAdapter  = (*PolygonAdapter)(nil)
+	_ ports.FeedHealthReporter = (*PolygonAdapter)(nil)
+)
@@ -0,0 +1,158 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestPolygonAdapter_Params(t *testing.T) {
+	t.Parallel()
+
+	spx, err := entity.NewInstrumentRegistry(entity.Instrument{
+		Symbol:     "SPX",
+		AssetClass: entity.AssetIndex,
+		PipSize:    0.01,
+		Precision:  2,
+		Codes:      map[string]string{"polygon": "I:SPX"},
+	})
+	if err != nil {
+		t.Fatalf("registry: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		market      PolygonMarket
+		instruments *entity.InstrumentRegistry
+		symbols     []string
+		want        string
+	}{
+		{name: "stocks", market: PolygonStocks, symbols: []string{"aapl", "MSFT"}, want: "AM.AAPL,AM.MSFT"},
+		{name: "forex", market: PolygonForex, symbols: []string{"EURUSD", "GBP/JPY"}, want: "CA.EUR/USD,CA.GBP/JPY"},
+		{name: "crypto", market: PolygonCrypto, symbols: []string{"BTCUSD", "ETH-USDT"}, want: "XA.BTC-USD,XA.ETH-USDT"},
+		{name: "registry code", market: PolygonStocks, instruments: spx, symbols: []string{"spx", "AAPL"}, want: "AM.I:SPX,AM.AAPL"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := NewPolygonAdapter(nil, nil, nil, PolygonOptions{APIKey: "k", Market: tt.market, Instruments: tt.instruments})
+			got, err := a.params(tt.symbols)
+			if err != nil {
+				t.Fatalf("params: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPolygonAdapter_StreamCandles(t *testing.T) {
+	start := time.Now().Truncate(time.Minute).Add(-time.Minute)
+
+	var (
+		mu       sync.Mutex
+		path     string
+		requests []map[string]string
+	)
+	srv, base := newLocalWSServer(t, func(r *http.Request, c *websocket.Conn) {
+		_ = c.WriteMessage(websocket.TextMessage, []byte(`[{"ev":"status","status":"connected","message":"Connected Successfully"}]`))
+		for i := 0; i < 2; i++ {
+			var req map[string]string
+			if err := c.ReadJSON(&req); err != nil {
+				t.Errorf("read request: %v", err)
+				return
+			}
+			mu.Lock()
+			requests = append(requests, req)
+			mu.Unlock()
+		}
+		mu.Lock()
+		path = r.URL.Path
+		mu.Unlock()
+		_ = c.WriteMessage(websocket.TextMessage, []byte(`[{"ev":"status","status":"auth_success","message":"authenticated"}]`))
+		msg := fmt.Sprintf(`[{"ev":"CA","pair":"EUR/USD","o":1.1,"h":1.2,"l":1.0,"c":1.15,"v":10,"s":%d},{"ev":"CA","pair":"GBP/USD","o":1.3,"h":1.4,"l":1.2,"c":1.35,"v":5,"s":%d}]`,
+			start.UnixMilli(), start.UnixMilli())
+		_ = c.WriteMessage(websocket.TextMessage, []byte(msg))
+		time.Sleep(100 * time.Millisecond)
+	})
+	defer srv.Close()
+
+	a := NewPolygonAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, &mockBackoff{}, PolygonOptions{BaseURL: base, APIKey: "secret"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"EURUSD", "GBPUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	got := receive(t, ch, 2)
+	if got[0].Symbol != "EURUSD" || got[0].Close != 1.15 || !got[0].Time.Equal(start) {
+		t.Fatalf("unexpected candle %+v", got[0])
+	}
+	if got[1].Symbol != "GBPUSD" {
+		t.Fatalf("expected GBPUSD, got %s", got[1].Symbol)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/forex" {
+		t.Fatalf("unexpected path %q", path)
+	}
+	b, _ := json.Marshal(requests)
+	want := `[{"action":"auth","params":"secret"},{"action":"subscribe","params":"CA.EUR/USD,CA.GBP/USD"}]`
+	if string(b) != want {
+		t.Fatalf("expected requests %s, got %s", want, b)
+	}
+}
+
+func TestPolygonAdapter_AuthFailedBacksOff(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		conns int
+	)
+	srv, base := newLocalWSServer(t, func(r *http.Request, c *websocket.Conn) {
+		mu.Lock()
+		conns++
+		mu.Unlock()
+		c.ReadMessage()
+		c.ReadMessage()
+		_ = c.WriteMessage(websocket.TextMessage, []byte(`[{"ev":"status","status":"auth_failed","message":"authentication failed"}]`))
+		time.Sleep(100 * time.Millisecond)
+	})
+	defer srv.Close()
+
+	mb := &mockBackoff{}
+	a := NewPolygonAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, mb, PolygonOptions{BaseURL: base, APIKey: "bad"})
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	ch, err := a.StreamCandles(ctx, []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	for range ch {
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if conns < 2 {
+		t.Fatalf("expected reconnect after auth failure, got %d connections", conns)
+	}
+	if len(mb.calls) == 0 {
+		t.Fatalf("expected backoff after auth failure")
+	}
+}
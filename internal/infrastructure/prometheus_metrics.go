@@ -0,0 +1,158 @@
+package infrastructure
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+const metricsNamespace = "signalengine"
+
+// PrometheusMetrics implements ports.MetricsRecorder backed by a dedicated
+// Prometheus registry.
+type PrometheusMetrics struct {
+	registry         *prometheus.Registry
+	candles          *prometheus.CounterVec
+	buffer           *prometheus.GaugeVec
+	indicatorLatency prometheus.Histogram
+	scanLatency      prometheus.Histogram
+	signals          *prometheus.CounterVec
+	suppressed       *prometheus.CounterVec
+	settled          *prometheus.CounterVec
+	publishes        *prometheus.CounterVec
+	reconnects       *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics registers the pipeline metrics, along with the Go
+// runtime and process collectors, on a new registry.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	latencyBuckets := prometheus.ExponentialBuckets(0.00005, 2, 14)
+
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		candles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "candles_received_total",
+			Help:      "Candles consumed from the market feed.",
+		}, []string{"symbol"}),
+		buffer: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "candle_buffer_size",
+			Help:      "Candles buffered per symbol for scoring.",
+		}, []string{"symbol"}),
+		indicatorLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "indicator_duration_seconds",
+			Help:      "Time spent computing RSI and EMA indicators.",
+			Buckets:   latencyBuckets,
+		}),
+		scanLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scan_duration_seconds",
+			Help:      "Time spent scanning candles for signal patterns.",
+			Buckets:   latencyBuckets,
+		}),
+		signals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "signals_total",
+			Help:      "Signals produced per scorer and direction.",
+		}, []string{"scorer", "direction"}),
+		suppressed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "signals_suppressed_total",
+			Help:      "Signals dropped by cooldowns and rate limits per reason.",
+		}, []string{"reason"}),
+		settled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "signals_settled_total",
+			Help:      "Published signals settled per symbol and outcome.",
+		}, []string{"symbol", "outcome"}),
+		publishes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "publish_total",
+			Help:      "Publish attempts by result.",
+		}, []string{"result"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "feed_reconnects_total",
+			Help:      "Market feed reconnects per provider.",
+		}, []string{"provider"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.candles,
+		m.buffer,
+		m.indicatorLatency,
+		m.scanLatency,
+		m.signals,
+		m.suppressed,
+		m.settled,
+		m.publishes,
+		m.reconnects,
+	)
+	return m
+}
+
+// Handler returns an http.Handler serving the registry in the Prometheus
+// exposition format.
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// CandleReceived implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) CandleReceived(symbol string) {
+	m.candles.WithLabelValues(symbol).Inc()
+}
+
+// BufferSize implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) BufferSize(symbol string, n int) {
+	m.buffer.WithLabelValues(symbol).Set(float64(n))
+}
+
+// IndicatorLatency implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) IndicatorLatency(d time.Duration) {
+	m.indicatorLatency.Observe(d.Seconds())
+}
+
+// ScanLatency implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) ScanLatency(d time.Duration) {
+	m.scanLatency.Observe(d.Seconds())
+}
+
+// SignalProduced implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) SignalProduced(scorer, direction string) {
+	m.signals.WithLabelValues(scorer, direction).Inc()
+}
+
+// SignalSuppressed implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) SignalSuppressed(reason string) {
+	m.suppressed.WithLabelValues(reason).Inc()
+}
+
+// SignalSettled implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) SignalSettled(symbol, outcome string) {
+	m.settled.WithLabelValues(symbol, outcome).Inc()
+}
+
+// PublishResult implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) PublishResult(ok bool) {
+	result := "failure"
+	if ok {
+		result = "success"
+	}
+	m.publishes.WithLabelValues(result).Inc()
+}
+
+// FeedReconnect implements ports.MetricsRecorder.
+func (m *PrometheusMetrics) FeedReconnect(provider string) {
+	m.reconnects.WithLabelValues(provider).Inc()
+}
+
+var _ ports.MetricsRecorder = (*PrometheusMetrics)(nil)
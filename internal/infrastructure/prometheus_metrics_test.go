@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics_Handler(t *testing.T) {
+	m := NewPrometheusMetrics()
+	m.CandleReceived("EURUSD")
+	m.CandleReceived("EURUSD")
+	m.BufferSize("EURUSD", 20)
+	m.IndicatorLatency(time.Millisecond)
+	m.ScanLatency(2 * time.Millisecond)
+	m.SignalProduced("candlestick", "UP")
+	m.SignalSuppressed("cooldown")
+	m.SignalSettled("EURUSD", "WIN")
+	m.PublishResult(true)
+	m.PublishResult(false)
+	m.FeedReconnect("finage")
+
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	body := string(b)
+
+	want := []string{
+		`signalengine_candles_received_total{symbol="EURUSD"} 2`,
+		`signalengine_candle_buffer_size{symbol="EURUSD"} 20`,
+		`signalengine_indicator_duration_seconds_count 1`,
+		`signalengine_scan_duration_seconds_count 1`,
+		`signalengine_signals_total{direction="UP",scorer="candlestick"} 1`,
+		`signalengine_signals_suppressed_total{reason="cooldown"} 1`,
+		`signalengine_signals_settled_total{outcome="WIN",symbol="EURUSD"} 1`,
+		`signalengine_publish_total{result="success"} 1`,
+		`signalengine_publish_total{result="failure"} 1`,
+		`signalengine_feed_reconnects_total{provider="finage"} 1`,
+		`go_goroutines`,
+	}
+	for _, w := range want {
+		if !strings.Contains(body, w) {
+			t.Errorf("expected metrics output to contain %q", w)
+		}
+	}
+}
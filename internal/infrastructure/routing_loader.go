@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// defaultRoutingPollInterval is the WatchRoutingTable interval used when
+// none is given.
+const defaultRoutingPollInterval = 10 * time.Second
+
+// routingFile is the JSON layout read by LoadRoutingTable.
+type routingFile struct {
+	Routes []struct {
+		Destination   string   `json:"destination"`
+		Symbols       []string `json:"symbols"`
+		Directions    []string `json:"directions"`
+		Sources       []string `json:"sources"`
+		Sessions      []string `json:"sessions"`
+		MinConfidence float64  `json:"min_confidence"`
+	} `json:"routes"`
+}
+
+// LoadRoutingTable reads routing rules from a JSON file. Each route names a
+// destination; a destination may have several routes and receives signals
+// matching any of them.
+func LoadRoutingTable(path string) (entity.RoutingTable, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read routes: %w", err)
+	}
+	return parseRoutingTable(b)
+}
+
+func parseRoutingTable(b []byte) (entity.RoutingTable, error) {
+	var f routingFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("decode routes: %w", err)
+	}
+	table := make(entity.RoutingTable)
+	for i, r := range f.Routes {
+		if r.Destination == "" {
+			return nil, fmt.Errorf("route %d: destination is required", i)
+		}
+		if r.MinConfidence < 0 || r.MinConfidence > 1 {
+			return nil, fmt.Errorf("route %d: min_confidence %v outside [0, 1]", i, r.MinConfidence)
+		}
+		table[r.Destination] = append(table[r.Destination], entity.RoutingRule{
+			Symbols:       r.Symbols,
+			Directions:    r.Directions,
+			Sources:       r.Sources,
+			Sessions:      r.Sessions,
+			MinConfidence: r.MinConfidence,
+		})
+	}
+	return table, nil
+}
+
+// WatchRoutingTable applies the routing file at path, then polls it every
+// interval and applies it again whenever its contents change. Files that
+// cannot be read, parsed or applied are logged and the previous rules stay
+// in effect. A non-positive interval defaults to 10 seconds. It blocks until
+// ctx is done.
+func WatchRoutingTable(ctx context.Context, logger *slog.Logger, path string, interval time.Duration, apply func(entity.RoutingTable) error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if interval <= 0 {
+		interval = defaultRoutingPollInterval
+	}
+	var last []byte
+	for {
+		b, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			logger.ErrorContext(ctx, "read routes", "path", path, "error", err)
+		case last != nil && bytes.Equal(b, last):
+		default:
+			table, err := parseRoutingTable(b)
+			if err == nil {
+				err = apply(table)
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "reload routes", "path", path, "error", err)
+			} else {
+				logger.InfoContext(ctx, "routes loaded", "path", path, "destinations", len(table))
+			}
+			last = b
+		}
+		if !sleep(ctx, interval) {
+			return
+		}
+	}
+}
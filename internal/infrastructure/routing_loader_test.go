@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestLoadRoutingTable(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return p
+	}
+
+	good := write("routes.json", `{"routes":[
+		{"destination":"majors","symbols":["EURUSD","GBPUSD"]},
+		{"destination":"majors","sessions":["London"],"sources":["rsi_divergence"]},
+		{"destination":"confident","min_confidence":0.7,"directions":["UP"]},
+		{"destination":"vip"}]}`)
+	got, err := LoadRoutingTable(good)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := entity.RoutingTable{
+		"majors": {
+			{Symbols: []string{"EURUSD", "GBPUSD"}},
+			{Sessions: []string{"London"}, Sources: []string{"rsi_divergence"}},
+		},
+		"confident": {{MinConfidence: 0.7, Directions: []string{"UP"}}},
+		"vip":       {{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	for name, body := range map[string]string{
+		"bad_json.json":       `{`,
+		"no_destination.json": `{"routes":[{"symbols":["EURUSD"]}]}`,
+		"bad_confidence.json": `{"routes":[{"destination":"vip","min_confidence":70}]}`,
+	} {
+		if _, err := LoadRoutingTable(write(name, body)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestWatchRoutingTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write(`{"routes":[{"destination":"vip"}]}`)
+
+	applied := make(chan entity.RoutingTable, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		WatchRoutingTable(ctx, nil, path, 5*time.Millisecond, func(t entity.RoutingTable) error {
+			applied <- t
+			return nil
+		})
+		close(done)
+	}()
+	next := func() entity.RoutingTable {
+		t.Helper()
+		select {
+		case table := <-applied:
+			return table
+		case <-time.After(time.Second):
+			t.Fatal("routes not applied")
+			return nil
+		}
+	}
+
+	if got := next(); !reflect.DeepEqual(got, entity.RoutingTable{"vip": {{}}}) {
+		t.Fatalf("initial table %+v", got)
+	}
+	// Invalid contents are skipped; the next valid file is applied.
+	write(`{"routes":[`)
+	write(`{"routes":[{"destination":"majors","symbols":["EURUSD"]}]}`)
+	if got := next(); !reflect.DeepEqual(got, entity.RoutingTable{"majors": {{Symbols: []string{"EURUSD"}}}}) {
+		t.Fatalf("reloaded table %+v", got)
+	}
+	select {
+	case table := <-applied:
+		t.Fatalf("unchanged file applied again: %+v", table)
+	case <-time.After(30 * time.Millisecond):
+	}
+	cancel()
+	<-done
+}
+
+func TestWatchRoutingTable_DefaultInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := os.WriteFile(path, []byte(`{"routes":[{"destination":"vip"}]}`), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		applied := make(chan entity.RoutingTable, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			WatchRoutingTable(ctx, nil, path, interval, func(t entity.RoutingTable) error {
+				applied <- t
+				return nil
+			})
+			close(done)
+		}()
+		select {
+		case <-applied:
+		case <-time.After(time.Second):
+			t.Fatalf("interval %s: routes not applied", interval)
+		}
+		if err := os.WriteFile(path, []byte(`{"routes":[{"destination":"majors"}]}`), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		select {
+		case table := <-applied:
+			t.Fatalf("interval %s: expected the default poll interval, reloaded %+v", interval, table)
+		case <-time.After(50 * time.Millisecond):
+		}
+		cancel()
+		<-done
+	}
+}
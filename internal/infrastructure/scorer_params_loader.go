@@ -0,0 +1,124 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// scorerParamsFile is the JSON layout read by LoadScorerParams.
+type scorerParamsFile struct {
+	Default      scorerParamsJSON            `json:"default"`
+	AssetClasses map[string]scorerParamsJSON `json:"asset_classes"`
+	Symbols      map[string]scorerParamsJSON `json:"symbols"`
+}
+
+type scorerParamsJSON struct {
+	RSIDivergence struct {
+		RSIPeriod    int        `json:"rsi_period"`
+		Lookback     int        `json:"lookback"`
+		ReversalBars int        `json:"reversal_bars"`
+		PinBar       pinBarJSON `json:"pin_bar"`
+		signalParamsJSON
+	} `json:"rsi_divergence"`
+	EMAInteraction struct {
+		FastPeriod int `json:"fast_period"`
+		SlowPeriod int `json:"slow_period"`
+		signalParamsJSON
+	} `json:"ema_interaction"`
+	Candlestick struct {
+		PinBar pinBarJSON `json:"pin_bar"`
+		signalParamsJSON
+	} `json:"candlestick"`
+}
+
+type pinBarJSON struct {
+	MaxBody float64 `json:"max_body"`
+	MinWick float64 `json:"min_wick"`
+}
+
+type signalParamsJSON struct {
+	Confidence float64 `json:"confidence"`
+	TTL        string  `json:"ttl"`
+}
+
+// LoadScorerParams reads scorer parameters from a JSON file with a
+// "default" entry and overrides under "asset_classes" and "symbols". TTLs
+// are Go durations such as "2m". Omitted fields inherit from the enclosing
+// level. reg resolves the asset class of symbols.
+func LoadScorerParams(path string, reg *entity.InstrumentRegistry) (*entity.ScorerParamsTable, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read scorer params: %w", err)
+	}
+	return parseScorerParams(b, reg)
+}
+
+func parseScorerParams(b []byte, reg *entity.InstrumentRegistry) (*entity.ScorerParamsTable, error) {
+	var f scorerParamsFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("decode scorer params: %w", err)
+	}
+	cfg := entity.ScorerParamsConfig{
+		AssetClasses: make(map[entity.AssetClass]entity.ScorerParams, len(f.AssetClasses)),
+		Symbols:      make(map[string]entity.ScorerParams, len(f.Symbols)),
+		Instruments:  reg,
+	}
+	var err error
+	if cfg.Default, err = f.Default.params(); err != nil {
+		return nil, fmt.Errorf("scorer params default: %w", err)
+	}
+	for class, p := range f.AssetClasses {
+		if cfg.AssetClasses[entity.AssetClass(class)], err = p.params(); err != nil {
+			return nil, fmt.Errorf("scorer params %s: %w", class, err)
+		}
+	}
+	for sym, p := range f.Symbols {
+		if cfg.Symbols[sym], err = p.params(); err != nil {
+			return nil, fmt.Errorf("scorer params %s: %w", sym, err)
+		}
+	}
+	return entity.NewScorerParamsTable(cfg)
+}
+
+func (j scorerParamsJSON) params() (entity.ScorerParams, error) {
+	var p entity.ScorerParams
+	var err error
+	rsi := j.RSIDivergence
+	p.RSIDivergence = entity.RSIDivergenceParams{
+		RSIPeriod:    rsi.RSIPeriod,
+		Lookback:     rsi.Lookback,
+		ReversalBars: rsi.ReversalBars,
+		PinBar:       entity.PinBarParams(rsi.PinBar),
+		Confidence:   rsi.Confidence,
+	}
+	if p.RSIDivergence.TTL, err = parseTTL(rsi.TTL); err != nil {
+		return p, fmt.Errorf("rsi_divergence: %w", err)
+	}
+	ema := j.EMAInteraction
+	p.EMAInteraction = entity.EMAInteractionParams{FastPeriod: ema.FastPeriod, SlowPeriod: ema.SlowPeriod, Confidence: ema.Confidence}
+	if p.EMAInteraction.TTL, err = parseTTL(ema.TTL); err != nil {
+		return p, fmt.Errorf("ema_interaction: %w", err)
+	}
+	cs := j.Candlestick
+	p.Candlestick = entity.CandlestickParams{PinBar: entity.PinBarParams(cs.PinBar), Confidence: cs.Confidence}
+	if p.Candlestick.TTL, err = parseTTL(cs.TTL); err != nil {
+		return p, fmt.Errorf("candlestick: %w", err)
+	}
+	return p, nil
+}
+
+// parseTTL parses an optional duration; an empty string leaves it unset.
+func parseTTL(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ttl %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestLoadScorerParams(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return p
+	}
+
+	reg := entity.DefaultInstruments()
+	good := write("scorers.json", `{
+		"default": {"candlestick": {"confidence": 0.55}},
+		"asset_classes": {"crypto": {
+			"rsi_divergence": {"lookback": 30, "ttl": "5m"},
+			"ema_interaction": {"fast_period": 12, "slow_period": 26},
+			"candlestick": {"pin_bar": {"max_body": 0.25, "min_wick": 0.7}}
+		}},
+		"symbols": {"BTC/USDT": {"rsi_divergence": {"confidence": 0.7}}}
+	}`)
+	table, err := LoadScorerParams(good, reg)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	eur := table.For("EURUSD")
+	if eur.Candlestick.Confidence != 0.55 || eur.RSIDivergence.Lookback != 20 || eur.EMAInteraction.SlowPeriod != 21 {
+		t.Fatalf("expected defaults with the candlestick override, got %+v", eur)
+	}
+	btc := table.For("BTCUSDT")
+	want := entity.RSIDivergenceParams{RSIPeriod: 14, Lookback: 30, ReversalBars: 3,
+		PinBar: entity.PinBarParams{MaxBody: 1.0 / 3, MinWick: 2.0 / 3}, Confidence: 0.7, TTL: 5 * time.Minute}
+	if btc.RSIDivergence != want {
+		t.Fatalf("expected %+v, got %+v", want, btc.RSIDivergence)
+	}
+	if btc.EMAInteraction.FastPeriod != 12 || btc.Candlestick.PinBar.MaxBody != 0.25 || btc.Candlestick.Confidence != 0.55 {
+		t.Fatalf("expected crypto overrides over the default, got %+v", btc)
+	}
+
+	for name, body := range map[string]string{
+		"bad_json.json":       `{`,
+		"bad_ttl.json":        `{"default": {"ema_interaction": {"ttl": "soon"}}}`,
+		"bad_ema.json":        `{"asset_classes": {"crypto": {"ema_interaction": {"fast_period": 30}}}}`,
+		"bad_pin.json":        `{"symbols": {"EURUSD": {"candlestick": {"pin_bar": {"max_body": 0.5}}}}}`,
+		"bad_lookback.json":   `{"default": {"rsi_divergence": {"lookback": 4, "reversal_bars": 3}}}`,
+		"bad_confidence.json": `{"default": {"rsi_divergence": {"confidence": 80}}}`,
+		"negative.json":       `{"asset_classes": {"forex": {"candlestick": {"confidence": -0.5}}}}`,
+		"negative_ttl.json":   `{"symbols": {"EURUSD": {"ema_interaction": {"ttl": "-1m"}}}}`,
+		"duplicate.json":      `{"symbols": {"EURUSD": {}, "EUR/USD": {}}}`,
+	} {
+		if _, err := LoadScorerParams(write(name, body), reg); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if _, err := LoadScorerParams(filepath.Join(dir, "missing.json"), reg); err == nil {
+		t.Fatal("expected error for a missing file")
+	}
+}
@@ -0,0 +1,217 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// SignalSchemaVersion is the version of the JSON document posted by
+// SignedWebhookSink. It changes only when fields are removed or change
+// meaning; receivers should ignore unknown fields.
+const SignalSchemaVersion = 1
+
+// Headers set by SignedWebhookSink.
+const (
+	HeaderSignalTimestamp = "X-Signal-Timestamp"
+	HeaderSignalSignature = "X-Signal-Signature"
+	HeaderIdempotencyKey  = "Idempotency-Key"
+)
+
+// SignedWebhookOptions configures a SignedWebhookSink.
+type SignedWebhookOptions struct {
+	// URLs receive every signal.
+	URLs []string
+	// Secret is the HMAC-SHA256 key shared with the receivers.
+	Secret string
+	// MaxAttempts is the number of deliveries tried per signal and URL.
+	// Defaults to 3.
+	MaxAttempts int
+	// Backoff computes the wait before each retry. Defaults to doubling from
+	// 500ms up to 10s.
+	Backoff ports.BackoffStrategy
+	// Client performs the requests. Defaults to an http.Client with a 10s
+	// timeout.
+	Client *http.Client
+}
+
+func (o SignedWebhookOptions) withDefaults() SignedWebhookOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.Backoff == nil {
+		o.Backoff = ExponentialBackoff{Base: 500 * time.Millisecond, Max: 10 * time.Second}
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return o
+}
+
+// SignalDocument is the versioned JSON schema posted by SignedWebhookSink,
+// one document per request.
+type SignalDocument struct {
+	Version    int       `json:"version"`
+	ID         string    `json:"id"`
+	Stage      string    `json:"stage"`
+	Symbol     string    `json:"symbol"`
+	Direction  string    `json:"direction"`
+	Confidence float64   `json:"confidence"`
+	TTLSeconds int       `json:"ttl_seconds"`
+	Price      float64   `json:"price,omitempty"`
+	BarTime    time.Time `json:"bar_time,omitzero"`
+	Tags       []string  `json:"tags,omitempty"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// SignedWebhookSink implements ports.SignalPublisher for execution bots. Each
+// signal is POSTed as a SignalDocument with these headers:
+//
+//	X-Signal-Timestamp: <unix seconds>
+//	X-Signal-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//	Idempotency-Key:    <signal ID>-<stage>
+//
+// Receivers verify requests with VerifyWebhookSignature and use the
+// idempotency key to discard retried deliveries they already processed.
+type SignedWebhookSink struct {
+	opts SignedWebhookOptions
+	now  func() time.Time
+}
+
+// NewSignedWebhookSink returns a sink posting to opts.URLs.
+func NewSignedWebhookSink(opts SignedWebhookOptions) (*SignedWebhookSink, error) {
+	if len(opts.URLs) == 0 || opts.Secret == "" {
+		return nil, fmt.Errorf("signed webhook sink: URLs and secret are required")
+	}
+	return &SignedWebhookSink{opts: opts.withDefaults(), now: time.Now}, nil
+}
+
+// PublishSignals posts each signal to every URL, retrying network errors,
+// 429 and 5xx responses with backoff. Other responses are not retried. It
+// returns the joined errors of all deliveries that failed.
+func (w *SignedWebhookSink) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	var errs []error
+	for _, s := range signals {
+		key := s.ID() + "-" + stageName(s.Stage)
+		for _, url := range w.opts.URLs {
+			if err := w.deliver(ctx, url, key, s); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", url, key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver posts s to url until it succeeds, fails permanently or runs out of
+// attempts. The body and signature are rebuilt on every attempt so that the
+// timestamp stays fresh.
+func (w *SignedWebhookSink) deliver(ctx context.Context, url, key string, s entity.Signal) error {
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, url, key, s)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.opts.MaxAttempts {
+			return err
+		}
+		if !sleep(ctx, w.opts.Backoff.Next(attempt-1)) {
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+	}
+}
+
+// post performs one delivery and reports whether a failure may be retried.
+func (w *SignedWebhookSink) post(ctx context.Context, url, key string, s entity.Signal) (bool, error) {
+	now := w.now()
+	body, err := json.Marshal(SignalDocument{
+		Version:    SignalSchemaVersion,
+		ID:         s.ID(),
+		Stage:      stageName(s.Stage),
+		Symbol:     s.Symbol,
+		Direction:  s.Direction,
+		Confidence: s.Confidence,
+		TTLSeconds: int(s.TTL / time.Second),
+		Price:      s.Price,
+		BarTime:    s.Time.UTC(),
+		Tags:       s.Tags,
+		SentAt:     now.UTC(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("encode signal: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("build request: %w", err)
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignalTimestamp, ts)
+	req.Header.Set(HeaderSignalSignature, signWebhook(w.opts.Secret, ts, body))
+	req.Header.Set(HeaderIdempotencyKey, key)
+
+	resp, err := w.opts.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return false, nil
+}
+
+// stageName returns the schema name of stage.
+func stageName(stage entity.SignalStage) string {
+	if stage == entity.StageSignal {
+		return "signal"
+	}
+	return string(stage)
+}
+
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature and timestamp headers of a
+// request sent by SignedWebhookSink. Requests whose timestamp is more than
+// tolerance away from now are rejected to limit replays; a zero tolerance
+// skips that check.
+func VerifyWebhookSignature(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	ts := header.Get(HeaderSignalTimestamp)
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header %q", HeaderSignalTimestamp, ts)
+	}
+	if tolerance > 0 {
+		if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+			return fmt.Errorf("webhook timestamp outside tolerance: %s", d)
+		}
+	}
+	want := signWebhook(secret, ts, body)
+	if !hmac.Equal([]byte(header.Get(HeaderSignalSignature)), []byte(want)) {
+		return fmt.Errorf("webhook signature mismatch")
+	}
+	return nil
+}
+
+var _ ports.SignalPublisher = (*SignedWebhookSink)(nil)
@@ -0,0 +1,153 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+type zeroBackoff struct{}
+
+func (zeroBackoff) Next(int) time.Duration { return 0 }
+
+// webhookReceiver records verified deliveries and answers with the queued
+// status codes, then 200.
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	keys     []string
+	docs     []SignalDocument
+}
+
+func (rv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := VerifyWebhookSignature(rv.secret, r.Header, body, time.Now(), time.Minute); err != nil {
+		rv.t.Errorf("verify: %v", err)
+	}
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	rv.requests++
+	if len(rv.statuses) > 0 {
+		status := rv.statuses[0]
+		rv.statuses = rv.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+	var doc SignalDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		rv.t.Errorf("decode: %v", err)
+	}
+	rv.keys = append(rv.keys, r.Header.Get(HeaderIdempotencyKey))
+	rv.docs = append(rv.docs, doc)
+}
+
+func TestSignedWebhookSink(t *testing.T) {
+	bar := time.Date(2024, 3, 1, 13, 30, 0, 0, time.UTC)
+	sig := entity.Signal{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute, Price: 1.0845, Time: bar}
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "delivered", wantRequests: 1},
+		{name: "retries server errors", statuses: []int{500, 429}, wantRequests: 3},
+		{name: "gives up after max attempts", statuses: []int{503, 503, 503}, wantRequests: 3, wantErr: true},
+		{name: "does not retry client errors", statuses: []int{400}, wantRequests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rv := &webhookReceiver{t: t, secret: "s3cret", statuses: tt.statuses}
+			srv := httptest.NewServer(rv)
+			defer srv.Close()
+
+			sink, err := NewSignedWebhookSink(SignedWebhookOptions{URLs: []string{srv.URL}, Secret: "s3cret", Backoff: zeroBackoff{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = sink.PublishSignals(context.Background(), []entity.Signal{sig})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if rv.requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", rv.requests, tt.wantRequests)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := []string{sig.ID() + "-signal"}; len(rv.keys) != 1 || rv.keys[0] != want[0] {
+				t.Errorf("idempotency keys = %v, want %v", rv.keys, want)
+			}
+			doc := rv.docs[0]
+			if doc.Version != SignalSchemaVersion || doc.ID != sig.ID() || doc.Stage != "signal" ||
+				doc.Symbol != "EURUSD" || doc.TTLSeconds != 120 || !doc.BarTime.Equal(bar) {
+				t.Errorf("document = %+v", doc)
+			}
+		})
+	}
+}
+
+func TestSignedWebhookSink_StagesShareID(t *testing.T) {
+	rv := &webhookReceiver{t: t, secret: "k"}
+	srv := httptest.NewServer(rv)
+	defer srv.Close()
+	sink, err := NewSignedWebhookSink(SignedWebhookOptions{URLs: []string{srv.URL}, Secret: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar := time.Date(2024, 3, 1, 13, 30, 0, 0, time.UTC)
+	early := entity.Signal{Symbol: "EUR/USD", Direction: "UP", TTL: time.Minute, Time: bar, Stage: entity.StageEarly}
+	confirmed := early
+	confirmed.Stage = entity.StageConfirmed
+	if err := sink.PublishSignals(context.Background(), []entity.Signal{early, confirmed}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rv.docs) != 2 || rv.docs[0].ID != rv.docs[1].ID {
+		t.Fatalf("documents = %+v, want a shared ID", rv.docs)
+	}
+	if rv.keys[0] == rv.keys[1] {
+		t.Errorf("idempotency keys %v should differ per stage", rv.keys)
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"version":1}`)
+	header := http.Header{}
+	header.Set(HeaderSignalTimestamp, "1700000000")
+	header.Set(HeaderSignalSignature, signWebhook("k", "1700000000", body))
+
+	if err := VerifyWebhookSignature("k", header, body, now.Add(30*time.Second), time.Minute); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if err := VerifyWebhookSignature("other", header, body, now, time.Minute); err == nil {
+		t.Error("wrong secret accepted")
+	}
+	if err := VerifyWebhookSignature("k", header, []byte(`{"version":2}`), now, time.Minute); err == nil {
+		t.Error("tampered body accepted")
+	}
+	if err := VerifyWebhookSignature("k", header, body, now.Add(5*time.Minute), time.Minute); err == nil {
+		t.Error("stale timestamp accepted")
+	}
+}
+
+func TestNewSignedWebhookSink_Validates(t *testing.T) {
+	if _, err := NewSignedWebhookSink(SignedWebhookOptions{URLs: []string{"http://x"}}); err == nil {
+		t.Error("missing secret accepted")
+	}
+	if _, err := NewSignedWebhookSink(SignedWebhookOptions{Secret: "k"}); err == nil {
+		t.Error("missing URLs accepted")
+	}
+}
@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// SMTPOptions configures an SMTPSink.
+type SMTPOptions struct {
+	// Addr is the server as "host:port".
+	Addr string
+	// Username and Password enable PLAIN authentication when Username is
+	// set.
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Subject defaults to "Trading signals: <symbol>".
+	Subject string
+	// Format renders one signal. Defaults to a one-line summary.
+	Format func(entity.Signal) string
+}
+
+// SMTPSink implements ports.SignalPublisher by emailing signals.
+type SMTPSink struct {
+	opts SMTPOptions
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSink returns a sink sending through opts.Addr.
+func NewSMTPSink(opts SMTPOptions) (*SMTPSink, error) {
+	if opts.Addr == "" || opts.From == "" || len(opts.To) == 0 {
+		return nil, fmt.Errorf("smtp sink: address, sender and recipients are required")
+	}
+	if opts.Format == nil {
+		opts.Format = func(s entity.Signal) string { return signalLine(s, func(s string) string { return s }) }
+	}
+	return &SMTPSink{opts: opts, send: smtp.SendMail}, nil
+}
+
+// PublishSignals sends one email listing signals. net/smtp does not support
+// cancellation, so ctx is only checked before sending; bound slow servers
+// with a FanOut sink timeout.
+func (s *SMTPSink) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	subject := s.opts.Subject
+	if subject == "" {
+		subject = "Trading signals: " + signals[0].Symbol
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.opts.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.opts.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	for _, sig := range signals {
+		b.WriteString(strings.ReplaceAll(s.opts.Format(sig), "\n", "\r\n"))
+		b.WriteString("\r\n")
+	}
+
+	var auth smtp.Auth
+	if s.opts.Username != "" {
+		host, _, err := net.SplitHostPort(s.opts.Addr)
+		if err != nil {
+			return fmt.Errorf("smtp address: %w", err)
+		}
+		auth = smtp.PlainAuth("", s.opts.Username, s.opts.Password, host)
+	}
+	if err := s.send(s.opts.Addr, auth, s.opts.From, s.opts.To, []byte(b.String())); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
+
+var _ ports.SignalPublisher = (*SMTPSink)(nil)
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestSMTPSink(t *testing.T) {
+	if _, err := NewSMTPSink(SMTPOptions{Addr: "mail:25"}); err == nil {
+		t.Fatalf("expected missing sender and recipients to fail")
+	}
+
+	sink, err := NewSMTPSink(SMTPOptions{Addr: "mail.example.com:587", Username: "bot", Password: "secret", From: "bot@example.com", To: []string{"a@example.com", "b@example.com"}})
+	if err != nil {
+		t.Fatalf("new smtp sink: %v", err)
+	}
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotAuth smtp.Auth
+	var gotMsg string
+	sink.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, a, from, to, string(msg)
+		return nil
+	}
+
+	signals := []entity.Signal{{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute}}
+	if err := sink.PublishSignals(context.Background(), signals); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if gotAddr != "mail.example.com:587" || gotFrom != "bot@example.com" || len(gotTo) != 2 || gotAuth == nil {
+		t.Fatalf("unexpected envelope %s %s %v %v", gotAddr, gotFrom, gotTo, gotAuth)
+	}
+	for _, want := range []string{
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: Trading signals: EURUSD\r\n",
+		"\r\n\r\n⚡ EURUSD UP · 80% · 2m\r\n",
+	} {
+		if !strings.Contains(gotMsg, want) {
+			t.Fatalf("expected message to contain %q, got %q", want, gotMsg)
+		}
+	}
+}
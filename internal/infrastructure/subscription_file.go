@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// subscriptionFile is the JSON layout written by FileSubscriptionStore.
+type subscriptionFile struct {
+	Subscriptions map[int64][]string         `json:"subscriptions"`
+	Preferences   map[int64]preferenceRecord `json:"preferences,omitempty"`
+}
+
+type preferenceRecord struct {
+	Locale   string `json:"locale,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// FileSubscriptionStore implements ports.SubscriptionStore and
+// ports.PreferenceStore as one JSON file. Saves write a temporary file and
+// rename it over the old one, so a crash never leaves a partially written
+// file.
+type FileSubscriptionStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSubscriptionStore returns a store backed by path. The file is
+// created on the first Save.
+func NewFileSubscriptionStore(path string) *FileSubscriptionStore {
+	return &FileSubscriptionStore{path: path}
+}
+
+// Load reads the subscriptions. A missing file holds none.
+func (s *FileSubscriptionStore) Load(ctx context.Context) (map[int64][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if f.Subscriptions == nil {
+		f.Subscriptions = map[int64][]string{}
+	}
+	return f.Subscriptions, nil
+}
+
+// Save replaces the subscriptions, keeping the stored preferences.
+func (s *FileSubscriptionStore) Save(ctx context.Context, subs map[int64][]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	f.Subscriptions = subs
+	return s.write(f)
+}
+
+// LoadPreferences reads the chat preferences. A missing file holds none.
+func (s *FileSubscriptionStore) LoadPreferences(ctx context.Context) (map[int64]ports.ChatPreferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	prefs := make(map[int64]ports.ChatPreferences, len(f.Preferences))
+	for chat, p := range f.Preferences {
+		prefs[chat] = ports.ChatPreferences{Locale: p.Locale, Timezone: p.Timezone}
+	}
+	return prefs, nil
+}
+
+// SavePreferences replaces the chat preferences, keeping the stored
+// subscriptions.
+func (s *FileSubscriptionStore) SavePreferences(ctx context.Context, prefs map[int64]ports.ChatPreferences) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	f.Preferences = make(map[int64]preferenceRecord, len(prefs))
+	for chat, p := range prefs {
+		f.Preferences[chat] = preferenceRecord{Locale: p.Locale, Timezone: p.Timezone}
+	}
+	return s.write(f)
+}
+
+// read decodes the file. A missing file is empty.
+func (s *FileSubscriptionStore) read() (subscriptionFile, error) {
+	var f subscriptionFile
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, fmt.Errorf("read subscriptions: %w", err)
+	}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return f, fmt.Errorf("decode subscriptions: %w", err)
+	}
+	return f, nil
+}
+
+// write replaces the file with f.
+func (s *FileSubscriptionStore) write(f subscriptionFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode subscriptions: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write subscriptions: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write subscriptions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write subscriptions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write subscriptions: %w", err)
+	}
+	return nil
+}
+
+var (
+	_ ports.SubscriptionStore = (*FileSubscriptionStore)(nil)
+	_ ports.PreferenceStore   = (*FileSubscriptionStore)(nil)
+)
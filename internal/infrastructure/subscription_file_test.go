@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestFileSubscriptionStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "subscriptions.json")
+	store := NewFileSubscriptionStore(path)
+
+	got, err := store.Load(ctx)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected empty subscriptions from a missing file, got %v, %v", got, err)
+	}
+
+	subs := map[int64][]string{-100: {"EURUSD", "GBPUSD"}, 42: {"USDJPY"}}
+	if err := store.Save(ctx, subs); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := store.Save(ctx, subs); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	got, err = NewFileSubscriptionStore(path).Load(ctx)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !reflect.DeepEqual(got, subs) {
+		t.Fatalf("expected %v, got %v", subs, got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected temporary files to be cleaned up, got %v", entries)
+	}
+
+	prefs := map[int64]ports.ChatPreferences{-100: {Locale: "fr", Timezone: "Europe/Paris"}, 42: {Locale: "pt"}}
+	if err := store.SavePreferences(ctx, prefs); err != nil {
+		t.Fatalf("save preferences: %v", err)
+	}
+	if err := store.Save(ctx, subs); err != nil {
+		t.Fatalf("save after preferences: %v", err)
+	}
+	gotPrefs, err := NewFileSubscriptionStore(path).LoadPreferences(ctx)
+	if err != nil || !reflect.DeepEqual(gotPrefs, prefs) {
+		t.Fatalf("expected preferences %v to survive a subscription save, got %v, %v", prefs, gotPrefs, err)
+	}
+	if got, _ := store.Load(ctx); !reflect.DeepEqual(got, subs) {
+		t.Fatalf("expected subscriptions %v to survive a preference save, got %v", subs, got)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(ctx); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
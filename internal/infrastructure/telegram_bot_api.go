@@ -0,0 +1,293 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// maxCaptionLength is the longest photo caption the Bot API accepts.
+const maxCaptionLength = 1024
+
+// TelegramBotOptions configures a TelegramBotAPI.
+type TelegramBotOptions struct {
+	Token string
+	// BaseURL defaults to https://api.telegram.org. Tests point it at a
+	// local stand-in.
+	BaseURL string
+	// Client performs the requests. Its timeout must exceed the long-poll
+	// timeout passed to Updates. Defaults to an http.Client with a 90s
+	// timeout.
+	Client *http.Client
+}
+
+// telegramUpdate is the subset of a Bot API Update used by the bot.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From *struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+	} `json:"message"`
+}
+
+// botMessage converts u. Updates without a message have an empty Text.
+func (u telegramUpdate) botMessage() ports.BotMessage {
+	m := ports.BotMessage{UpdateID: u.UpdateID}
+	if u.Message == nil {
+		return m
+	}
+	m.ChatID, m.Text = u.Message.Chat.ID, u.Message.Text
+	if u.Message.From != nil {
+		m.UserID = u.Message.From.ID
+	}
+	return m
+}
+
+// TelegramBotAPI implements ports.TelegramBot over the Telegram Bot API.
+type TelegramBotAPI struct {
+	opts TelegramBotOptions
+}
+
+// NewTelegramBotAPI returns a Bot API client for opts.Token.
+func NewTelegramBotAPI(opts TelegramBotOptions) (*TelegramBotAPI, error) {
+	if opts.Token == "" {
+		return nil, fmt.Errorf("telegram bot: token is required")
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.telegram.org"
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 90 * time.Second}
+	}
+	return &TelegramBotAPI{opts: opts}, nil
+}
+
+// Updates long-polls getUpdates. Updates other than text messages are
+// returned with an empty Text so that the offset still advances past them.
+func (t *TelegramBotAPI) Updates(ctx context.Context, offset int64, timeout time.Duration) ([]ports.BotMessage, error) {
+	var updates []telegramUpdate
+	err := t.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout / time.Second),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]ports.BotMessage, len(updates))
+	for i, u := range updates {
+		msgs[i] = u.botMessage()
+	}
+	return msgs, nil
+}
+
+// SendMessage sends text as a plain-text message.
+func (t *TelegramBotAPI) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return t.SendFormatted(ctx, chatID, text, "")
+}
+
+// SendFormatted sends text with parseMode, or as plain text when parseMode
+// is empty.
+func (t *TelegramBotAPI) SendFormatted(ctx context.Context, chatID int64, text, parseMode string) error {
+	params := map[string]any{"chat_id": chatID, "text": text}
+	if parseMode != "" {
+		params["parse_mode"] = parseMode
+	}
+	return t.call(ctx, "sendMessage", params, nil)
+}
+
+// SendPhoto uploads photo, a PNG image, with caption parsed with parseMode.
+// Captions longer than Telegram's limit follow the photo as a separate
+// message.
+func (t *TelegramBotAPI) SendPhoto(ctx context.Context, chatID int64, photo []byte, caption, parseMode string) error {
+	long := utf8.RuneCountInString(caption) > maxCaptionLength
+	fields := map[string]string{"chat_id": strconv.FormatInt(chatID, 10)}
+	if caption != "" && !long {
+		fields["caption"] = caption
+		if parseMode != "" {
+			fields["parse_mode"] = parseMode
+		}
+	}
+	if err := t.upload(ctx, "sendPhoto", fields, "photo", "chart.png", photo); err != nil {
+		return err
+	}
+	if long {
+		return t.SendFormatted(ctx, chatID, caption, parseMode)
+	}
+	return nil
+}
+
+// Chat returns a ports.TelegramPublisher sending plain text to chatID, for
+// use with TelegramSink. It implements ports.FormattedPublisher, through
+// which TelegramSink sends with the parse mode of its templates.
+func (t *TelegramBotAPI) Chat(chatID int64) ports.TelegramPublisher {
+	return telegramChat{api: t, chatID: chatID}
+}
+
+// FormattedChat behaves like Chat but sends PublishMessages and PublishPhoto
+// with parseMode.
+func (t *TelegramBotAPI) FormattedChat(chatID int64, parseMode string) ports.TelegramPublisher {
+	return telegramChat{api: t, chatID: chatID, parseMode: parseMode}
+}
+
+// call invokes a Bot API method and decodes its result into out when set.
+func (t *TelegramBotAPI) call(ctx context.Context, method string, params any, out any) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", method, err)
+	}
+	return t.post(ctx, method, "application/json", bytes.NewReader(b), out)
+}
+
+// upload invokes a Bot API method with fields and one file as
+// multipart/form-data.
+func (t *TelegramBotAPI) upload(ctx context.Context, method string, fields map[string]string, field, filename string, file []byte) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		if err := mw.WriteField(k, fields[k]); err != nil {
+			return fmt.Errorf("encode %s: %w", method, err)
+		}
+	}
+	fw, err := mw.CreateFormFile(field, filename)
+	if err == nil {
+		_, err = fw.Write(file)
+	}
+	if err == nil {
+		err = mw.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", method, err)
+	}
+	return t.post(ctx, method, mw.FormDataContentType(), &body, nil)
+}
+
+// post sends body to a Bot API method and decodes its result into out when
+// set.
+func (t *TelegramBotAPI) post(ctx context.Context, method, contentType string, payload io.Reader, out any) error {
+	endpoint := fmt.Sprintf("%s/bot%s/%s", t.opts.BaseURL, t.opts.Token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, payload)
+	if err != nil {
+		return fmt.Errorf("build %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := t.opts.Client.Do(req)
+	if err != nil {
+		// The URL embeds the token; report the method only.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("telegram %s: request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("telegram %s: status %d: decode response: %w", method, resp.StatusCode, err)
+	}
+	if !body.OK {
+		return fmt.Errorf("telegram %s: status %d: %s", method, resp.StatusCode, body.Description)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body.Result, out); err != nil {
+			return fmt.Errorf("telegram %s: decode result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// telegramChat publishes messages to a single chat.
+type telegramChat struct {
+	api       *TelegramBotAPI
+	chatID    int64
+	parseMode string
+}
+
+// PublishMessages sends each message in order and stops at the first error,
+// reporting how many were sent in a *ports.PartialPublishError.
+func (c telegramChat) PublishMessages(ctx context.Context, msgs []string) error {
+	return c.PublishFormatted(ctx, msgs, c.parseMode)
+}
+
+// PublishFormatted behaves like PublishMessages but parses msgs with
+// parseMode instead of the chat's own.
+func (c telegramChat) PublishFormatted(ctx context.Context, msgs []string, parseMode string) error {
+	for i, m := range msgs {
+		if err := c.api.SendFormatted(ctx, c.chatID, m, parseMode); err != nil {
+			return &ports.PartialPublishError{Sent: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// PublishPhoto sends photo with caption.
+func (c telegramChat) PublishPhoto(ctx context.Context, photo []byte, caption string) error {
+	return c.PublishFormattedPhoto(ctx, photo, caption, c.parseMode)
+}
+
+// PublishFormattedPhoto sends photo with caption parsed with parseMode.
+func (c telegramChat) PublishFormattedPhoto(ctx context.Context, photo []byte, caption, parseMode string) error {
+	return c.api.SendPhoto(ctx, c.chatID, photo, caption, parseMode)
+}
+
+// NewTelegramWebhookHandler returns an http.Handler for Bot API webhook
+// updates that passes text messages to handle. When secretToken is set,
+// requests must carry it in the X-Telegram-Bot-Api-Secret-Token header, as
+// configured with setWebhook.
+func NewTelegramWebhookHandler(logger *slog.Logger, secretToken string, handle func(context.Context, ports.BotMessage)) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		got := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if secretToken != "" && subtle.ConstantTimeCompare([]byte(got), []byte(secretToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var u telegramUpdate
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			logger.WarnContext(r.Context(), "decode telegram update", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if m := u.botMessage(); m.Text != "" {
+			handle(r.Context(), m)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+var (
+	_ ports.TelegramBot        = (*TelegramBotAPI)(nil)
+	_ ports.TelegramPublisher  = telegramChat{}
+	_ ports.PhotoPublisher     = telegramChat{}
+	_ ports.FormattedPublisher = telegramChat{}
+)
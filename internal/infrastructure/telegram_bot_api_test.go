@@ -0,0 +1,210 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// fakeBotAPI is a local stand-in for the Telegram Bot API.
+type fakeBotAPI struct {
+	t       *testing.T
+	token   string
+	updates string
+
+	mu     sync.Mutex
+	params []map[string]any
+	sent   []map[string]any
+}
+
+func (f *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+f.token+"/")
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+		return
+	}
+	var params map[string]any
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		params = f.formParams(r)
+	} else if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		f.t.Errorf("decode %s: %v", method, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch method {
+	case "getUpdates":
+		f.params = append(f.params, params)
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + f.updates + `}`))
+	case "sendMessage", "sendPhoto":
+		if params["chat_id"] == float64(404) || params["chat_id"] == "404" || params["text"] == "reject" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		f.sent = append(f.sent, params)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":404,"description":"Not Found"}`))
+	}
+}
+
+// formParams returns the fields of a multipart request, with uploaded files
+// as strings.
+func (f *fakeBotAPI) formParams(r *http.Request) map[string]any {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		f.t.Errorf("parse form: %v", err)
+		return nil
+	}
+	params := make(map[string]any)
+	for k, v := range r.MultipartForm.Value {
+		params[k] = v[0]
+	}
+	for k, files := range r.MultipartForm.File {
+		file, err := files[0].Open()
+		if err != nil {
+			f.t.Errorf("open %s: %v", k, err)
+			continue
+		}
+		b, _ := io.ReadAll(file)
+		file.Close()
+		params[k] = string(b)
+	}
+	return params
+}
+
+func TestTelegramBotAPI(t *testing.T) {
+	fake := &fakeBotAPI{t: t, token: "123:abc", updates: `[
+		{"update_id":5,"message":{"text":"/status","chat":{"id":-100},"from":{"id":7}}},
+		{"update_id":6,"edited_message":{"text":"ignored"}},
+		{"update_id":7,"message":{"chat":{"id":-100},"from":{"id":7},"sticker":{}}}]`}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	api, err := NewTelegramBotAPI(TelegramBotOptions{Token: "123:abc", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	msgs, err := api.Updates(ctx, 5, 30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ports.BotMessage{{UpdateID: 5, ChatID: -100, UserID: 7, Text: "/status"}, {UpdateID: 6}, {UpdateID: 7, ChatID: -100, UserID: 7}}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Fatalf("expected %+v, got %+v", want, msgs)
+	}
+	if p := fake.params[0]; p["offset"] != float64(5) || p["timeout"] != float64(30) {
+		t.Fatalf("unexpected getUpdates params %v", p)
+	}
+
+	if err := api.Chat(-100).PublishMessages(ctx, []string{"one", "two"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.sent) != 2 || fake.sent[1]["text"] != "two" || fake.sent[1]["chat_id"] != float64(-100) {
+		t.Fatalf("unexpected messages %v", fake.sent)
+	}
+	if _, ok := fake.sent[1]["parse_mode"]; ok {
+		t.Fatalf("expected plain text, got %v", fake.sent[1])
+	}
+	if err := api.FormattedChat(-100, "MarkdownV2").PublishMessages(ctx, []string{"*three*"}); err != nil {
+		t.Fatal(err)
+	}
+	if p := fake.sent[2]; p["text"] != "*three*" || p["parse_mode"] != "MarkdownV2" {
+		t.Fatalf("unexpected formatted message %v", p)
+	}
+	if err := api.FormattedChat(-100, "HTML").(ports.PhotoPublisher).PublishPhoto(ctx, []byte("png"), "<b>four</b>"); err != nil {
+		t.Fatal(err)
+	}
+	photo := map[string]any{"chat_id": "-100", "photo": "png", "caption": "<b>four</b>", "parse_mode": "HTML"}
+	if p := fake.sent[3]; !reflect.DeepEqual(p, photo) {
+		t.Fatalf("expected photo %v, got %v", photo, p)
+	}
+	long := strings.Repeat("x", 1025)
+	if err := api.SendPhoto(ctx, -100, []byte("png"), long, ""); err != nil {
+		t.Fatal(err)
+	}
+	if p := fake.sent[4]; p["caption"] != nil || p["photo"] != "png" {
+		t.Fatalf("expected a photo without caption, got %v", p)
+	}
+	if p := fake.sent[5]; p["text"] != long {
+		t.Fatalf("expected the long caption as a message, got %v", p)
+	}
+	if err := api.SendPhoto(ctx, 404, []byte("png"), "", ""); err == nil || !strings.Contains(err.Error(), "sendPhoto") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+	if err := api.SendMessage(ctx, 404, "x"); err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+	var partial *ports.PartialPublishError
+	if err := api.Chat(-100).PublishMessages(ctx, []string{"five", "reject", "six"}); !errors.As(err, &partial) || partial.Sent != 1 {
+		t.Fatalf("expected a partial publish after 1 message, got %v", err)
+	}
+	if n := len(fake.sent); fake.sent[n-1]["text"] != "five" {
+		t.Fatalf("expected to stop at the rejected message, got %v", fake.sent[n-1])
+	}
+	formatted := api.FormattedChat(-100, "HTML").(ports.FormattedPublisher)
+	if err := formatted.PublishFormatted(ctx, []string{"*seven*"}, "MarkdownV2"); err != nil {
+		t.Fatal(err)
+	}
+	if p := fake.sent[len(fake.sent)-1]; p["text"] != "*seven*" || p["parse_mode"] != "MarkdownV2" {
+		t.Fatalf("expected the call's parse mode, got %v", p)
+	}
+	if err := formatted.PublishFormattedPhoto(ctx, []byte("png"), "eight.", ""); err != nil {
+		t.Fatal(err)
+	}
+	if p := fake.sent[len(fake.sent)-1]; p["caption"] != "eight." || p["parse_mode"] != nil {
+		t.Fatalf("expected a plain caption, got %v", p)
+	}
+
+	bad, _ := NewTelegramBotAPI(TelegramBotOptions{Token: "wrong", BaseURL: srv.URL})
+	if _, err := bad.Updates(ctx, 0, 0); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+
+	down, _ := NewTelegramBotAPI(TelegramBotOptions{Token: "secret-token", BaseURL: "http://127.0.0.1:1"})
+	if err := down.SendMessage(ctx, 1, "x"); err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("expected a network error without the token, got %v", err)
+	}
+}
+
+func TestTelegramWebhookHandler(t *testing.T) {
+	var got []ports.BotMessage
+	h := NewTelegramWebhookHandler(nil, "s3cret", func(_ context.Context, m ports.BotMessage) { got = append(got, m) })
+
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		status int
+	}{
+		{name: "message", secret: "s3cret", body: `{"update_id":1,"message":{"text":"/pause","chat":{"id":2},"from":{"id":3}}}`, status: http.StatusOK},
+		{name: "no text", secret: "s3cret", body: `{"update_id":2,"channel_post":{}}`, status: http.StatusOK},
+		{name: "wrong secret", secret: "nope", body: `{"update_id":3,"message":{"text":"/pause"}}`, status: http.StatusUnauthorized},
+		{name: "bad json", secret: "s3cret", body: `{`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(tt.body))
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.secret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+		}
+	}
+	if want := []ports.BotMessage{{UpdateID: 1, ChatID: 2, UserID: 3, Text: "/pause"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
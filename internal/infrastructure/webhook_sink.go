@@ -0,0 +1,176 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// WebhookOptions configures a WebhookSink.
+type WebhookOptions struct {
+	URL string
+	// Headers are added to every request, e.g. an Authorization header.
+	Headers map[string]string
+	// Client performs the requests. Defaults to an http.Client with a 10s
+	// timeout.
+	Client *http.Client
+}
+
+// webhookSignal is the JSON representation of a signal sent by WebhookSink.
+type webhookSignal struct {
+	Symbol     string   `json:"symbol"`
+	Direction  string   `json:"direction"`
+	Confidence float64  `json:"confidence"`
+	TTLSeconds int      `json:"ttl_seconds"`
+	Price      float64  `json:"price,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Stage      string   `json:"stage"`
+}
+
+// WebhookSink implements ports.SignalPublisher by POSTing signals as JSON:
+//
+//	{"signals": [{"symbol": "EURUSD", "direction": "UP", "confidence": 0.8,
+//	  "ttl_seconds": 120, "price": 1.0845, "stage": "signal"}]}
+type WebhookSink struct {
+	opts WebhookOptions
+}
+
+// NewWebhookSink returns a sink posting to opts.URL.
+func NewWebhookSink(opts WebhookOptions) *WebhookSink {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookSink{opts: opts}
+}
+
+// PublishSignals posts signals in a single request.
+func (w *WebhookSink) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	payload := struct {
+		Signals []webhookSignal `json:"signals"`
+	}{Signals: make([]webhookSignal, len(signals))}
+	for i, s := range signals {
+		payload.Signals[i] = webhookSignal{
+			Symbol:     s.Symbol,
+			Direction:  s.Direction,
+			Confidence: s.Confidence,
+			TTLSeconds: int(s.TTL / time.Second),
+			Price:      s.Price,
+			Tags:       s.Tags,
+			Stage:      stageName(s.Stage),
+		}
+	}
+	return postJSON(ctx, w.opts.Client, w.opts.URL, w.opts.Headers, payload)
+}
+
+// ChatSinkOptions configures the Slack and Discord sinks.
+type ChatSinkOptions struct {
+	// Format renders one signal. Defaults to a one-line summary using the
+	// channel's markdown.
+	Format func(entity.Signal) string
+	// Client performs the requests. Defaults to an http.Client with a 10s
+	// timeout.
+	Client *http.Client
+}
+
+// ChatWebhookSink implements ports.SignalPublisher for chat services that
+// accept incoming webhooks, posting all signals as one message.
+type ChatWebhookSink struct {
+	url    string
+	field  string
+	format func(entity.Signal) string
+	client *http.Client
+}
+
+// NewSlackSink returns a sink posting to a Slack incoming webhook.
+func NewSlackSink(webhookURL string, opts ChatSinkOptions) *ChatWebhookSink {
+	return newChatWebhookSink(webhookURL, "text", func(s string) string { return "*" + s + "*" }, opts)
+}
+
+// NewDiscordSink returns a sink posting to a Discord channel webhook.
+func NewDiscordSink(webhookURL string, opts ChatSinkOptions) *ChatWebhookSink {
+	return newChatWebhookSink(webhookURL, "content", func(s string) string { return "**" + s + "**" }, opts)
+}
+
+func newChatWebhookSink(url, field string, bold func(string) string, opts ChatSinkOptions) *ChatWebhookSink {
+	if opts.Format == nil {
+		opts.Format = func(s entity.Signal) string { return signalLine(s, bold) }
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &ChatWebhookSink{url: url, field: field, format: opts.Format, client: opts.Client}
+}
+
+// PublishSignals posts signals as a single chat message.
+func (c *ChatWebhookSink) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	lines := make([]string, len(signals))
+	for i, s := range signals {
+		lines[i] = c.format(s)
+	}
+	return postJSON(ctx, c.client, c.url, nil, map[string]string{c.field: strings.Join(lines, "\n")})
+}
+
+// signalLine renders s as a one-line summary, e.g.
+// "⚡ EURUSD UP · 80% · 2m · @ 1.0845".
+func signalLine(s entity.Signal, bold func(string) string) string {
+	var prefix string
+	switch s.Stage {
+	case entity.StageEarly:
+		prefix = "⏳ Early"
+	case entity.StageConfirmed:
+		return fmt.Sprintf("✅ Confirmed %s %s", bold(s.Symbol), s.Direction)
+	case entity.StageCancelled:
+		return fmt.Sprintf("❌ Cancelled %s %s", bold(s.Symbol), s.Direction)
+	default:
+		prefix = "⚡"
+	}
+	minutes := max(int(s.TTL.Round(time.Minute)/time.Minute), 1)
+	line := fmt.Sprintf("%s %s %s · %.0f%% · %dm", prefix, bold(s.Symbol), s.Direction, s.Confidence*100, minutes)
+	if s.Price > 0 {
+		line += fmt.Sprintf(" · @ %g", s.Price)
+	}
+	for _, t := range s.Tags {
+		line += " · ⚠️ " + t
+	}
+	return line
+}
+
+// postJSON POSTs body as JSON and treats any non-2xx status as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+var (
+	_ ports.SignalPublisher = (*WebhookSink)(nil)
+	_ ports.SignalPublisher = (*ChatWebhookSink)(nil)
+)
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestWebhookSinks(t *testing.T) {
+	signals := []entity.Signal{
+		{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8, TTL: 2 * time.Minute, Price: 1.0845, Tags: []string{"news: USD CPI at 13:30 UTC"}},
+		{Symbol: "EURUSD", Direction: "DOWN", Stage: entity.StageCancelled},
+	}
+
+	tests := []struct {
+		name string
+		sink func(url string) ports.SignalPublisher
+		want string
+	}{
+		{
+			name: "webhook",
+			sink: func(url string) ports.SignalPublisher {
+				return NewWebhookSink(WebhookOptions{URL: url, Headers: map[string]string{"Authorization": "Bearer t"}})
+			},
+			want: `{"signals":[` +
+				`{"symbol":"EURUSD","direction":"UP","confidence":0.8,"ttl_seconds":120,"price":1.0845,"tags":["news: USD CPI at 13:30 UTC"],"stage":"signal"},` +
+				`{"symbol":"EURUSD","direction":"DOWN","confidence":0,"ttl_seconds":0,"stage":"cancelled"}]}`,
+		},
+		{
+			name: "slack",
+			sink: func(url string) ports.SignalPublisher { return NewSlackSink(url, ChatSinkOptions{}) },
+			want: `{"text":"⚡ *EURUSD* UP · 80% · 2m · @ 1.0845 · ⚠️ news: USD CPI at 13:30 UTC\n❌ Cancelled *EURUSD* DOWN"}`,
+		},
+		{
+			name: "discord",
+			sink: func(url string) ports.SignalPublisher {
+				return NewDiscordSink(url, ChatSinkOptions{Format: func(s entity.Signal) string { return s.Symbol + " " + s.Direction }})
+			},
+			want: `{"content":"EURUSD UP\nEURUSD DOWN"}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			var auth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				body, auth = string(b), r.Header.Get("Authorization")
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+			defer srv.Close()
+
+			if err := tt.sink(srv.URL).PublishSignals(context.Background(), signals); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+			if !jsonEqual(t, body, tt.want) {
+				t.Fatalf("expected body %s, got %s", tt.want, body)
+			}
+			if tt.name == "webhook" && auth != "Bearer t" {
+				t.Fatalf("expected custom header, got %q", auth)
+			}
+		})
+	}
+}
+
+func TestWebhookSink_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	err := NewSlackSink(srv.URL, ChatSinkOptions{}).PublishSignals(context.Background(), []entity.Signal{{Symbol: "EURUSD", Direction: "UP"}})
+	if err == nil || !strings.Contains(err.Error(), "429") || !strings.Contains(err.Error(), "rate limited") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func jsonEqual(t *testing.T, a, b string) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		t.Fatalf("decode %s: %v", a, err)
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		t.Fatalf("decode %s: %v", b, err)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// wsFeed adds WebSocket dialing and the read loop to feedCore.
+type wsFeed struct {
+	*feedCore
+	dialer *websocket.Dialer
+}
+
+func newWSFeed(provider string, logger *slog.Logger, dialer *websocket.Dialer, backoff ports.BackoffStrategy, metrics ports.MetricsRecorder) *wsFeed {
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	return &wsFeed{feedCore: newFeedCore(provider, logger, backoff, metrics), dialer: dialer}
+}
+
+// wsProtocol describes the provider-specific parts of a WebSocket session.
+type wsProtocol struct {
+	// endpoint is the URL dialed on every connection attempt.
+	endpoint string
+	// subscribe is called once the connection is established.
+	subscribe func(conn *websocket.Conn) error
+	// decode converts a message into zero or more candles. Control messages
+	// return no candles and no error; errors wrapping errSessionRejected end
+	// the session and back off before reconnecting.
+	decode func(msg []byte) ([]ports.Candle, error)
+}
+
+// run connects, subscribes and forwards decoded candles to out, reconnecting
+// with backoff on failure and when the stream goes stale. Candles that are
+// invalid, older than maxCandleAge or repeat the previous timestamp for their
+// symbol are dropped.
+func (f *wsFeed) run(ctx context.Context, p wsProtocol, out chan ports.Candle) {
+	defer close(out)
+	defer f.health.setState(ports.FeedStopped)
+
+	lastTS := make(map[string]time.Time)
+	var r retrier
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		f.connecting(ctx, &r)
+
+		conn, _, err := f.dialer.DialContext(ctx, p.endpoint, nil)
+		if err != nil {
+			f.logger.ErrorContext(ctx, "connection failed", "provider", f.provider, "error", err)
+			if !f.wait(ctx, &r) {
+				return
+			}
+			continue
+		}
+
+		r.retries = 0
+		if err := p.subscribe(conn); err != nil {
+			f.logger.ErrorContext(ctx, "subscription failed", "provider", f.provider, "error", err)
+			conn.Close()
+			if !f.wait(ctx, &r) {
+				return
+			}
+			continue
+		}
+		f.health.setState(ports.FeedSubscribed)
+
+		switch f.read(ctx, conn, p, lastTS, out) {
+		case sessionStop:
+			return
+		case sessionRejected:
+			if !f.wait(ctx, &r) {
+				return
+			}
+		}
+	}
+}
+
+// read consumes messages until the connection fails, goes stale or is
+// rejected by the provider.
+func (f *wsFeed) read(ctx context.Context, conn *websocket.Conn, p wsProtocol, lastTS map[string]time.Time, out chan ports.Candle) sessionEnd {
+	defer conn.Close()
+	lastRecv := f.now()
+
+	for {
+		if ctx.Err() != nil {
+			return sessionStop
+		}
+
+		conn.SetReadDeadline(time.Now().Add(f.readDeadline))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			f.logger.ErrorContext(ctx, "read error", "provider", f.provider, "error", err)
+			return sessionReconnect
+		}
+		f.health.update(func(c *ports.FeedCounters) { c.Received++ })
+
+		candles, err := p.decode(message)
+		if errors.Is(err, errSessionRejected) {
+			f.logger.ErrorContext(ctx, "session rejected", "provider", f.provider, "error", err)
+			return sessionRejected
+		}
+		if err != nil {
+			f.logger.ErrorContext(ctx, "decode error", "provider", f.provider, "error", err)
+			f.health.update(func(c *ports.FeedCounters) { c.DroppedInvalid++ })
+			continue
+		}
+		f.health.update(func(c *ports.FeedCounters) { c.Decoded++ })
+
+		emitted := false
+		for _, c := range candles {
+			c.Symbol = f.instruments.Canonical(c.Symbol)
+			if !f.accept(c, lastTS) {
+				continue
+			}
+			select {
+			case out <- c:
+				emitted = true
+			case <-ctx.Done():
+				return sessionStop
+			}
+		}
+		if !emitted {
+			continue
+		}
+
+		if f.now().Sub(lastRecv) > f.staleAfter {
+			f.logger.WarnContext(ctx, "stale stream detected, reconnecting", "provider", f.provider)
+			f.health.setState(ports.FeedStale)
+			return sessionReconnect
+		}
+		lastRecv = f.now()
+	}
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetter records messages that could not be delivered.
+type DeadLetter struct {
+	// Time is when delivery was abandoned.
+	Time     time.Time
+	Symbol   string
+	Messages []string
+	Attempts int
+	// Error is the last publish error.
+	Error string
+}
+
+// DeadLetterStore keeps undelivered messages for later inspection.
+type DeadLetterStore interface {
+	// Store persists a dead letter.
+	Store(ctx context.Context, dl DeadLetter) error
+	// List returns the stored dead letters, oldest first.
+	List(ctx context.Context) ([]DeadLetter, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// EconomicCalendarPort supplies scheduled economic releases.
+type EconomicCalendarPort interface {
+	// Events returns the events scheduled in [from, to), ordered by time.
+	Events(ctx context.Context, from, to time.Time) ([]entity.EconomicEvent, error)
+}
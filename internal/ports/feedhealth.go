@@ -0,0 +1,61 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// FeedState describes the connection state of a market feed.
+type FeedState string
+
+// Market feed connection states.
+const (
+	FeedIdle       FeedState = "idle"
+	FeedConnecting FeedState = "connecting"
+	FeedSubscribed FeedState = "subscribed"
+	FeedStale      FeedState = "stale"
+	FeedBackingOff FeedState = "backing_off"
+	FeedStopped    FeedState = "stopped"
+)
+
+// FeedStateChange records a transition between two feed states.
+type FeedStateChange struct {
+	From FeedState
+	To   FeedState
+	At   time.Time
+}
+
+// FeedCounters holds cumulative message counters for a market feed.
+type FeedCounters struct {
+	Received         uint64
+	Decoded          uint64
+	DroppedStale     uint64
+	DroppedDuplicate uint64
+	DroppedInvalid   uint64
+	Reconnects       uint64
+	Failovers        uint64
+}
+
+// FeedHealth is a point-in-time snapshot of a market feed's health.
+type FeedHealth struct {
+	State       FeedState
+	Since       time.Time
+	Counters    FeedCounters
+	LastMessage map[string]time.Time
+}
+
+// Healthy reports whether the feed is subscribed and delivering data.
+func (h FeedHealth) Healthy() bool {
+	return h.State == FeedSubscribed
+}
+
+// FeedHealthReporter is implemented by market feeds that expose their
+// connection state and counters.
+type FeedHealthReporter interface {
+	// FeedHealth returns a snapshot of the current state and counters.
+	FeedHealth() FeedHealth
+	// StateChanges returns a channel receiving state transitions until ctx is
+	// canceled, after which the channel is closed. Transitions are dropped for
+	// receivers that fall behind.
+	StateChanges(ctx context.Context) <-chan FeedStateChange
+}
@@ -14,15 +14,29 @@ type Candle struct {
 	Low    float64
 	Close  float64
 	Volume float64
+	// Spread is the last quoted ask minus bid in the bar, zero when the feed
+	// does not provide quotes.
+	Spread float64
+	// Partial marks an update of a bar that is still forming. The final,
+	// closed candle for the same Time supersedes it.
+	Partial bool
 }
 
 // MarketFeedPort streams candles for the given symbols.
 type MarketFeedPort interface {
 	// StreamCandles returns a channel emitting 1-minute candles for each symbol.
-	// The channel is closed when the context is canceled or an error occurs.
+	// Feeds may also emit Partial updates before a bar closes; consumers that
+	// only want closed bars skip them. The channel is closed when the context
+	// is canceled or an error occurs.
 	StreamCandles(ctx context.Context, symbols []string) (<-chan Candle, error)
 }
 
+// CandleRecorder persists closed candles so that a live session can be
+// replayed later, e.g. to compare it with a backtest.
+type CandleRecorder interface {
+	RecordCandle(ctx context.Context, c Candle) error
+}
+
 // MarketFeedAdapter represents a provider-specific implementation of a
 // market feed.
 type MarketFeedAdapter interface {
@@ -0,0 +1,58 @@
+package ports
+
+import "time"
+
+// MetricsRecorder records instrumentation for the signal pipeline.
+type MetricsRecorder interface {
+	// CandleReceived counts a candle consumed for symbol.
+	CandleReceived(symbol string)
+	// BufferSize reports the number of candles buffered for symbol.
+	BufferSize(symbol string, n int)
+	// IndicatorLatency observes the time spent computing indicators.
+	IndicatorLatency(d time.Duration)
+	// ScanLatency observes the time spent scanning for signal patterns.
+	ScanLatency(d time.Duration)
+	// SignalProduced counts a signal emitted by scorer in direction.
+	SignalProduced(scorer, direction string)
+	// SignalSuppressed counts a signal dropped by rate limiting for reason.
+	SignalSuppressed(reason string)
+	// SignalSettled counts a published signal settled with outcome "WIN",
+	// "LOSS" or "NEUTRAL".
+	SignalSettled(symbol, outcome string)
+	// PublishResult counts a publish attempt and whether it succeeded.
+	PublishResult(ok bool)
+	// FeedReconnect counts a reconnect of the named feed provider.
+	FeedReconnect(provider string)
+}
+
+// NopMetrics is a MetricsRecorder that discards all observations.
+type NopMetrics struct{}
+
+// CandleReceived implements MetricsRecorder.
+func (NopMetrics) CandleReceived(string) {}
+
+// BufferSize implements MetricsRecorder.
+func (NopMetrics) BufferSize(string, int) {}
+
+// IndicatorLatency implements MetricsRecorder.
+func (NopMetrics) IndicatorLatency(time.Duration) {}
+
+// ScanLatency implements MetricsRecorder.
+func (NopMetrics) ScanLatency(time.Duration) {}
+
+// SignalProduced implements MetricsRecorder.
+func (NopMetrics) SignalProduced(string, string) {}
+
+// SignalSuppressed implements MetricsRecorder.
+func (NopMetrics) SignalSuppressed(string) {}
+
+// SignalSettled implements MetricsRecorder.
+func (NopMetrics) SignalSettled(string, string) {}
+
+// PublishResult implements MetricsRecorder.
+func (NopMetrics) PublishResult(bool) {}
+
+// FeedReconnect implements MetricsRecorder.
+func (NopMetrics) FeedReconnect(string) {}
+
+var _ MetricsRecorder = NopMetrics{}
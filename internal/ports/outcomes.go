@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// OutcomeStore persists the outcomes of live signals.
+type OutcomeStore interface {
+	// Store persists an outcome.
+	Store(ctx context.Context, o entity.SignalOutcome) error
+	// List returns the outcomes that expired at or after since, oldest
+	// first.
+	List(ctx context.Context, since time.Time) ([]entity.SignalOutcome, error)
+}
+
+// OutcomePublisher is implemented by SignalPublishers that can send
+// follow-up messages reporting how their signals settled.
+type OutcomePublisher interface {
+	// PublishOutcomes delivers settled outcomes, which all belong to one
+	// symbol.
+	PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error
+}
@@ -0,0 +1,38 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// SignalPublisher delivers structured signals to a notification channel.
+// Implementations format signals for their channel.
+type SignalPublisher interface {
+	// PublishSignals delivers signals, which all belong to one symbol.
+	PublishSignals(ctx context.Context, signals []entity.Signal) error
+}
+
+// SignalPublisherFunc adapts a function to SignalPublisher.
+type SignalPublisherFunc func(ctx context.Context, signals []entity.Signal) error
+
+// PublishSignals calls f.
+func (f SignalPublisherFunc) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	return f(ctx, signals)
+}
+
+// ChartPublisher is implemented by SignalPublishers that can attach a chart
+// image to each signal.
+type ChartPublisher interface {
+	// PublishSignalCharts behaves like PublishSignals and attaches charts[i],
+	// a PNG image, to signals[i]. Signals without a chart are sent as usual.
+	PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error
+}
+
+// DigestPublisher is implemented by SignalPublishers that can summarise
+// several signals in one message.
+type DigestPublisher interface {
+	// PublishDigest delivers signals, which may belong to several symbols,
+	// as a single summary.
+	PublishDigest(ctx context.Context, signals []entity.Signal) error
+}
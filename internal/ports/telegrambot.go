@@ -0,0 +1,56 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// BotMessage is a text message received by the Telegram bot.
+type BotMessage struct {
+	// UpdateID orders updates; polling resumes after the last one seen.
+	UpdateID int64
+	ChatID   int64
+	// UserID is the sender, used to authorise admin commands.
+	UserID int64
+	// Text is empty for updates other than text messages.
+	Text string
+}
+
+// TelegramBot receives and sends messages through the Telegram Bot API.
+type TelegramBot interface {
+	// Updates waits up to timeout for messages with an UpdateID of at least
+	// offset.
+	Updates(ctx context.Context, offset int64, timeout time.Duration) ([]BotMessage, error)
+	// SendMessage sends text to a chat.
+	SendMessage(ctx context.Context, chatID int64, text string) error
+	// SendFormatted sends text parsed with a Telegram parse mode, e.g.
+	// "MarkdownV2" or "HTML".
+	SendFormatted(ctx context.Context, chatID int64, text, parseMode string) error
+	// SendPhoto sends a PNG image with a caption parsed with parseMode.
+	SendPhoto(ctx context.Context, chatID int64, photo []byte, caption, parseMode string) error
+}
+
+// ChatPreferences are a chat's message settings.
+type ChatPreferences struct {
+	// Locale is a locale pack code such as "fr". Empty uses the default.
+	Locale string
+	// Timezone is an IANA zone name such as "Europe/Paris". Empty uses the
+	// default.
+	Timezone string
+}
+
+// PreferenceStore persists chat preferences.
+type PreferenceStore interface {
+	// LoadPreferences returns the preferences by chat ID.
+	LoadPreferences(ctx context.Context) (map[int64]ChatPreferences, error)
+	// SavePreferences replaces the stored preferences.
+	SavePreferences(ctx context.Context, prefs map[int64]ChatPreferences) error
+}
+
+// SubscriptionStore persists the symbols each chat subscribed to.
+type SubscriptionStore interface {
+	// Load returns the subscribed symbols by chat ID.
+	Load(ctx context.Context) (map[int64][]string, error)
+	// Save replaces the stored subscriptions.
+	Save(ctx context.Context, subs map[int64][]string) error
+}
@@ -4,6 +4,38 @@ import "context"
 
 // TelegramPublisher publishes messages to Telegram.
 type TelegramPublisher interface {
-	// PublishMessages sends the provided messages as Telegram alerts.
+	// PublishMessages sends the provided messages as Telegram alerts. When it
+	// fails after sending some of them, it returns a *PartialPublishError.
 	PublishMessages(ctx context.Context, msgs []string) error
 }
+
+// PartialPublishError reports a batch that failed partway. Sent counts the
+// messages delivered, in order, before Err.
+type PartialPublishError struct {
+	Sent int
+	Err  error
+}
+
+func (e *PartialPublishError) Error() string { return e.Err.Error() }
+
+// Unwrap returns Err.
+func (e *PartialPublishError) Unwrap() error { return e.Err }
+
+// PhotoPublisher is implemented by TelegramPublishers that can send images.
+type PhotoPublisher interface {
+	// PublishPhoto sends a PNG image with caption as a single message.
+	PublishPhoto(ctx context.Context, photo []byte, caption string) error
+}
+
+// FormattedPublisher is implemented by TelegramPublishers that take the parse
+// mode per call, so that it always matches the markup the text was escaped
+// for.
+type FormattedPublisher interface {
+	// PublishFormatted behaves like PublishMessages but parses msgs with
+	// parseMode, e.g. "MarkdownV2", or sends them as plain text when it is
+	// empty.
+	PublishFormatted(ctx context.Context, msgs []string, parseMode string) error
+	// PublishFormattedPhoto behaves like PhotoPublisher.PublishPhoto but
+	// parses caption with parseMode.
+	PublishFormattedPhoto(ctx context.Context, photo []byte, caption, parseMode string) error
+}
@@ -0,0 +1,63 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLateTick is returned by CandleBuilder.Add for ticks whose bar has
+// already closed.
+var ErrLateTick = errors.New("late tick")
+
+// Tick represents a single price update for a symbol. Bid and Ask are zero
+// for trade-only feeds and Last is zero for quote-only feeds.
+type Tick struct {
+	Symbol string
+	Time   time.Time
+	Bid    float64
+	Ask    float64
+	Last   float64
+	Volume float64
+}
+
+// Spread returns Ask minus Bid, or zero unless both sides are quoted.
+func (t Tick) Spread() float64 {
+	if t.Bid > 0 && t.Ask > 0 {
+		return t.Ask - t.Bid
+	}
+	return 0
+}
+
+// Price returns Last when set, otherwise the bid/ask midpoint, falling back to
+// whichever side is quoted.
+func (t Tick) Price() float64 {
+	switch {
+	case t.Last > 0:
+		return t.Last
+	case t.Bid > 0 && t.Ask > 0:
+		return (t.Bid + t.Ask) / 2
+	case t.Bid > 0:
+		return t.Bid
+	default:
+		return t.Ask
+	}
+}
+
+// TickFeedPort streams ticks for the given symbols.
+type TickFeedPort interface {
+	// StreamTicks returns a channel emitting ticks for each symbol. The channel
+	// is closed when the context is canceled or an error occurs.
+	StreamTicks(ctx context.Context, symbols []string) (<-chan Tick, error)
+}
+
+// CandleBuilder aggregates ticks into candles. usecase.TickAggregator
+// implements it, so tick-based feeds share its bar boundaries, grace and
+// late-tick policy.
+type CandleBuilder interface {
+	// Add applies t and returns the candles it closed. It returns
+	// ErrLateTick for ticks dropped because their bar has closed.
+	Add(t Tick) ([]Candle, error)
+	// Flush closes every bar that ended at or before now.
+	Flush(now time.Time) []Candle
+}
@@ -0,0 +1,36 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockCandleRecorder keeps recorded candles in memory and can fail.
+type MockCandleRecorder struct {
+	Err error
+
+	mu      sync.Mutex
+	candles []ports.Candle
+}
+
+// RecordCandle appends c unless Err is set.
+func (m *MockCandleRecorder) RecordCandle(ctx context.Context, c ports.Candle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.candles = append(m.candles, c)
+	return nil
+}
+
+// Candles returns the recorded candles.
+func (m *MockCandleRecorder) Candles() []ports.Candle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ports.Candle(nil), m.candles...)
+}
+
+var _ ports.CandleRecorder = (*MockCandleRecorder)(nil)
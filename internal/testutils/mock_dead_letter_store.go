@@ -0,0 +1,31 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockDeadLetterStore keeps dead letters in memory.
+type MockDeadLetterStore struct {
+	mu      sync.Mutex
+	letters []ports.DeadLetter
+}
+
+// Store appends dl.
+func (m *MockDeadLetterStore) Store(ctx context.Context, dl ports.DeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.letters = append(m.letters, dl)
+	return nil
+}
+
+// List returns the stored dead letters.
+func (m *MockDeadLetterStore) List(ctx context.Context) ([]ports.DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ports.DeadLetter(nil), m.letters...), nil
+}
+
+var _ ports.DeadLetterStore = (*MockDeadLetterStore)(nil)
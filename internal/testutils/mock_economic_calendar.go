@@ -0,0 +1,45 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockEconomicCalendar serves a fixed event list and counts queries.
+type MockEconomicCalendar struct {
+	List []entity.EconomicEvent
+	Err  error
+
+	mu    sync.Mutex
+	calls int
+}
+
+// Events returns the configured events in [from, to).
+func (m *MockEconomicCalendar) Events(ctx context.Context, from, to time.Time) ([]entity.EconomicEvent, error) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	var out []entity.EconomicEvent
+	for _, ev := range m.List {
+		if !ev.Time.Before(from) && ev.Time.Before(to) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+// Calls returns the number of Events queries.
+func (m *MockEconomicCalendar) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+var _ ports.EconomicCalendarPort = (*MockEconomicCalendar)(nil)
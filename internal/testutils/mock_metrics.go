@@ -0,0 +1,104 @@
+package testutils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockMetrics records observations for assertions in tests.
+type MockMetrics struct {
+	mu                sync.Mutex
+	Candles           map[string]int
+	Buffers           map[string]int
+	IndicatorObserved int
+	ScanObserved      int
+	Signals           map[string]int
+	Suppressed        map[string]int
+	Settled           map[string]int
+	PublishOK         int
+	PublishFailed     int
+	Reconnects        map[string]int
+}
+
+// NewMockMetrics returns an empty MockMetrics.
+func NewMockMetrics() *MockMetrics {
+	return &MockMetrics{
+		Candles:    make(map[string]int),
+		Buffers:    make(map[string]int),
+		Signals:    make(map[string]int),
+		Suppressed: make(map[string]int),
+		Settled:    make(map[string]int),
+		Reconnects: make(map[string]int),
+	}
+}
+
+// CandleReceived implements ports.MetricsRecorder.
+func (m *MockMetrics) CandleReceived(symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Candles[symbol]++
+}
+
+// BufferSize implements ports.MetricsRecorder.
+func (m *MockMetrics) BufferSize(symbol string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Buffers[symbol] = n
+}
+
+// IndicatorLatency implements ports.MetricsRecorder.
+func (m *MockMetrics) IndicatorLatency(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.IndicatorObserved++
+}
+
+// ScanLatency implements ports.MetricsRecorder.
+func (m *MockMetrics) ScanLatency(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ScanObserved++
+}
+
+// SignalProduced implements ports.MetricsRecorder. Keys are "scorer|direction".
+func (m *MockMetrics) SignalProduced(scorer, direction string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Signals[scorer+"|"+direction]++
+}
+
+// SignalSuppressed implements ports.MetricsRecorder.
+func (m *MockMetrics) SignalSuppressed(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Suppressed[reason]++
+}
+
+// SignalSettled implements ports.MetricsRecorder. Keys are "symbol|outcome".
+func (m *MockMetrics) SignalSettled(symbol, outcome string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Settled[symbol+"|"+outcome]++
+}
+
+// PublishResult implements ports.MetricsRecorder.
+func (m *MockMetrics) PublishResult(ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ok {
+		m.PublishOK++
+	} else {
+		m.PublishFailed++
+	}
+}
+
+// FeedReconnect implements ports.MetricsRecorder.
+func (m *MockMetrics) FeedReconnect(provider string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Reconnects[provider]++
+}
+
+var _ ports.MetricsRecorder = (*MockMetrics)(nil)
@@ -0,0 +1,39 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockOutcomeStore keeps outcomes in memory.
+type MockOutcomeStore struct {
+	mu       sync.Mutex
+	outcomes []entity.SignalOutcome
+}
+
+// Store appends o.
+func (m *MockOutcomeStore) Store(ctx context.Context, o entity.SignalOutcome) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes = append(m.outcomes, o)
+	return nil
+}
+
+// List returns the outcomes that expired at or after since.
+func (m *MockOutcomeStore) List(ctx context.Context, since time.Time) ([]entity.SignalOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []entity.SignalOutcome
+	for _, o := range m.outcomes {
+		if !o.ExpiryTime.Before(since) {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+var _ ports.OutcomeStore = (*MockOutcomeStore)(nil)
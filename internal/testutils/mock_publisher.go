@@ -24,4 +24,61 @@ func (m *MockPublisher) PublishMessages(ctx context.Context, msgs []string) erro
 	return nil
 }
 
-var _ ports.TelegramPublisher = (*MockPublisher)(nil)
+// PublishedPhoto is a photo recorded by MockPhotoPublisher.
+type PublishedPhoto struct {
+	Photo   []byte
+	Caption string
+}
+
+// MockPhotoPublisher is a MockPublisher that also accepts photos.
+type MockPhotoPublisher struct {
+	MockPublisher
+	// PhotoErr is returned by PublishPhoto when set.
+	PhotoErr error
+	Photos   []PublishedPhoto
+}
+
+// PublishPhoto records the photo and returns PhotoErr.
+func (m *MockPhotoPublisher) PublishPhoto(ctx context.Context, photo []byte, caption string) error {
+	if m.PhotoErr != nil {
+		return m.PhotoErr
+	}
+	m.Photos = append(m.Photos, PublishedPhoto{Photo: photo, Caption: caption})
+	return nil
+}
+
+// FormattedCall is a message or photo caption recorded by
+// MockFormattedPublisher with its parse mode.
+type FormattedCall struct {
+	Text      string
+	ParseMode string
+	Photo     bool
+}
+
+// MockFormattedPublisher is a MockPhotoPublisher that also records the parse
+// mode of every message.
+type MockFormattedPublisher struct {
+	MockPhotoPublisher
+	Formatted []FormattedCall
+}
+
+// PublishFormatted records msgs with parseMode and publishes them.
+func (m *MockFormattedPublisher) PublishFormatted(ctx context.Context, msgs []string, parseMode string) error {
+	for _, msg := range msgs {
+		m.Formatted = append(m.Formatted, FormattedCall{Text: msg, ParseMode: parseMode})
+	}
+	return m.PublishMessages(ctx, msgs)
+}
+
+// PublishFormattedPhoto records caption with parseMode and publishes the
+// photo.
+func (m *MockFormattedPublisher) PublishFormattedPhoto(ctx context.Context, photo []byte, caption, parseMode string) error {
+	m.Formatted = append(m.Formatted, FormattedCall{Text: caption, ParseMode: parseMode, Photo: true})
+	return m.PublishPhoto(ctx, photo, caption)
+}
+
+var (
+	_ ports.TelegramPublisher  = (*MockPublisher)(nil)
+	_ ports.PhotoPublisher     = (*MockPhotoPublisher)(nil)
+	_ ports.FormattedPublisher = (*MockFormattedPublisher)(nil)
+)
@@ -0,0 +1,90 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockSignalPublisher records published signal batches, charts and outcomes
+// and can fail.
+type MockSignalPublisher struct {
+	Err error
+
+	mu       sync.Mutex
+	batches  [][]entity.Signal
+	charts   [][]byte
+	outcomes []entity.SignalOutcome
+}
+
+// PublishSignals records signals and returns Err.
+func (m *MockSignalPublisher) PublishSignals(ctx context.Context, signals []entity.Signal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, append([]entity.Signal(nil), signals...))
+	return m.Err
+}
+
+// Batches returns the recorded batches.
+func (m *MockSignalPublisher) Batches() [][]entity.Signal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]entity.Signal(nil), m.batches...)
+}
+
+// Signals returns all recorded signals in publish order.
+func (m *MockSignalPublisher) Signals() []entity.Signal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []entity.Signal
+	for _, b := range m.batches {
+		out = append(out, b...)
+	}
+	return out
+}
+
+// PublishSignalCharts records signals and their charts and returns Err.
+func (m *MockSignalPublisher) PublishSignalCharts(ctx context.Context, signals []entity.Signal, charts [][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, append([]entity.Signal(nil), signals...))
+	for i := range signals {
+		var chart []byte
+		if i < len(charts) {
+			chart = charts[i]
+		}
+		m.charts = append(m.charts, chart)
+	}
+	return m.Err
+}
+
+// Charts returns the charts recorded by PublishSignalCharts, nil for
+// signals without one.
+func (m *MockSignalPublisher) Charts() [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]byte(nil), m.charts...)
+}
+
+// PublishOutcomes records outcomes and returns Err.
+func (m *MockSignalPublisher) PublishOutcomes(ctx context.Context, outcomes []entity.SignalOutcome) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes = append(m.outcomes, outcomes...)
+	return m.Err
+}
+
+// Outcomes returns the recorded outcomes.
+func (m *MockSignalPublisher) Outcomes() []entity.SignalOutcome {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]entity.SignalOutcome(nil), m.outcomes...)
+}
+
+var (
+	_ ports.SignalPublisher  = (*MockSignalPublisher)(nil)
+	_ ports.OutcomePublisher = (*MockSignalPublisher)(nil)
+	_ ports.ChartPublisher   = (*MockSignalPublisher)(nil)
+)
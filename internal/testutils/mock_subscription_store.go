@@ -0,0 +1,69 @@
+package testutils
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockSubscriptionStore keeps subscriptions and preferences in memory and
+// can fail saves.
+type MockSubscriptionStore struct {
+	SaveErr error
+
+	mu    sync.Mutex
+	subs  map[int64][]string
+	prefs map[int64]ports.ChatPreferences
+}
+
+// Load returns a copy of the saved subscriptions.
+func (m *MockSubscriptionStore) Load(ctx context.Context) (map[int64][]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return cloneSubscriptions(m.subs), nil
+}
+
+// Save stores a copy of subs unless SaveErr is set.
+func (m *MockSubscriptionStore) Save(ctx context.Context, subs map[int64][]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SaveErr != nil {
+		return m.SaveErr
+	}
+	m.subs = cloneSubscriptions(subs)
+	return nil
+}
+
+// LoadPreferences returns a copy of the saved preferences.
+func (m *MockSubscriptionStore) LoadPreferences(ctx context.Context) (map[int64]ports.ChatPreferences, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.prefs), nil
+}
+
+// SavePreferences stores a copy of prefs unless SaveErr is set.
+func (m *MockSubscriptionStore) SavePreferences(ctx context.Context, prefs map[int64]ports.ChatPreferences) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SaveErr != nil {
+		return m.SaveErr
+	}
+	m.prefs = maps.Clone(prefs)
+	return nil
+}
+
+func cloneSubscriptions(subs map[int64][]string) map[int64][]string {
+	out := make(map[int64][]string, len(subs))
+	for chat, syms := range subs {
+		out[chat] = slices.Clone(syms)
+	}
+	return out
+}
+
+var (
+	_ ports.SubscriptionStore = (*MockSubscriptionStore)(nil)
+	_ ports.PreferenceStore   = (*MockSubscriptionStore)(nil)
+)
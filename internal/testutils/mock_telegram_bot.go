@@ -0,0 +1,117 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// SentMessage is a message recorded by MockTelegramBot.
+type SentMessage struct {
+	ChatID    int64
+	Text      string
+	ParseMode string
+	// Photo is set for messages sent with SendPhoto, whose Text is the
+	// caption.
+	Photo []byte
+}
+
+// MockTelegramBot serves queued updates and records sent messages.
+type MockTelegramBot struct {
+	// SendErr is returned by SendMessage, SendFormatted and SendPhoto when
+	// set.
+	SendErr error
+
+	mu      sync.Mutex
+	updates []ports.BotMessage
+	sent    []SentMessage
+	offsets []int64
+	ready   chan struct{}
+}
+
+// Push queues updates for the next poll.
+func (m *MockTelegramBot) Push(msgs ...ports.BotMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updates = append(m.updates, msgs...)
+	if m.ready != nil {
+		close(m.ready)
+		m.ready = nil
+	}
+}
+
+// Updates returns the queued updates at or after offset, waiting up to
+// timeout for some to arrive.
+func (m *MockTelegramBot) Updates(ctx context.Context, offset int64, timeout time.Duration) ([]ports.BotMessage, error) {
+	m.mu.Lock()
+	m.offsets = append(m.offsets, offset)
+	var out []ports.BotMessage
+	for _, u := range m.updates {
+		if u.UpdateID >= offset {
+			out = append(out, u)
+		}
+	}
+	if len(out) > 0 {
+		m.mu.Unlock()
+		return out, nil
+	}
+	if m.ready == nil {
+		m.ready = make(chan struct{})
+	}
+	ready := m.ready
+	m.mu.Unlock()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-t.C:
+		return nil, nil
+	case <-ready:
+		return m.Updates(ctx, offset, 0)
+	}
+}
+
+// SendMessage records the message and returns SendErr.
+func (m *MockTelegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return m.SendFormatted(ctx, chatID, text, "")
+}
+
+// SendFormatted records the message with its parse mode and returns SendErr.
+func (m *MockTelegramBot) SendFormatted(ctx context.Context, chatID int64, text, parseMode string) error {
+	return m.record(SentMessage{ChatID: chatID, Text: text, ParseMode: parseMode})
+}
+
+// SendPhoto records the photo with its caption and returns SendErr.
+func (m *MockTelegramBot) SendPhoto(ctx context.Context, chatID int64, photo []byte, caption, parseMode string) error {
+	return m.record(SentMessage{ChatID: chatID, Text: caption, ParseMode: parseMode, Photo: photo})
+}
+
+func (m *MockTelegramBot) record(msg SentMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SendErr != nil {
+		return m.SendErr
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent returns the recorded messages.
+func (m *MockTelegramBot) Sent() []SentMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SentMessage(nil), m.sent...)
+}
+
+// Offsets returns the offsets passed to Updates.
+func (m *MockTelegramBot) Offsets() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int64(nil), m.offsets...)
+}
+
+var _ ports.TelegramBot = (*MockTelegramBot)(nil)
@@ -0,0 +1,30 @@
+package testutils
+
+import (
+	"context"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// MockTickFeed streams a predefined tick sequence and then closes.
+type MockTickFeed struct {
+	Ticks []ports.Tick
+}
+
+// StreamTicks returns a channel that emits the configured ticks.
+func (m *MockTickFeed) StreamTicks(ctx context.Context, symbols []string) (<-chan ports.Tick, error) {
+	ch := make(chan ports.Tick)
+	go func() {
+		defer close(ch)
+		for _, t := range m.Ticks {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- t:
+			}
+		}
+	}()
+	return ch, nil
+}
+
+var _ ports.TickFeedPort = (*MockTickFeed)(nil)
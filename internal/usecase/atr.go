@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"math"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// CalcATR calculates Wilder's Average True Range for the provided candles.
+// The returned slice has the same length as candles. Values before the given
+// period remain zero.
+func CalcATR(candles []ports.Candle, period int) []float64 {
+	atr := make([]float64, len(candles))
+	if period <= 0 || len(candles) <= period {
+		return atr
+	}
+	tr := func(i int) float64 {
+		c := candles[i]
+		prev := candles[i-1].Close
+		return math.Max(c.High-c.Low, math.Max(math.Abs(c.High-prev), math.Abs(c.Low-prev)))
+	}
+	var sum float64
+	for i := 1; i <= period; i++ {
+		sum += tr(i)
+	}
+	atr[period] = sum / float64(period)
+	for i := period + 1; i < len(candles); i++ {
+		atr[i] = (atr[i-1]*float64(period-1) + tr(i)) / float64(period)
+	}
+	return atr
+}
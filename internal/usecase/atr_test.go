@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestCalcATR(t *testing.T) {
+	candles := []ports.Candle{
+		{High: 2, Low: 1, Close: 1.5},
+		{High: 2.5, Low: 1.5, Close: 2},   // TR 1
+		{High: 2.2, Low: 1.8, Close: 2},   // TR 0.4
+		{High: 3, Low: 2.5, Close: 2.8},   // TR 1 (gap from 2)
+		{High: 2.9, Low: 2.7, Close: 2.8}, // TR 0.2
+	}
+	want := []float64{0, 0, 0.7, 0.85, 0.525}
+	got := CalcATR(candles, 2)
+	for i := range want {
+		if diff := got[i] - want[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("index %d: want %.5f got %.5f", i, want[i], got[i])
+		}
+	}
+	if got := CalcATR(candles[:2], 2); got[1] != 0 {
+		t.Errorf("expected zeros for short input, got %v", got)
+	}
+}
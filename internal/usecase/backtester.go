@@ -2,9 +2,13 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math"
+	"sort"
 	"time"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 )
 
@@ -14,8 +18,20 @@ type BacktestResult struct {
 	Direction  string
 	EntryTime  time.Time
 	ExpiryTime time.Time
-	Outcome    string
-	Reason     string
+	EntryPrice float64
+	ExitPrice  float64
+	// SignalTime is the open time of the bar that raised the signal.
+	SignalTime time.Time
+	// Pips is the move from entry to exit in the signal's direction. It is
+	// zero for symbols without a registered instrument.
+	Pips float64
+	// Sessions lists the trading sessions active at entry when a calendar
+	// is configured.
+	Sessions []string
+	// Tags carries the signal's tags, such as a news release nearby.
+	Tags    []string
+	Outcome string
+	Reason  string
 }
 
 // BacktestReport aggregates results from a backtest run.
@@ -26,109 +42,300 @@ type BacktestReport struct {
 	Wins     int
 	Losses   int
 	Neutrals int
+	// BySession breaks results down by the sessions active at entry. A trade
+	// during an overlap counts towards each session.
+	BySession map[string]SessionStats
+}
+
+// SessionStats aggregates backtest results for one trading session.
+type SessionStats struct {
+	Total    int
+	Wins     int
+	Losses   int
+	Neutrals int
+	Accuracy float64
+}
+
+func (s *SessionStats) add(outcome string) {
+	s.Total++
+	switch outcome {
+	case "WIN":
+		s.Wins++
+	case "LOSS":
+		s.Losses++
+	case "NEUTRAL":
+		s.Neutrals++
+	}
+	if s.Wins+s.Losses > 0 {
+		s.Accuracy = float64(s.Wins) / float64(s.Wins+s.Losses)
+	}
+}
+
+// BacktestOptions configures optional backtest behaviour.
+type BacktestOptions struct {
+	// Ticks, when present for a symbol, settles trades on the last tick at or
+	// before the entry and expiry instants instead of on bar closes. Ticks
+	// must be sorted by time.
+	Ticks map[string][]ports.Tick
+	// BarInterval is the candle length used to derive the signal time from a
+	// bar's open time. Defaults to one minute.
+	BarInterval time.Duration
+	// Instruments supplies pip sizes used for Pips and MinMovePips.
+	Instruments *entity.InstrumentRegistry
+	// MinMovePips settles trades whose move is smaller than this many pips
+	// as NEUTRAL. It only applies to registered instruments.
+	MinMovePips float64
+	// Calendar tags results with the sessions active at entry, fills
+	// BacktestReport.BySession and skips signal bars it rejects under
+	// SessionFilter, as delivery.WithSessionFilter does for the Orchestrator.
+	Calendar *entity.SessionCalendar
+	// SessionFilter restricts the signal bars Calendar allows. The zero
+	// value allows any session while the market is open.
+	SessionFilter entity.SessionFilter
+	// NewsBlackout, when set, suppresses or tags signals around economic
+	// releases exactly as the Orchestrator does.
+	NewsBlackout *NewsBlackout
+	// RegimeFilter, when set, vetoes or down-weights signals raised outside
+	// the allowed volatility regime exactly as the Orchestrator does.
+	RegimeFilter *RegimeFilter
+	// Scorers selects scorer parameters per symbol, as given to the
+	// Orchestrator. Nil uses the defaults.
+	Scorers *entity.ScorerParamsTable
+	// Throttle, when set, drops the signals it suppresses exactly as the
+	// Orchestrator does. Scored bars are then replayed in time order across
+	// symbols so that cooldowns and the global cap see the live sequence.
+	Throttle *SignalThrottle
+	// SignalTTL settles each signal after its own TTL, as OutcomeTracker
+	// does, instead of after the expiry passed to BacktestSignalsWithOptions.
+	// Signals without a TTL still use expiry.
+	SignalTTL bool
+}
+
+// backtestMinWindow is the fewest bars BacktestSignals scores on.
+const backtestMinWindow = 50
+
+// WarmupBars returns the bars the backtest needs for symbol before it scores
+// the first one.
+func (o BacktestOptions) WarmupBars(symbol string) int {
+	return max(backtestMinWindow, o.Scorers.For(symbol).MinBars())
+}
+
+// scoredBar holds the signals raised on bar i of a symbol's candles.
+type scoredBar struct {
+	symbol  string
+	candles []ports.Candle
+	i       int
+	signals []entity.Signal
 }
 
 // BacktestSignals replays historical candles and evaluates signal outcomes.
 func BacktestSignals(ctx context.Context, logger *slog.Logger, data map[string][]ports.Candle, delayBeforeEntry, expiry time.Duration) BacktestReport {
+	return BacktestSignalsWithOptions(ctx, logger, data, delayBeforeEntry, expiry, BacktestOptions{})
+}
+
+// BacktestSignalsWithOptions behaves like BacktestSignals. Entry is the
+// signal bar's close plus delayBeforeEntry and expiry follows after expiry.
+// Symbols with ticks in opts are settled on the exact tick at entry and
+// expiry, others on bar closes.
+func BacktestSignalsWithOptions(ctx context.Context, logger *slog.Logger, data map[string][]ports.Candle, delayBeforeEntry, expiry time.Duration, opts BacktestOptions) BacktestReport {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	const (
-		windowSize = 50
-		rsiPeriod  = 14
-	)
-
-	var rep BacktestReport
+	if opts.BarInterval <= 0 {
+		opts.BarInterval = time.Minute
+	}
 
+	var scored []scoredBar
 	for symbol, candles := range data {
+		params := opts.Scorers.For(symbol)
+		windowSize := opts.WarmupBars(symbol)
 		if len(candles) < windowSize || !sorted(candles) {
 			continue
 		}
 
 		for i := windowSize - 1; i < len(candles); i++ {
+			if opts.Calendar != nil {
+				if ok, _ := opts.Calendar.Allow(symbol, candles[i].Time, opts.SessionFilter); !ok {
+					continue
+				}
+			}
 			window := candles[i-windowSize+1 : i+1]
-			closes := make([]float64, len(window))
-			for j, c := range window {
-				closes[j] = c.Close
+			var regime RegimeVerdict
+			if opts.RegimeFilter != nil {
+				regime = opts.RegimeFilter.Evaluate(symbol, window)
+				if opts.RegimeFilter.Vetoes(regime) {
+					continue
+				}
 			}
-
-			rsi := CalcRSI(closes, rsiPeriod)
-			ema8 := CalcEMA(closes, 8)
-			ema21 := CalcEMA(closes, 21)
-
-			signals, err := ScanSignalPatterns(ctx, logger, symbol, window, rsi, ema8, ema21)
+			rsi, emaFast, emaSlow := CalcIndicators(window, params)
+			signals, err := ScanSignalPatternsWithParams(ctx, logger, nil, symbol, window, rsi, emaFast, emaSlow, params)
 			if err != nil {
 				continue
 			}
+			if opts.RegimeFilter != nil {
+				signals = opts.RegimeFilter.Apply(regime, signals)
+			}
+			if opts.NewsBlackout != nil {
+				signals, err = opts.NewsBlackout.Apply(ctx, symbol, candles[i].Time, signals)
+				if err != nil {
+					logger.WarnContext(ctx, "news blackout unavailable", "symbol", symbol, "error", err)
+				}
+			}
 			if len(signals) == 0 {
 				continue
 			}
+			scored = append(scored, scoredBar{symbol: symbol, candles: candles, i: i, signals: signals})
+		}
+	}
+	if opts.Throttle != nil {
+		scored = throttleBars(opts.Throttle, scored)
+	}
 
-			entryIdx := i + int(delayBeforeEntry/time.Minute)
-			exitIdx := entryIdx + int(expiry/time.Minute)
-			if exitIdx >= len(candles) {
+	var rep BacktestReport
+	for _, b := range scored {
+		inst, hasInst := opts.Instruments.Lookup(b.symbol)
+		for _, s := range b.signals {
+			hold := expiry
+			if opts.SignalTTL && s.TTL > 0 {
+				hold = s.TTL
+			}
+			res, ok := settleBar(b, delayBeforeEntry, hold, opts)
+			if !ok {
 				continue
 			}
+			res.Direction = s.Direction
+			res.Tags = s.Tags
+			res.Outcome, res.Reason, res.Pips = settleMove(s.Direction, res.EntryPrice, res.ExitPrice, inst, hasInst, opts.MinMovePips)
+			if opts.Calendar != nil {
+				res.Sessions = opts.Calendar.ActiveSessions(b.symbol, res.EntryTime)
+			}
+			rep.add(res)
+		}
+	}
 
-			entryTime := candles[entryIdx].Time
-			expiryTime := candles[exitIdx].Time
-			entryClose := candles[entryIdx].Close
-			exitClose := candles[exitIdx].Close
-
-			for _, s := range signals {
-				res := BacktestResult{
-					Symbol:     symbol,
-					Direction:  s.Direction,
-					EntryTime:  entryTime,
-					ExpiryTime: expiryTime,
-				}
+	return rep
+}
 
-				switch s.Direction {
-				case "UP":
-					switch {
-					case exitClose > entryClose:
-						res.Outcome = "WIN"
-						res.Reason = "closed above entry"
-					case exitClose < entryClose:
-						res.Outcome = "LOSS"
-						res.Reason = "closed below entry"
-					default:
-						res.Outcome = "NEUTRAL"
-						res.Reason = "no change"
-					}
-				case "DOWN":
-					switch {
-					case exitClose < entryClose:
-						res.Outcome = "WIN"
-						res.Reason = "closed below entry"
-					case exitClose > entryClose:
-						res.Outcome = "LOSS"
-						res.Reason = "closed above entry"
-					default:
-						res.Outcome = "NEUTRAL"
-						res.Reason = "no change"
-					}
-				}
+// throttleBars replays scored in time order through t and keeps the signals
+// it allows.
+func throttleBars(t *SignalThrottle, scored []scoredBar) []scoredBar {
+	sort.SliceStable(scored, func(i, j int) bool {
+		ti, tj := scored[i].candles[scored[i].i].Time, scored[j].candles[scored[j].i].Time
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return scored[i].symbol < scored[j].symbol
+	})
+	kept := scored[:0]
+	for _, b := range scored {
+		at := b.candles[b.i].Time
+		b.signals, _ = t.Filter(at, b.signals)
+		if len(b.signals) == 0 {
+			continue
+		}
+		t.Record(at, b.signals)
+		kept = append(kept, b)
+	}
+	return kept
+}
 
-				rep.Results = append(rep.Results, res)
-				rep.Total++
-				switch res.Outcome {
-				case "WIN":
-					rep.Wins++
-				case "LOSS":
-					rep.Losses++
-				case "NEUTRAL":
-					rep.Neutrals++
-				}
-			}
+// settleBar returns the entry and exit of a trade raised on bar b. Entry is
+// the bar's close plus delay and expiry follows after hold. Symbols with
+// ticks in opts settle on the last tick at or before each instant, others on
+// the close of the last bar ending at or before it. It reports false when the
+// data ends before the trade settles.
+func settleBar(b scoredBar, delay, hold time.Duration, opts BacktestOptions) (BacktestResult, bool) {
+	res := BacktestResult{Symbol: b.symbol, SignalTime: b.candles[b.i].Time}
+	res.EntryTime = res.SignalTime.Add(opts.BarInterval + delay)
+	res.ExpiryTime = res.EntryTime.Add(hold)
+	if ticks, ok := opts.Ticks[b.symbol]; ok {
+		var entryOK, exitOK bool
+		res.EntryPrice, entryOK = tickAt(ticks, res.EntryTime)
+		res.ExitPrice, exitOK = tickAt(ticks, res.ExpiryTime)
+		return res, entryOK && exitOK && !ticks[len(ticks)-1].Time.Before(res.ExpiryTime)
+	}
+	entryIdx := b.i + int(delay/opts.BarInterval)
+	exitIdx := entryIdx + int(hold/opts.BarInterval)
+	if exitIdx >= len(b.candles) {
+		return res, false
+	}
+	res.EntryPrice = b.candles[entryIdx].Close
+	res.ExitPrice = b.candles[exitIdx].Close
+	return res, true
+}
+
+// add counts res towards the totals, accuracy and per-session stats.
+func (r *BacktestReport) add(res BacktestResult) {
+	for _, name := range res.Sessions {
+		if r.BySession == nil {
+			r.BySession = make(map[string]SessionStats)
 		}
+		st := r.BySession[name]
+		st.add(res.Outcome)
+		r.BySession[name] = st
 	}
 
-	if rep.Wins+rep.Losses > 0 {
-		rep.Accuracy = float64(rep.Wins) / float64(rep.Wins+rep.Losses)
+	r.Results = append(r.Results, res)
+	r.Total++
+	switch res.Outcome {
+	case "WIN":
+		r.Wins++
+	case "LOSS":
+		r.Losses++
+	case "NEUTRAL":
+		r.Neutrals++
 	}
+	if r.Wins+r.Losses > 0 {
+		r.Accuracy = float64(r.Wins) / float64(r.Wins+r.Losses)
+	}
+}
 
-	return rep
+// settleMove applies SettleSignal and, for registered instruments, computes
+// the move in pips and settles moves below minMovePips as NEUTRAL.
+func settleMove(direction string, entry, exit float64, inst entity.Instrument, hasInst bool, minMovePips float64) (outcome, reason string, pips float64) {
+	outcome, reason = SettleSignal(direction, entry, exit)
+	if !hasInst {
+		return outcome, reason, 0
+	}
+	move := inst.Pips(exit - entry)
+	if direction == "DOWN" {
+		move = -move
+	}
+	if minMovePips > 0 && math.Abs(move) < minMovePips {
+		outcome, reason = "NEUTRAL", fmt.Sprintf("move below %g pips", minMovePips)
+	}
+	return outcome, reason, math.Round(move*10) / 10
+}
+
+// SettleSignal returns the outcome ("WIN", "LOSS" or "NEUTRAL") and reason for
+// a binary trade in direction entered at entry and settled at exit.
+func SettleSignal(direction string, entry, exit float64) (outcome, reason string) {
+	switch direction {
+	case "UP":
+		switch {
+		case exit > entry:
+			return "WIN", "closed above entry"
+		case exit < entry:
+			return "LOSS", "closed below entry"
+		}
+	case "DOWN":
+		switch {
+		case exit < entry:
+			return "WIN", "closed below entry"
+		case exit > entry:
+			return "LOSS", "closed above entry"
+		}
+	}
+	return "NEUTRAL", "no change"
+}
+
+// tickAt returns the price of the last tick at or before t.
+func tickAt(ticks []ports.Tick, t time.Time) (float64, bool) {
+	i := sort.Search(len(ticks), func(i int) bool { return ticks[i].Time.After(t) })
+	if i == 0 {
+		return 0, false
+	}
+	return ticks[i-1].Price(), true
 }
 
 func sorted(c []ports.Candle) bool {
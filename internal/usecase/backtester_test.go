@@ -4,9 +4,11 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 	"github.com/nomenarkt/signalengine/internal/testutils"
 )
@@ -51,6 +53,64 @@ func TestBacktestSignals_Generate(t *testing.T) {
 	if rep.Results[0].Outcome == "NEUTRAL" {
 		t.Errorf("expected resolved outcome, got %s", rep.Results[0].Outcome)
 	}
+	if res := rep.Results[0]; !res.SignalTime.Add(4 * time.Minute).Equal(res.EntryTime) {
+		t.Errorf("expected entry %v three minutes after the close of signal bar %v", res.EntryTime, res.SignalTime)
+	}
+}
+
+func TestBacktestSignals_BarInterval(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cases := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"1m", time.Minute},
+		{"5m", 5 * time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			candles := makeSeries("EURUSD", true)
+			base := candles[0].Time
+			for i := range candles {
+				candles[i].Time = base.Add(time.Duration(i) * tc.interval)
+			}
+			data := map[string][]ports.Candle{"EURUSD": candles}
+			delay, hold := tc.interval, 2*tc.interval
+
+			bars := BacktestSignalsWithOptions(ctx, logger, data, delay, hold, BacktestOptions{BarInterval: tc.interval})
+			if bars.Total == 0 {
+				t.Fatalf("expected bar-settled results")
+			}
+			for _, r := range bars.Results {
+				if !r.EntryTime.Equal(r.SignalTime.Add(2 * tc.interval)) {
+					t.Errorf("entry %v, want signal bar close plus delay after %v", r.EntryTime, r.SignalTime)
+				}
+				if !r.ExpiryTime.Equal(r.EntryTime.Add(hold)) {
+					t.Errorf("expiry %v, want %v after entry %v", r.ExpiryTime, hold, r.EntryTime)
+				}
+			}
+
+			// Ticks at each bar's close, priced at that close, must settle
+			// the same trades as the bars themselves.
+			ticks := make([]ports.Tick, len(candles))
+			for i, c := range candles {
+				ticks[i] = ports.Tick{Symbol: "EURUSD", Time: c.Time.Add(tc.interval), Last: c.Close}
+			}
+			opts := BacktestOptions{BarInterval: tc.interval, Ticks: map[string][]ports.Tick{"EURUSD": ticks}}
+			tks := BacktestSignalsWithOptions(ctx, logger, data, delay, hold, opts)
+			if tks.Total != bars.Total {
+				t.Fatalf("tick path settled %d trades, bar path %d", tks.Total, bars.Total)
+			}
+			for i := range bars.Results {
+				b, k := bars.Results[i], tks.Results[i]
+				if !b.EntryTime.Equal(k.EntryTime) || !b.ExpiryTime.Equal(k.ExpiryTime) ||
+					b.EntryPrice != k.EntryPrice || b.ExitPrice != k.ExitPrice || b.Outcome != k.Outcome {
+					t.Errorf("bar result %+v differs from tick result %+v", b, k)
+				}
+			}
+		})
+	}
 }
 
 func TestBacktestSignals_ReportCounts(t *testing.T) {
@@ -72,3 +132,275 @@ func TestBacktestSignals_ReportCounts(t *testing.T) {
 		t.Errorf("accuracy mismatch")
 	}
 }
+
+func TestBacktestSignals_TickSettlement(t *testing.T) {
+	candles := makeSeries("EURUSD", true)
+	data := map[string][]ports.Candle{"EURUSD": candles}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var ticks []ports.Tick
+	end := candles[len(candles)-1].Time.Add(5 * time.Minute)
+	for ts := candles[0].Time; !ts.After(end); ts = ts.Add(15 * time.Second) {
+		ticks = append(ticks, ports.Tick{Symbol: "EURUSD", Time: ts, Bid: 1, Ask: 1})
+	}
+	opts := BacktestOptions{Ticks: map[string][]ports.Tick{"EURUSD": ticks}}
+
+	flat := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, opts)
+	if flat.Total == 0 {
+		t.Fatalf("expected tick-settled results")
+	}
+	first := flat.Results[0]
+	if got := first.ExpiryTime.Sub(first.EntryTime); got != 2*time.Minute {
+		t.Fatalf("expected 2m expiry window, got %s", got)
+	}
+
+	// Price the exact entry and expiry ticks so the trade moves against the
+	// signal direction.
+	entryPrice, exitPrice := 1.5, 1.4
+	if first.Direction == "DOWN" {
+		entryPrice, exitPrice = 1.4, 1.5
+	}
+	for i := range ticks {
+		switch {
+		case ticks[i].Time.Equal(first.EntryTime):
+			ticks[i].Last = entryPrice
+		case ticks[i].Time.Equal(first.ExpiryTime):
+			ticks[i].Last = exitPrice
+		}
+	}
+
+	rep := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, opts)
+	r := rep.Results[0]
+	if r.EntryPrice != entryPrice || r.ExitPrice != exitPrice || r.Outcome != "LOSS" {
+		t.Fatalf("unexpected tick settlement %+v", r)
+	}
+
+	opts.Ticks["EURUSD"] = nil
+	if rep := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, opts); rep.Total != 0 {
+		t.Fatalf("expected trades without covering ticks to be skipped, got %d", rep.Total)
+	}
+}
+
+func TestSettleSignal(t *testing.T) {
+	tests := []struct {
+		dir         string
+		entry, exit float64
+		want        string
+	}{
+		{"UP", 1, 2, "WIN"},
+		{"UP", 2, 1, "LOSS"},
+		{"DOWN", 2, 1, "WIN"},
+		{"DOWN", 1, 2, "LOSS"},
+		{"UP", 1, 1, "NEUTRAL"},
+	}
+	for _, tt := range tests {
+		if got, _ := SettleSignal(tt.dir, tt.entry, tt.exit); got != tt.want {
+			t.Errorf("%s %v->%v: expected %s, got %s", tt.dir, tt.entry, tt.exit, tt.want, got)
+		}
+	}
+}
+
+func TestBacktestSignals_MinMovePips(t *testing.T) {
+	data := map[string][]ports.Candle{"EURUSD": makeSeries("EURUSD", true)}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	base := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{Instruments: entity.DefaultInstruments()})
+	if base.Total == 0 {
+		t.Fatalf("expected results")
+	}
+	var maxMove float64
+	for _, r := range base.Results {
+		want := (r.ExitPrice - r.EntryPrice) / 0.0001
+		if r.Direction == "DOWN" {
+			want = -want
+		}
+		if math.Abs(r.Pips-want) > 0.05 {
+			t.Fatalf("expected %.1f pips, got %v", want, r.Pips)
+		}
+		maxMove = max(maxMove, math.Abs(r.Pips))
+	}
+
+	filtered := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{
+		Instruments: entity.DefaultInstruments(),
+		MinMovePips: maxMove + 1,
+	})
+	if filtered.Total != base.Total || filtered.Neutrals != filtered.Total {
+		t.Fatalf("expected every trade to be neutral below threshold, got %+v", filtered)
+	}
+
+	plain := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{MinMovePips: maxMove + 1})
+	if plain.Neutrals == plain.Total || plain.Results[0].Pips != 0 {
+		t.Fatalf("expected threshold to apply only to registered instruments")
+	}
+}
+
+func TestBacktestSignals_Sessions(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cal := entity.NewSessionCalendar(entity.DefaultInstruments())
+	at := func(start time.Time) map[string][]ports.Candle {
+		candles := makeSeries("EURUSD", true)
+		shift := start.Sub(candles[0].Time)
+		for i := range candles {
+			candles[i].Time = candles[i].Time.Add(shift)
+		}
+		return map[string][]ports.Candle{"EURUSD": candles}
+	}
+
+	// Entries land around 13:00 UTC on a Wednesday, in the London/New York overlap.
+	rep := BacktestSignalsWithOptions(ctx, logger, at(time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)), time.Minute, 2*time.Minute, BacktestOptions{Calendar: cal})
+	if rep.Total == 0 {
+		t.Fatalf("expected results")
+	}
+	for _, name := range []string{"London", "New York"} {
+		if st := rep.BySession[name]; st.Total != rep.Total || st.Wins != rep.Wins {
+			t.Fatalf("expected every trade in %s, got %+v", name, st)
+		}
+	}
+	if _, ok := rep.BySession["Asia"]; ok {
+		t.Fatalf("unexpected Asia bucket")
+	}
+	if got := rep.Results[0].Sessions; len(got) != 2 {
+		t.Fatalf("expected overlap sessions, got %v", got)
+	}
+
+	// The calendar filters bars as the Orchestrator's session filter does.
+	weekend := at(time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC))
+	if rep := BacktestSignalsWithOptions(ctx, logger, weekend, time.Minute, 2*time.Minute, BacktestOptions{}); rep.Total == 0 {
+		t.Fatalf("expected weekend trades without a calendar")
+	}
+	if filtered := BacktestSignalsWithOptions(ctx, logger, weekend, time.Minute, 2*time.Minute, BacktestOptions{Calendar: cal}); filtered.Total != 0 {
+		t.Fatalf("expected weekend signals to be filtered, got %d", filtered.Total)
+	}
+	asia := BacktestOptions{Calendar: cal, SessionFilter: entity.SessionFilter{Sessions: []string{"Asia"}}}
+	if filtered := BacktestSignalsWithOptions(ctx, logger, at(time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)), time.Minute, 2*time.Minute, asia); filtered.Total != 0 {
+		t.Fatalf("expected signals outside Asia to be filtered, got %d", filtered.Total)
+	}
+}
+
+func TestBacktestSignals_NewsBlackout(t *testing.T) {
+	data := map[string][]ports.Candle{"EURUSD": makeSeries("EURUSD", true)}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	base := BacktestSignals(ctx, logger, data, time.Minute, 2*time.Minute)
+	if base.Total == 0 {
+		t.Fatalf("expected results")
+	}
+	cal := &testutils.MockEconomicCalendar{List: []entity.EconomicEvent{
+		{Time: base.Results[0].EntryTime, Currency: "EUR", Impact: entity.ImpactHigh, Title: "ECB Rate Decision"},
+	}}
+
+	suppressed := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{
+		NewsBlackout: NewNewsBlackout(cal, nil, NewsBlackoutConfig{Before: time.Hour, After: time.Hour}),
+	})
+	if suppressed.Total != 0 {
+		t.Fatalf("expected all signals suppressed, got %d", suppressed.Total)
+	}
+
+	tagged := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{
+		NewsBlackout: NewNewsBlackout(cal, nil, NewsBlackoutConfig{Before: time.Hour, After: time.Hour, Mode: BlackoutTag}),
+	})
+	if tagged.Total != base.Total || len(tagged.Results[0].Tags) != 1 {
+		t.Fatalf("expected tagged results, got %+v", tagged.Results)
+	}
+}
+
+func TestBacktestSignals_RegimeFilter(t *testing.T) {
+	data := map[string][]ports.Candle{"EURUSD": makeSeries("EURUSD", true)}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	base := BacktestSignals(ctx, logger, data, time.Minute, 2*time.Minute)
+	if base.Total == 0 {
+		t.Fatalf("expected results")
+	}
+
+	veto, err := NewRegimeFilter(RegimeFilterConfig{Default: RegimeLimits{MinATR: 10}})
+	if err != nil {
+		t.Fatalf("new regime filter: %v", err)
+	}
+	vetoed := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{RegimeFilter: veto})
+	if vetoed.Total != 0 {
+		t.Fatalf("expected all signals vetoed, got %d", vetoed.Total)
+	}
+
+	down, err := NewRegimeFilter(RegimeFilterConfig{Default: RegimeLimits{MinATR: 10}, Action: RegimeDownWeight})
+	if err != nil {
+		t.Fatalf("new regime filter: %v", err)
+	}
+	weighted := BacktestSignalsWithOptions(ctx, logger, data, time.Minute, 2*time.Minute, BacktestOptions{RegimeFilter: down})
+	if weighted.Total != base.Total || len(weighted.Results[0].Tags) != 1 {
+		t.Fatalf("expected tagged results, got %+v", weighted.Results)
+	}
+}
+
+func TestBacktestSignals_SignalTTL(t *testing.T) {
+	data := map[string][]ports.Candle{"EURUSD": makeSeries("EURUSD", true)}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	scorers, err := entity.NewScorerParamsTable(entity.ScorerParamsConfig{Default: entity.ScorerParams{
+		RSIDivergence:  entity.RSIDivergenceParams{TTL: 3 * time.Minute},
+		EMAInteraction: entity.EMAInteractionParams{TTL: 3 * time.Minute},
+		Candlestick:    entity.CandlestickParams{TTL: 3 * time.Minute},
+	}})
+	if err != nil {
+		t.Fatalf("scorer params: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		signalTTL bool
+		want      time.Duration
+	}{
+		{"expiry", false, 2 * time.Minute},
+		{"signal ttl", true, 3 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := BacktestOptions{Scorers: scorers, SignalTTL: tt.signalTTL}
+			rep := BacktestSignalsWithOptions(ctx, logger, data, 0, 2*time.Minute, opts)
+			if rep.Total == 0 {
+				t.Fatalf("expected results")
+			}
+			for _, r := range rep.Results {
+				if got := r.ExpiryTime.Sub(r.EntryTime); got != tt.want {
+					t.Errorf("expected %s held for %s, got %s", r.Direction, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBacktestSignals_Throttle(t *testing.T) {
+	data := map[string][]ports.Candle{
+		"EURUSD": makeSeries("EURUSD", true),
+		"GBPUSD": makeSeries("GBPUSD", true),
+	}
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	base := BacktestSignals(ctx, logger, data, 0, 2*time.Minute)
+	if base.Total < 2 {
+		t.Fatalf("expected signals on both symbols, got %d", base.Total)
+	}
+
+	tests := []struct {
+		name string
+		cfg  ThrottleConfig
+		want int
+	}{
+		{"disabled", ThrottleConfig{}, base.Total},
+		{"global cap across symbols", ThrottleConfig{MaxSignals: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := BacktestOptions{Throttle: NewSignalThrottle(tt.cfg)}
+			rep := BacktestSignalsWithOptions(ctx, logger, data, 0, 2*time.Minute, opts)
+			if rep.Total != tt.want {
+				t.Fatalf("expected %d results, got %d", tt.want, rep.Total)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package usecase
 import (
 	"context"
 	"log/slog"
-	"time"
 
 	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
@@ -12,10 +11,18 @@ import (
 // ScoreCandlestickPatterns evaluates the latest candles for simple candlestick patterns.
 // It returns signals when bullish or bearish patterns are detected.
 func ScoreCandlestickPatterns(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle) []entity.Signal {
+	return ScoreCandlestickPatternsWithParams(ctx, logger, symbol, candles, entity.CandlestickParams{})
+}
+
+// ScoreCandlestickPatternsWithParams behaves like ScoreCandlestickPatterns
+// with the pin bar shape, confidence and TTL set by p. Unset fields take
+// their defaults.
+func ScoreCandlestickPatternsWithParams(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle, p entity.CandlestickParams) []entity.Signal {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	logger.InfoContext(ctx, "score candlestick patterns", "symbol", symbol)
+	p = p.WithDefaults()
 
 	n := len(candles)
 	if n < 2 {
@@ -26,11 +33,11 @@ func ScoreCandlestickPatterns(ctx context.Context, logger *slog.Logger, symbol s
 	last := n - 1
 	var signals []entity.Signal
 
-	if isBullishEngulfing(candles, last) || isBullishPinBar(candles[last]) {
-		signals = append(signals, entity.Signal{Symbol: symbol, Direction: "UP", Confidence: 0.5, TTL: time.Minute})
+	if isBullishEngulfing(candles, last) || isBullishPinBar(candles[last], p.PinBar) {
+		signals = append(signals, entity.Signal{Symbol: symbol, Direction: "UP", Confidence: p.Confidence, TTL: p.TTL})
 	}
-	if isBearishEngulfing(candles, last) || isBearishPinBar(candles[last]) {
-		signals = append(signals, entity.Signal{Symbol: symbol, Direction: "DOWN", Confidence: 0.5, TTL: time.Minute})
+	if isBearishEngulfing(candles, last) || isBearishPinBar(candles[last], p.PinBar) {
+		signals = append(signals, entity.Signal{Symbol: symbol, Direction: "DOWN", Confidence: p.Confidence, TTL: p.TTL})
 	}
 
 	if len(signals) == 0 {
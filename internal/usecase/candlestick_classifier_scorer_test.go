@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 )
 
@@ -55,6 +56,20 @@ func TestScoreCandlestickPatterns(t *testing.T) {
 		}
 	})
 
+	t.Run("params", func(t *testing.T) {
+		candles := makeBearishPin()
+		p := entity.CandlestickParams{Confidence: 0.3, TTL: 3 * time.Minute}
+		sigs := ScoreCandlestickPatternsWithParams(ctx, logger, "EURUSD", candles, p)
+		if len(sigs) != 1 || sigs[0].Confidence != 0.3 || sigs[0].TTL != 3*time.Minute {
+			t.Fatalf("expected a DOWN signal with the configured confidence and TTL, got %+v", sigs)
+		}
+		// The upper wick is 2/3 of the range.
+		p.PinBar.MinWick = 0.7
+		if sigs := ScoreCandlestickPatternsWithParams(ctx, logger, "EURUSD", candles, p); len(sigs) != 0 {
+			t.Fatalf("expected no signal with a longer minimum wick, got %+v", sigs)
+		}
+	})
+
 	t.Run("none logs", func(t *testing.T) {
 		candles := makeNeutral()
 		buf := &bytes.Buffer{}
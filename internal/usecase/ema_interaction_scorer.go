@@ -3,7 +3,6 @@ package usecase
 import (
 	"context"
 	"log/slog"
-	"time"
 
 	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
@@ -12,10 +11,18 @@ import (
 // ScoreEMAInteractions looks for EMA crossovers between ema8 and ema21.
 // It returns binary trade signals when the fast EMA crosses the slow EMA.
 func ScoreEMAInteractions(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle, ema8, ema21 []float64) []entity.Signal {
+	return ScoreEMAInteractionsWithParams(ctx, logger, symbol, candles, ema8, ema21, entity.EMAInteractionParams{})
+}
+
+// ScoreEMAInteractionsWithParams behaves like ScoreEMAInteractions for the
+// EMAs of p's fast and slow periods, passed as ema8 and ema21, and raises
+// signals with p's confidence and TTL. Unset fields take their defaults.
+func ScoreEMAInteractionsWithParams(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle, ema8, ema21 []float64, p entity.EMAInteractionParams) []entity.Signal {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	logger.InfoContext(ctx, "score EMA interactions", "symbol", symbol)
+	p = p.WithDefaults()
 
 	n := len(candles)
 	if n < 2 || n != len(ema8) || n != len(ema21) {
@@ -31,8 +38,8 @@ func ScoreEMAInteractions(ctx context.Context, logger *slog.Logger, symbol strin
 		signals = append(signals, entity.Signal{
 			Symbol:     symbol,
 			Direction:  "UP",
-			Confidence: 0.6,
-			TTL:        time.Minute,
+			Confidence: p.Confidence,
+			TTL:        p.TTL,
 		})
 	}
 
@@ -40,8 +47,8 @@ func ScoreEMAInteractions(ctx context.Context, logger *slog.Logger, symbol strin
 		signals = append(signals, entity.Signal{
 			Symbol:     symbol,
 			Direction:  "DOWN",
-			Confidence: 0.6,
-			TTL:        time.Minute,
+			Confidence: p.Confidence,
+			TTL:        p.TTL,
 		})
 	}
 
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// LiveComparison aligns the signals published live with those BacktestSignals
+// raises on the recorded candles of the same period. Signals are paired by
+// symbol, direction and signal bar.
+type LiveComparison struct {
+	// Live and Backtest summarise each side within the period.
+	Live     BacktestReport
+	Backtest BacktestReport
+	// Matched pairs the signals raised on both sides.
+	Matched []MatchedSignal
+	// Missed lists backtest signals without a live outcome, e.g. because
+	// candles arrived late or not at all, or because the signal was still
+	// pending when the engine stopped and its outcome was never recorded.
+	Missed []BacktestResult
+	// Extra lists live signals the backtest did not raise, e.g. because the
+	// live candles differ from the recorded ones.
+	Extra []BacktestResult
+	// Disagreements counts matched signals that settled differently.
+	Disagreements int
+	// AccuracyDrift is Live.Accuracy minus Backtest.Accuracy.
+	AccuracyDrift float64
+	// BySymbol breaks the comparison down by symbol key.
+	BySymbol map[string]ComparisonStats
+}
+
+// MatchedSignal is a signal raised both live and in the backtest.
+type MatchedSignal struct {
+	Live     BacktestResult
+	Backtest BacktestResult
+}
+
+// Agrees reports whether both sides settled the signal alike.
+func (m MatchedSignal) Agrees() bool {
+	return m.Live.Outcome == m.Backtest.Outcome
+}
+
+// ComparisonStats aggregates a LiveComparison for one symbol.
+type ComparisonStats struct {
+	Live          SessionStats
+	Backtest      SessionStats
+	Matched       int
+	Missed        int
+	Extra         int
+	Disagreements int
+}
+
+// CompareLive compares live outcomes with backtest, both restricted to
+// signals raised on bars in [from, to). A zero from or to leaves that end
+// open. Live signals are known only from their settled outcomes, so signals
+// published but left pending across a restart count as Missed. For a
+// meaningful accuracy comparison the backtest should set
+// BacktestOptions.SignalTTL and use the scorer parameters and filters of the
+// Orchestrator.
+func CompareLive(live []entity.SignalOutcome, backtest BacktestReport, from, to time.Time) LiveComparison {
+	inPeriod := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	}
+	key := func(r BacktestResult) string {
+		return entity.SymbolKey(r.Symbol) + "|" + r.Direction + "|" + r.SignalTime.UTC().Format(time.RFC3339Nano)
+	}
+
+	var cmp LiveComparison
+	pending := make(map[string][]BacktestResult)
+	for _, res := range backtest.Results {
+		if !inPeriod(res.SignalTime) {
+			continue
+		}
+		cmp.Backtest.add(res)
+		pending[key(res)] = append(pending[key(res)], res)
+	}
+	for _, res := range OutcomeReport(live).Results {
+		if !inPeriod(res.SignalTime) {
+			continue
+		}
+		cmp.Live.add(res)
+		k := key(res)
+		if bt := pending[k]; len(bt) > 0 {
+			m := MatchedSignal{Live: res, Backtest: bt[0]}
+			pending[k] = bt[1:]
+			cmp.Matched = append(cmp.Matched, m)
+			if !m.Agrees() {
+				cmp.Disagreements++
+			}
+			continue
+		}
+		cmp.Extra = append(cmp.Extra, res)
+	}
+	for _, bt := range pending {
+		cmp.Missed = append(cmp.Missed, bt...)
+	}
+	cmp.AccuracyDrift = cmp.Live.Accuracy - cmp.Backtest.Accuracy
+
+	sort.Slice(cmp.Matched, func(i, j int) bool { return resultBefore(cmp.Matched[i].Live, cmp.Matched[j].Live) })
+	sort.Slice(cmp.Missed, func(i, j int) bool { return resultBefore(cmp.Missed[i], cmp.Missed[j]) })
+	sort.Slice(cmp.Extra, func(i, j int) bool { return resultBefore(cmp.Extra[i], cmp.Extra[j]) })
+	cmp.bySymbol()
+	return cmp
+}
+
+// bySymbol fills BySymbol from the other fields.
+func (c *LiveComparison) bySymbol() {
+	stats := make(map[string]ComparisonStats)
+	update := func(symbol string, f func(*ComparisonStats)) {
+		k := entity.SymbolKey(symbol)
+		st := stats[k]
+		f(&st)
+		stats[k] = st
+	}
+	for _, res := range c.Live.Results {
+		update(res.Symbol, func(st *ComparisonStats) { st.Live.add(res.Outcome) })
+	}
+	for _, res := range c.Backtest.Results {
+		update(res.Symbol, func(st *ComparisonStats) { st.Backtest.add(res.Outcome) })
+	}
+	for _, m := range c.Matched {
+		update(m.Live.Symbol, func(st *ComparisonStats) {
+			st.Matched++
+			if !m.Agrees() {
+				st.Disagreements++
+			}
+		})
+	}
+	for _, res := range c.Missed {
+		update(res.Symbol, func(st *ComparisonStats) { st.Missed++ })
+	}
+	for _, res := range c.Extra {
+		update(res.Symbol, func(st *ComparisonStats) { st.Extra++ })
+	}
+	if len(stats) > 0 {
+		c.BySymbol = stats
+	}
+}
+
+func resultBefore(a, b BacktestResult) bool {
+	if !a.SignalTime.Equal(b.SignalTime) {
+		return a.SignalTime.Before(b.SignalTime)
+	}
+	if a.Symbol != b.Symbol {
+		return a.Symbol < b.Symbol
+	}
+	return a.Direction < b.Direction
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestCompareLive(t *testing.T) {
+	bar := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time { return bar.Add(time.Duration(minute) * time.Minute) }
+	live := func(sym, dir string, minute int, outcome string) entity.SignalOutcome {
+		return entity.SignalOutcome{Signal: entity.Signal{Symbol: sym, Direction: dir, Time: at(minute)}, Outcome: outcome}
+	}
+	backtest := func(sym, dir string, minute int, outcome string) BacktestResult {
+		return BacktestResult{Symbol: sym, Direction: dir, SignalTime: at(minute), Outcome: outcome}
+	}
+
+	var rep BacktestReport
+	for _, res := range []BacktestResult{
+		backtest("EURUSD", "UP", 0, "WIN"),
+		backtest("EURUSD", "DOWN", 5, "WIN"),
+		backtest("EURUSD", "UP", 10, "WIN"),  // missed live
+		backtest("GBPUSD", "UP", 3, "WIN"),   // settled differently
+		backtest("GBPUSD", "UP", 60, "LOSS"), // outside the period
+	} {
+		rep.add(res)
+	}
+	cmp := CompareLive([]entity.SignalOutcome{
+		live("EUR/USD", "UP", 0, "WIN"),
+		live("EURUSD", "DOWN", 5, "WIN"),
+		live("GBPUSD", "UP", 3, "LOSS"),
+		live("GBPUSD", "DOWN", 3, "LOSS"), // extra live
+		live("EURUSD", "UP", -5, "WIN"),   // before the period
+	}, rep, bar, at(30))
+
+	if len(cmp.Matched) != 3 || cmp.Disagreements != 1 {
+		t.Fatalf("expected 3 matches with 1 disagreement, got %+v", cmp.Matched)
+	}
+	if cmp.Matched[0].Live.Symbol != "EUR/USD" || cmp.Matched[2].Live.SignalTime != at(5) {
+		t.Fatalf("expected matches in bar order, got %+v", cmp.Matched)
+	}
+	if len(cmp.Missed) != 1 || cmp.Missed[0].SignalTime != at(10) {
+		t.Fatalf("unexpected missed %+v", cmp.Missed)
+	}
+	if len(cmp.Extra) != 1 || cmp.Extra[0].Direction != "DOWN" {
+		t.Fatalf("unexpected extra %+v", cmp.Extra)
+	}
+	if cmp.Live.Total != 4 || cmp.Backtest.Total != 4 {
+		t.Fatalf("expected 4 signals per side, got %d live and %d backtest", cmp.Live.Total, cmp.Backtest.Total)
+	}
+	if cmp.Live.Accuracy != 0.5 || cmp.Backtest.Accuracy != 1 || cmp.AccuracyDrift != -0.5 {
+		t.Fatalf("unexpected accuracy live %v backtest %v drift %v", cmp.Live.Accuracy, cmp.Backtest.Accuracy, cmp.AccuracyDrift)
+	}
+
+	eur, gbp := cmp.BySymbol["EURUSD"], cmp.BySymbol["GBPUSD"]
+	if eur.Matched != 2 || eur.Missed != 1 || eur.Extra != 0 || eur.Live.Total != 2 || eur.Backtest.Total != 3 {
+		t.Fatalf("unexpected EURUSD stats %+v", eur)
+	}
+	if gbp.Matched != 1 || gbp.Disagreements != 1 || gbp.Extra != 1 || gbp.Live.Accuracy != 0 || gbp.Backtest.Accuracy != 1 {
+		t.Fatalf("unexpected GBPUSD stats %+v", gbp)
+	}
+}
+
+func TestCompareLive_Duplicates(t *testing.T) {
+	bar := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	s := entity.Signal{Symbol: "EURUSD", Direction: "UP", Time: bar}
+	var rep BacktestReport
+	rep.add(BacktestResult{Symbol: "EURUSD", Direction: "UP", SignalTime: bar, Outcome: "WIN"})
+
+	cmp := CompareLive([]entity.SignalOutcome{{Signal: s, Outcome: "WIN"}, {Signal: s, Outcome: "WIN"}}, rep, time.Time{}, time.Time{})
+	if len(cmp.Matched) != 1 || len(cmp.Extra) != 1 || len(cmp.Missed) != 0 {
+		t.Fatalf("expected each backtest signal to match once, got %+v", cmp)
+	}
+
+	if empty := CompareLive(nil, BacktestReport{}, time.Time{}, time.Time{}); empty.BySymbol != nil || empty.AccuracyDrift != 0 {
+		t.Fatalf("expected an empty comparison, got %+v", empty)
+	}
+}
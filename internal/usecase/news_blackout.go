@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// BlackoutMode selects what happens to signals inside a news window.
+type BlackoutMode int
+
+const (
+	// BlackoutSuppress drops signals inside a news window.
+	BlackoutSuppress BlackoutMode = iota
+	// BlackoutTag keeps signals but tags them with the event.
+	BlackoutTag
+)
+
+// NewsBlackoutConfig configures the window around economic releases.
+type NewsBlackoutConfig struct {
+	// Before is how long before a release signals are affected. Defaults to
+	// 15 minutes.
+	Before time.Duration
+	// After is how long after a release signals are affected. Defaults to
+	// 15 minutes.
+	After time.Duration
+	// MinImpact ignores less disruptive events. Defaults to ImpactHigh.
+	MinImpact entity.EventImpact
+	// Mode selects suppression or tagging.
+	Mode BlackoutMode
+	// Lookahead is the span fetched from the calendar per query. Defaults to
+	// 24 hours and is never shorter than Before plus After.
+	Lookahead time.Duration
+}
+
+func (c NewsBlackoutConfig) withDefaults() NewsBlackoutConfig {
+	if c.Before <= 0 {
+		c.Before = 15 * time.Minute
+	}
+	if c.After <= 0 {
+		c.After = 15 * time.Minute
+	}
+	if c.MinImpact == 0 {
+		c.MinImpact = entity.ImpactHigh
+	}
+	if c.Lookahead <= 0 {
+		c.Lookahead = 24 * time.Hour
+	}
+	c.Lookahead = max(c.Lookahead, c.Before+c.After)
+	return c
+}
+
+// NewsBlackout suppresses or tags signals for symbols whose currencies have a
+// release within the configured window. It caches calendar queries and is
+// safe for concurrent use.
+type NewsBlackout struct {
+	calendar    ports.EconomicCalendarPort
+	instruments *entity.InstrumentRegistry
+	cfg         NewsBlackoutConfig
+
+	mu       sync.Mutex
+	from, to time.Time
+	events   []entity.EconomicEvent
+}
+
+// NewNewsBlackout returns a NewsBlackout over calendar. instruments, when
+// set, resolves symbols to their canonical pair before currencies are
+// derived.
+func NewNewsBlackout(calendar ports.EconomicCalendarPort, instruments *entity.InstrumentRegistry, cfg NewsBlackoutConfig) *NewsBlackout {
+	return &NewsBlackout{calendar: calendar, instruments: instruments, cfg: cfg.withDefaults()}
+}
+
+// Mode returns the configured blackout mode.
+func (b *NewsBlackout) Mode() BlackoutMode {
+	return b.cfg.Mode
+}
+
+// Event returns the earliest event affecting symbol whose window contains t.
+func (b *NewsBlackout) Event(ctx context.Context, symbol string, t time.Time) (entity.EconomicEvent, bool, error) {
+	// An event at time e affects [e-Before, e+After], so t is affected by
+	// events in [t-After, t+Before].
+	events, err := b.window(ctx, t.Add(-b.cfg.After), t.Add(b.cfg.Before))
+	if err != nil {
+		return entity.EconomicEvent{}, false, err
+	}
+	currencies := symbolCurrencies(b.instruments.Canonical(symbol))
+	for _, ev := range events {
+		if ev.Impact < b.cfg.MinImpact {
+			continue
+		}
+		for _, c := range currencies {
+			if c == ev.Currency {
+				return ev, true, nil
+			}
+		}
+	}
+	return entity.EconomicEvent{}, false, nil
+}
+
+// Apply suppresses or tags signals for symbol raised at t according to Mode.
+func (b *NewsBlackout) Apply(ctx context.Context, symbol string, t time.Time, signals []entity.Signal) ([]entity.Signal, error) {
+	if len(signals) == 0 {
+		return signals, nil
+	}
+	ev, ok, err := b.Event(ctx, symbol, t)
+	if err != nil || !ok {
+		return signals, err
+	}
+	if b.cfg.Mode == BlackoutSuppress {
+		return nil, nil
+	}
+	tag := NewsTag(ev)
+	out := make([]entity.Signal, len(signals))
+	for i, s := range signals {
+		s.Tags = append(append([]string(nil), s.Tags...), tag)
+		out[i] = s
+	}
+	return out, nil
+}
+
+// NewsTag formats ev as a signal tag.
+func NewsTag(ev entity.EconomicEvent) string {
+	return fmt.Sprintf("news: %s %s at %s UTC", ev.Currency, ev.Title, ev.Time.UTC().Format("15:04"))
+}
+
+// window returns cached events in [from, to], fetching a new span from the
+// calendar when the cache does not cover it.
+func (b *NewsBlackout) window(ctx context.Context, from, to time.Time) ([]entity.EconomicEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.to.IsZero() || from.Before(b.from) || !to.Before(b.to) {
+		end := from.Add(b.cfg.Lookahead)
+		events, err := b.calendar.Events(ctx, from, end)
+		if err != nil {
+			return nil, fmt.Errorf("economic calendar: %w", err)
+		}
+		b.from, b.to, b.events = from, end, events
+	}
+
+	var out []entity.EconomicEvent
+	for _, ev := range b.events {
+		if !ev.Time.Before(from) && !ev.Time.After(to) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+// symbolCurrencies derives the currencies a symbol is exposed to, e.g.
+// EUR/USD -> [EUR USD]. Stablecoins also map to the currency they track.
+func symbolCurrencies(symbol string) []string {
+	base, quote, ok := strings.Cut(strings.ToUpper(symbol), "/")
+	if !ok {
+		key := entity.SymbolKey(symbol)
+		switch {
+		case strings.HasSuffix(key, "USDT"), strings.HasSuffix(key, "USDC"):
+			base, quote = key[:len(key)-4], key[len(key)-4:]
+		case len(key) == 6:
+			base, quote = key[:3], key[3:]
+		default:
+			return []string{key}
+		}
+	}
+	out := []string{base, quote}
+	for _, c := range out[:2] {
+		switch c {
+		case "USDT", "USDC":
+			out = append(out, "USD")
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestNewsBlackout_Apply(t *testing.T) {
+	nfp := time.Date(2024, 1, 5, 13, 30, 0, 0, time.UTC)
+	cal := &testutils.MockEconomicCalendar{List: []entity.EconomicEvent{
+		{Time: nfp, Currency: "USD", Impact: entity.ImpactHigh, Title: "Non-Farm Payrolls"},
+		{Time: nfp.Add(2 * time.Hour), Currency: "EUR", Impact: entity.ImpactLow, Title: "Retail Sales"},
+	}}
+	signals := []entity.Signal{{Symbol: "EURUSD", Direction: "UP"}}
+
+	tests := []struct {
+		name   string
+		cfg    NewsBlackoutConfig
+		symbol string
+		at     time.Time
+		want   []entity.Signal
+	}{
+		{name: "suppress before release", symbol: "EURUSD", at: nfp.Add(-10 * time.Minute), want: nil},
+		{name: "suppress after release", symbol: "EUR/USD", at: nfp.Add(15 * time.Minute), want: nil},
+		{name: "outside window", symbol: "EURUSD", at: nfp.Add(-20 * time.Minute), want: signals},
+		{name: "unaffected pair", symbol: "EURGBP", at: nfp, want: signals},
+		{name: "stablecoin maps to usd", symbol: "BTCUSDT", at: nfp, want: nil},
+		{name: "low impact ignored", symbol: "EURGBP", at: nfp.Add(2 * time.Hour), want: signals},
+		{name: "low impact included", cfg: NewsBlackoutConfig{MinImpact: entity.ImpactLow}, symbol: "EURGBP", at: nfp.Add(2 * time.Hour), want: nil},
+		{
+			name:   "tag",
+			cfg:    NewsBlackoutConfig{Mode: BlackoutTag, Before: time.Hour},
+			symbol: "EURUSD",
+			at:     nfp.Add(-45 * time.Minute),
+			want:   []entity.Signal{{Symbol: "EURUSD", Direction: "UP", Tags: []string{"news: USD Non-Farm Payrolls at 13:30 UTC"}}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewNewsBlackout(cal, entity.DefaultInstruments(), tt.cfg)
+			got, err := b.Apply(context.Background(), tt.symbol, tt.at, signals)
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+	if signals[0].Tags != nil {
+		t.Fatalf("input signals must not be modified")
+	}
+}
+
+func TestNewsBlackout_Cache(t *testing.T) {
+	cal := &testutils.MockEconomicCalendar{}
+	b := NewNewsBlackout(cal, nil, NewsBlackoutConfig{Lookahead: time.Hour})
+	start := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 30; i++ {
+		if _, _, err := b.Event(context.Background(), "EURUSD", start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("event: %v", err)
+		}
+	}
+	if got := cal.Calls(); got != 1 {
+		t.Fatalf("expected one calendar query, got %d", got)
+	}
+	b.Event(context.Background(), "EURUSD", start.Add(2*time.Hour))
+	if got := cal.Calls(); got != 2 {
+		t.Fatalf("expected refetch outside cached span, got %d", got)
+	}
+
+	failing := NewNewsBlackout(&testutils.MockEconomicCalendar{Err: errors.New("down")}, nil, NewsBlackoutConfig{})
+	sigs := []entity.Signal{{Symbol: "EURUSD"}}
+	got, err := failing.Apply(context.Background(), "EURUSD", start, sigs)
+	if err == nil || len(got) != 1 {
+		t.Fatalf("expected error with signals unchanged, got %v %v", got, err)
+	}
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// OutcomeTrackerOptions configures an OutcomeTracker. The fields mirror
+// their BacktestOptions counterparts so that live and backtest outcomes are
+// settled alike.
+type OutcomeTrackerOptions struct {
+	// BarInterval is the candle length. Defaults to one minute.
+	BarInterval time.Duration
+	// Instruments supplies pip sizes used for Pips and MinMovePips.
+	Instruments *entity.InstrumentRegistry
+	// MinMovePips settles trades whose move is smaller than this many pips
+	// as NEUTRAL. It only applies to registered instruments.
+	MinMovePips float64
+}
+
+// OutcomeTracker follows published signals until their TTL expires and
+// settles them against later candles with the same rules as
+// BacktestSignals: entry is the close of the signal's bar and exit the close
+// of the first bar ending at or after entry plus the TTL. It is safe for
+// concurrent use.
+type OutcomeTracker struct {
+	opts OutcomeTrackerOptions
+
+	mu      sync.Mutex
+	pending map[string][]entity.SignalOutcome // by symbol key
+}
+
+// NewOutcomeTracker returns an empty tracker.
+func NewOutcomeTracker(opts OutcomeTrackerOptions) *OutcomeTracker {
+	if opts.BarInterval <= 0 {
+		opts.BarInterval = time.Minute
+	}
+	return &OutcomeTracker{opts: opts, pending: make(map[string][]entity.SignalOutcome)}
+}
+
+// Track starts following signals. Closed-bar signals are tracked as is and
+// early signals once confirmed, whose Price the Orchestrator sets to the
+// close of the confirming bar; cancelled and early signals are ignored, as
+// are signals without a price, bar time or TTL.
+func (t *OutcomeTracker) Track(signals []entity.Signal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range signals {
+		if s.Stage != entity.StageSignal && s.Stage != entity.StageConfirmed {
+			continue
+		}
+		if s.Price <= 0 || s.Time.IsZero() || s.TTL <= 0 {
+			continue
+		}
+		entry := s.Time.Add(t.opts.BarInterval)
+		key := entity.SymbolKey(s.Symbol)
+		t.pending[key] = append(t.pending[key], entity.SignalOutcome{
+			Signal:     s,
+			EntryTime:  entry,
+			ExpiryTime: entry.Add(s.TTL),
+			EntryPrice: s.Price,
+		})
+	}
+}
+
+// Observe settles the signals for c's symbol whose expiry falls at or before
+// the close of c and returns their outcomes. Partial candles are ignored.
+func (t *OutcomeTracker) Observe(c ports.Candle) []entity.SignalOutcome {
+	if c.Partial {
+		return nil
+	}
+	closeTime := c.Time.Add(t.opts.BarInterval)
+	key := entity.SymbolKey(c.Symbol)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var settled, open []entity.SignalOutcome
+	for _, o := range t.pending[key] {
+		if closeTime.Before(o.ExpiryTime) {
+			open = append(open, o)
+			continue
+		}
+		inst, hasInst := t.opts.Instruments.Lookup(c.Symbol)
+		o.ExitPrice = c.Close
+		o.Outcome, o.Reason, o.Pips = settleMove(o.Signal.Direction, o.EntryPrice, o.ExitPrice, inst, hasInst, t.opts.MinMovePips)
+		settled = append(settled, o)
+	}
+	if len(open) == 0 {
+		delete(t.pending, key)
+	} else {
+		t.pending[key] = open
+	}
+	return settled
+}
+
+// Pending returns the number of signals awaiting settlement.
+func (t *OutcomeTracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, p := range t.pending {
+		n += len(p)
+	}
+	return n
+}
+
+// OutcomeReport summarises live outcomes in the BacktestReport format so
+// that live and backtest accuracy can be compared directly.
+func OutcomeReport(outcomes []entity.SignalOutcome) BacktestReport {
+	var rep BacktestReport
+	for _, o := range outcomes {
+		rep.add(BacktestResult{
+			Symbol:     o.Signal.Symbol,
+			Direction:  o.Signal.Direction,
+			SignalTime: o.Signal.Time,
+			EntryTime:  o.EntryTime,
+			ExpiryTime: o.ExpiryTime,
+			EntryPrice: o.EntryPrice,
+			ExitPrice:  o.ExitPrice,
+			Pips:       o.Pips,
+			Tags:       o.Signal.Tags,
+			Outcome:    o.Outcome,
+			Reason:     o.Reason,
+		})
+	}
+	return rep
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+func TestOutcomeTracker_Settle(t *testing.T) {
+	bar := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	candle := func(sym string, minute int, close float64) ports.Candle {
+		return ports.Candle{Symbol: sym, Time: bar.Add(time.Duration(minute) * time.Minute), Close: close}
+	}
+	tests := []struct {
+		name    string
+		opts    OutcomeTrackerOptions
+		signal  entity.Signal
+		candles []ports.Candle
+		settled int // index of the candle that settles the signal, -1 for none
+		outcome string
+		pips    float64
+		pending int
+	}{
+		{
+			name:    "up win at expiry",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Price: 1.1000, Time: bar, TTL: 2 * time.Minute},
+			candles: []ports.Candle{candle("EURUSD", 1, 1.0990), candle("EURUSD", 2, 1.1005)},
+			settled: 1,
+			outcome: "WIN",
+		},
+		{
+			name:    "down loss",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Price: 1.1000, Time: bar, TTL: time.Minute},
+			candles: []ports.Candle{candle("EURUSD", 1, 1.1010)},
+			settled: 0,
+			outcome: "LOSS",
+		},
+		{
+			name:    "other symbols ignored",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Price: 1.1000, Time: bar, TTL: time.Minute},
+			candles: []ports.Candle{candle("GBPUSD", 1, 1.3000), candle("EUR/USD", 1, 1.1003)},
+			settled: 1,
+			outcome: "WIN",
+		},
+		{
+			name:    "partial candles ignored",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Price: 1.1000, Time: bar, TTL: time.Minute},
+			candles: []ports.Candle{{Symbol: "EURUSD", Time: bar.Add(time.Minute), Close: 1.2, Partial: true}},
+			settled: -1,
+			pending: 1,
+		},
+		{
+			name:    "pips with instruments",
+			opts:    OutcomeTrackerOptions{Instruments: entity.DefaultInstruments()},
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "DOWN", Price: 1.1000, Time: bar, TTL: time.Minute},
+			candles: []ports.Candle{candle("EURUSD", 1, 1.0988)},
+			settled: 0,
+			outcome: "WIN",
+			pips:    12,
+		},
+		{
+			name:    "move below min pips",
+			opts:    OutcomeTrackerOptions{Instruments: entity.DefaultInstruments(), MinMovePips: 2},
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Price: 1.1000, Time: bar, TTL: time.Minute},
+			candles: []ports.Candle{candle("EURUSD", 1, 1.1001)},
+			settled: 0,
+			outcome: "NEUTRAL",
+			pips:    1,
+		},
+		{
+			name:    "early signals ignored",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Price: 1.1000, Time: bar, TTL: time.Minute, Stage: entity.StageEarly},
+			candles: []ports.Candle{candle("EURUSD", 1, 1.2)},
+			settled: -1,
+		},
+		{
+			name:    "no price ignored",
+			signal:  entity.Signal{Symbol: "EURUSD", Direction: "UP", Time: bar, TTL: time.Minute},
+			candles: []ports.Candle{candle("EURUSD", 1, 1.2)},
+			settled: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewOutcomeTracker(tt.opts)
+			tr.Track([]entity.Signal{tt.signal})
+			for i, c := range tt.candles {
+				got := tr.Observe(c)
+				if i != tt.settled {
+					if len(got) != 0 {
+						t.Fatalf("candle %d: unexpected outcomes %+v", i, got)
+					}
+					continue
+				}
+				if len(got) != 1 {
+					t.Fatalf("candle %d: expected one outcome, got %+v", i, got)
+				}
+				o := got[0]
+				if o.Outcome != tt.outcome || o.Pips != tt.pips || o.ExitPrice != c.Close || o.EntryPrice != tt.signal.Price {
+					t.Fatalf("unexpected outcome %+v", o)
+				}
+				if want := bar.Add(time.Minute + tt.signal.TTL); !o.ExpiryTime.Equal(want) {
+					t.Fatalf("expected expiry %v, got %v", want, o.ExpiryTime)
+				}
+			}
+			if tr.Pending() != tt.pending {
+				t.Fatalf("expected %d pending, got %d", tt.pending, tr.Pending())
+			}
+		})
+	}
+}
+
+func TestOutcomeReport(t *testing.T) {
+	outcomes := []entity.SignalOutcome{
+		{Signal: entity.Signal{Symbol: "EURUSD", Direction: "UP"}, Outcome: "WIN"},
+		{Signal: entity.Signal{Symbol: "EURUSD", Direction: "DOWN"}, Outcome: "WIN"},
+		{Signal: entity.Signal{Symbol: "GBPUSD", Direction: "UP"}, Outcome: "LOSS"},
+		{Signal: entity.Signal{Symbol: "GBPUSD", Direction: "UP"}, Outcome: "NEUTRAL"},
+	}
+	rep := OutcomeReport(outcomes)
+	if rep.Total != 4 || rep.Wins != 2 || rep.Losses != 1 || rep.Neutrals != 1 {
+		t.Fatalf("unexpected counts %+v", rep)
+	}
+	if rep.Accuracy != 2.0/3 {
+		t.Fatalf("expected accuracy 2/3, got %v", rep.Accuracy)
+	}
+	if len(rep.Results) != 4 || rep.Results[2].Symbol != "GBPUSD" {
+		t.Fatalf("unexpected results %+v", rep.Results)
+	}
+}
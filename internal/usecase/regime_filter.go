@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// RegimeAction selects what happens to signals raised outside the allowed
+// volatility regime.
+type RegimeAction int
+
+const (
+	// RegimeVeto skips scoring entirely.
+	RegimeVeto RegimeAction = iota
+	// RegimeDownWeight keeps signals but scales their confidence and tags
+	// them with the reason.
+	RegimeDownWeight
+)
+
+// RegimeLimits bounds the market conditions in which signals are raised. A
+// zero value disables the corresponding limit. ATR and spread are expressed
+// in pips for registered instruments and in price units otherwise.
+type RegimeLimits struct {
+	MinATR float64
+	MaxATR float64
+	// MinRangePercentile and MaxRangePercentile bound the percentile rank
+	// (0-100) of the last bar's high-low range within the buffer.
+	MinRangePercentile float64
+	MaxRangePercentile float64
+	// MaxSpread only applies when the feed reports spreads.
+	MaxSpread float64
+}
+
+func (l RegimeLimits) validate() error {
+	if l.MinATR < 0 || l.MaxATR < 0 || l.MaxSpread < 0 {
+		return fmt.Errorf("negative limit")
+	}
+	if l.MaxATR > 0 && l.MinATR > l.MaxATR {
+		return fmt.Errorf("min ATR %g above max %g", l.MinATR, l.MaxATR)
+	}
+	for _, p := range []float64{l.MinRangePercentile, l.MaxRangePercentile} {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("range percentile %g outside 0-100", p)
+		}
+	}
+	if l.MaxRangePercentile > 0 && l.MinRangePercentile > l.MaxRangePercentile {
+		return fmt.Errorf("min range percentile %g above max %g", l.MinRangePercentile, l.MaxRangePercentile)
+	}
+	return nil
+}
+
+// RegimeFilterConfig configures a RegimeFilter.
+type RegimeFilterConfig struct {
+	// Default applies to symbols without an entry in Symbols.
+	Default RegimeLimits
+	// Symbols overrides Default per symbol. Keys may use any common
+	// spelling, e.g. "EUR/USD" or "EURUSD".
+	Symbols map[string]RegimeLimits
+	// ATRPeriod is the ATR lookback. Defaults to 14.
+	ATRPeriod int
+	// Action selects veto or down-weighting.
+	Action RegimeAction
+	// Weight scales confidence under RegimeDownWeight. Defaults to 0.5.
+	Weight float64
+	// Instruments supplies pip sizes for the limits.
+	Instruments *entity.InstrumentRegistry
+}
+
+func (c RegimeFilterConfig) withDefaults() RegimeFilterConfig {
+	if c.ATRPeriod <= 0 {
+		c.ATRPeriod = 14
+	}
+	if c.Weight == 0 {
+		c.Weight = 0.5
+	}
+	return c
+}
+
+// RegimeVerdict is the outcome of evaluating a symbol's recent bars.
+type RegimeVerdict struct {
+	ATR             float64
+	RangePercentile float64
+	Spread          float64
+	// Reason describes the first violated limit. It is empty when the bars
+	// are inside the allowed regime.
+	Reason string
+}
+
+// OK reports whether no limit was violated.
+func (v RegimeVerdict) OK() bool {
+	return v.Reason == ""
+}
+
+// RegimeFilter vetoes or down-weights signals raised in dead-flat or overly
+// volatile markets, or while the spread is too wide.
+type RegimeFilter struct {
+	cfg     RegimeFilterConfig
+	symbols map[string]RegimeLimits
+}
+
+// NewRegimeFilter validates cfg and returns a RegimeFilter.
+func NewRegimeFilter(cfg RegimeFilterConfig) (*RegimeFilter, error) {
+	cfg = cfg.withDefaults()
+	if cfg.Weight < 0 || cfg.Weight > 1 {
+		return nil, fmt.Errorf("regime filter: weight %g outside 0-1", cfg.Weight)
+	}
+	if err := cfg.Default.validate(); err != nil {
+		return nil, fmt.Errorf("regime filter: default: %w", err)
+	}
+	f := &RegimeFilter{cfg: cfg, symbols: make(map[string]RegimeLimits, len(cfg.Symbols))}
+	for s, l := range cfg.Symbols {
+		if err := l.validate(); err != nil {
+			return nil, fmt.Errorf("regime filter: %s: %w", s, err)
+		}
+		f.symbols[entity.SymbolKey(s)] = l
+	}
+	return f, nil
+}
+
+// Evaluate measures ATR, the last bar's range percentile and spread over
+// candles and checks them against the limits for symbol. Too few candles for
+// the ATR period leave ATR limits unchecked.
+func (f *RegimeFilter) Evaluate(symbol string, candles []ports.Candle) RegimeVerdict {
+	var v RegimeVerdict
+	n := len(candles)
+	if n == 0 {
+		return v
+	}
+	limits, ok := f.symbols[entity.SymbolKey(symbol)]
+	if !ok {
+		limits = f.cfg.Default
+	}
+	scale := func(d float64) float64 { return d }
+	unit := ""
+	if inst, ok := f.cfg.Instruments.Lookup(symbol); ok {
+		scale = inst.Pips
+		unit = " pips"
+	}
+
+	atr := CalcATR(candles, f.cfg.ATRPeriod)
+	hasATR := n > f.cfg.ATRPeriod
+	v.ATR = scale(atr[n-1])
+	v.RangePercentile = rangePercentile(candles)
+	v.Spread = scale(candles[n-1].Spread)
+
+	switch {
+	case hasATR && limits.MinATR > 0 && v.ATR < limits.MinATR:
+		v.Reason = fmt.Sprintf("ATR %.2f%s below %g", v.ATR, unit, limits.MinATR)
+	case hasATR && limits.MaxATR > 0 && v.ATR > limits.MaxATR:
+		v.Reason = fmt.Sprintf("ATR %.2f%s above %g", v.ATR, unit, limits.MaxATR)
+	case limits.MinRangePercentile > 0 && v.RangePercentile < limits.MinRangePercentile:
+		v.Reason = fmt.Sprintf("range percentile %.0f below %g", v.RangePercentile, limits.MinRangePercentile)
+	case limits.MaxRangePercentile > 0 && v.RangePercentile > limits.MaxRangePercentile:
+		v.Reason = fmt.Sprintf("range percentile %.0f above %g", v.RangePercentile, limits.MaxRangePercentile)
+	case limits.MaxSpread > 0 && v.Spread > limits.MaxSpread:
+		v.Reason = fmt.Sprintf("spread %.2f%s above %g", v.Spread, unit, limits.MaxSpread)
+	}
+	return v
+}
+
+// Vetoes reports whether scoring should be skipped for v.
+func (f *RegimeFilter) Vetoes(v RegimeVerdict) bool {
+	return !v.OK() && f.cfg.Action == RegimeVeto
+}
+
+// Apply returns signals adjusted for v: unchanged inside the regime, dropped
+// under RegimeVeto and scaled and tagged under RegimeDownWeight.
+func (f *RegimeFilter) Apply(v RegimeVerdict, signals []entity.Signal) []entity.Signal {
+	if v.OK() || len(signals) == 0 {
+		return signals
+	}
+	if f.cfg.Action == RegimeVeto {
+		return nil
+	}
+	tag := RegimeTag(v)
+	out := make([]entity.Signal, len(signals))
+	for i, s := range signals {
+		s.Confidence *= f.cfg.Weight
+		s.Tags = append(append([]string(nil), s.Tags...), tag)
+		out[i] = s
+	}
+	return out
+}
+
+// RegimeTag formats v as a signal tag.
+func RegimeTag(v RegimeVerdict) string {
+	return "regime: " + v.Reason
+}
+
+// rangePercentile returns the percentile rank (0-100) of the last candle's
+// high-low range among all candles.
+func rangePercentile(candles []ports.Candle) float64 {
+	last := candles[len(candles)-1]
+	r := last.High - last.Low
+	var below int
+	for _, c := range candles {
+		if c.High-c.Low <= r {
+			below++
+		}
+	}
+	return math.Round(float64(below)/float64(len(candles))*1000) / 10
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// regimeSeries returns 20 bars around 1.0 with the given high-low range; the
+// last bar has lastRange and spread.
+func regimeSeries(symbol string, rng, lastRange, spread float64) []ports.Candle {
+	base := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	candles := make([]ports.Candle, 20)
+	for i := range candles {
+		r := rng
+		if i == len(candles)-1 {
+			r = lastRange
+		}
+		candles[i] = ports.Candle{Symbol: symbol, Time: base.Add(time.Duration(i) * time.Minute), Open: 1, High: 1 + r/2, Low: 1 - r/2, Close: 1}
+	}
+	candles[len(candles)-1].Spread = spread
+	return candles
+}
+
+func TestRegimeFilter_Evaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RegimeFilterConfig
+		symbol  string
+		candles []ports.Candle
+		reason  string
+	}{
+		{
+			name:    "inside regime",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MinATR: 2, MaxATR: 50}},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.001, 0.001, 0),
+		},
+		{
+			name:    "dead flat market",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MinATR: 2}},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.0001, 0.0001, 0),
+			reason:  "ATR 1.00 pips below 2",
+		},
+		{
+			name:    "too volatile",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MaxATR: 50}},
+			symbol:  "EUR/USD",
+			candles: regimeSeries("EUR/USD", 0.01, 0.01, 0),
+			reason:  "ATR 100.00 pips above 50",
+		},
+		{
+			name:    "unregistered symbol uses price units",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MinATR: 0.5}},
+			symbol:  "FOO",
+			candles: regimeSeries("FOO", 0.25, 0.25, 0),
+			reason:  "ATR 0.25 below 0.5",
+		},
+		{
+			name:    "narrow last bar",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MinRangePercentile: 20}},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.001, 0.0001, 0),
+			reason:  "range percentile 5 below 20",
+		},
+		{
+			name:    "wide spread",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MaxSpread: 3}},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.001, 0.001, 0.0005),
+			reason:  "spread 5.00 pips above 3",
+		},
+		{
+			name:    "missing spread ignored",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MaxSpread: 3}},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.001, 0.001, 0),
+		},
+		{
+			name: "per-symbol override",
+			cfg: RegimeFilterConfig{
+				Default: RegimeLimits{MinATR: 2},
+				Symbols: map[string]RegimeLimits{"EUR/USD": {MinATR: 0.5}},
+			},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.0001, 0.0001, 0),
+		},
+		{
+			name:    "too few bars for atr",
+			cfg:     RegimeFilterConfig{Default: RegimeLimits{MinATR: 2}, ATRPeriod: 30},
+			symbol:  "EURUSD",
+			candles: regimeSeries("EURUSD", 0.0001, 0.0001, 0),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Instruments = entity.DefaultInstruments()
+			f, err := NewRegimeFilter(tt.cfg)
+			if err != nil {
+				t.Fatalf("new regime filter: %v", err)
+			}
+			if got := f.Evaluate(tt.symbol, tt.candles); got.Reason != tt.reason {
+				t.Fatalf("expected reason %q, got %+v", tt.reason, got)
+			}
+		})
+	}
+}
+
+func TestRegimeFilter_Apply(t *testing.T) {
+	signals := []entity.Signal{{Symbol: "EURUSD", Direction: "UP", Confidence: 0.8}}
+	flat := regimeSeries("EURUSD", 0.0001, 0.0001, 0)
+
+	veto, err := NewRegimeFilter(RegimeFilterConfig{Default: RegimeLimits{MinATR: 2}, Instruments: entity.DefaultInstruments()})
+	if err != nil {
+		t.Fatalf("new regime filter: %v", err)
+	}
+	v := veto.Evaluate("EURUSD", flat)
+	if !veto.Vetoes(v) || veto.Apply(v, signals) != nil {
+		t.Fatalf("expected veto for %+v", v)
+	}
+
+	down, err := NewRegimeFilter(RegimeFilterConfig{Default: RegimeLimits{MinATR: 2}, Action: RegimeDownWeight, Weight: 0.25, Instruments: entity.DefaultInstruments()})
+	if err != nil {
+		t.Fatalf("new regime filter: %v", err)
+	}
+	v = down.Evaluate("EURUSD", flat)
+	if down.Vetoes(v) {
+		t.Fatalf("down-weight must not veto")
+	}
+	want := []entity.Signal{{Symbol: "EURUSD", Direction: "UP", Confidence: 0.2, Tags: []string{"regime: ATR 1.00 pips below 2"}}}
+	if got := down.Apply(v, signals); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if signals[0].Confidence != 0.8 || signals[0].Tags != nil {
+		t.Fatalf("input signals must not be modified")
+	}
+}
+
+func TestNewRegimeFilter_Invalid(t *testing.T) {
+	for name, cfg := range map[string]RegimeFilterConfig{
+		"min above max":      {Default: RegimeLimits{MinATR: 5, MaxATR: 2}},
+		"percentile range":   {Default: RegimeLimits{MaxRangePercentile: 120}},
+		"negative spread":    {Symbols: map[string]RegimeLimits{"EURUSD": {MaxSpread: -1}}},
+		"weight above one":   {Weight: 1.5},
+		"percentile min>max": {Default: RegimeLimits{MinRangePercentile: 80, MaxRangePercentile: 20}},
+	} {
+		if _, err := NewRegimeFilter(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"log/slog"
 	"math"
-	"time"
 
 	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
@@ -13,41 +12,38 @@ import (
 // ScoreRSIDivergence looks for RSI divergence reversal setups over the provided candles and rsi values.
 // It returns binary trade signals with a typical TTL of 2 minutes.
 func ScoreRSIDivergence(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle, rsi []float64) []entity.Signal {
+	return ScoreRSIDivergenceWithParams(ctx, logger, symbol, candles, rsi, entity.RSIDivergenceParams{})
+}
+
+// ScoreRSIDivergenceWithParams behaves like ScoreRSIDivergence with the
+// lookback, reversal patterns, confidence and TTL set by p. Unset fields
+// take their defaults.
+func ScoreRSIDivergenceWithParams(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle, rsi []float64, p entity.RSIDivergenceParams) []entity.Signal {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	logger.InfoContext(ctx, "score RSI divergence", "symbol", symbol)
+	p = p.WithDefaults()
 
 	n := len(candles)
-	if n < 20 || n != len(rsi) {
+	if n < p.Lookback || n != len(rsi) {
 		logger.WarnContext(ctx, "insufficient data", "candles", n, "rsi_len", len(rsi))
 		return nil
 	}
 
-	// Use last 20 bars for analysis
-	start := n - 20
+	// Use the last Lookback bars for analysis
+	start := n - p.Lookback
 	c := candles[start:]
 	r := rsi[start:]
 
-	// Find previous swing high/low excluding last 3 bars
-	lookback := len(c) - 3
+	// Find previous swing high/low excluding the reversal bars
+	lookback := len(c) - p.ReversalBars
 	if lookback <= 0 {
 		logger.WarnContext(ctx, "insufficient candles for swing lookup")
 		return nil
 	}
 
-	prevHighIdx, prevLowIdx := 0, 0
-	var highFound, lowFound bool
-	for i := 1; i < lookback; i++ {
-		if c[i].High > c[prevHighIdx].High {
-			prevHighIdx = i
-			highFound = true
-		}
-		if c[i].Low < c[prevLowIdx].Low {
-			prevLowIdx = i
-			lowFound = true
-		}
-	}
+	prevHighIdx, prevLowIdx, highFound, lowFound := divergenceSwings(c, p.ReversalBars)
 	if !highFound && !lowFound {
 		logger.WarnContext(ctx, "no swing points found")
 		return nil
@@ -58,13 +54,13 @@ func ScoreRSIDivergence(ctx context.Context, logger *slog.Logger, symbol string,
 
 	// Bearish divergence: price higher high but RSI lower high
 	if c[latest].High > c[prevHighIdx].High && r[latest] < r[prevHighIdx] {
-		dir := revDir(ctx, logger, c[len(c)-3:])
+		dir := revDir(ctx, logger, c[lookback:], p.PinBar)
 		if dir == "DOWN" {
 			signals = append(signals, entity.Signal{
 				Symbol:     symbol,
 				Direction:  "DOWN",
-				Confidence: 0.8,
-				TTL:        2 * time.Minute,
+				Confidence: p.Confidence,
+				TTL:        p.TTL,
 			})
 		} else {
 			logger.InfoContext(ctx, "divergence without reversal", "expected", "DOWN", "got", dir)
@@ -73,13 +69,13 @@ func ScoreRSIDivergence(ctx context.Context, logger *slog.Logger, symbol string,
 
 	// Bullish divergence: price lower low but RSI higher low
 	if c[latest].Low < c[prevLowIdx].Low && r[latest] > r[prevLowIdx] {
-		dir := revDir(ctx, logger, c[len(c)-3:])
+		dir := revDir(ctx, logger, c[lookback:], p.PinBar)
 		if dir == "UP" {
 			signals = append(signals, entity.Signal{
 				Symbol:     symbol,
 				Direction:  "UP",
-				Confidence: 0.8,
-				TTL:        2 * time.Minute,
+				Confidence: p.Confidence,
+				TTL:        p.TTL,
 			})
 		} else {
 			logger.InfoContext(ctx, "divergence without reversal", "expected", "UP", "got", dir)
@@ -89,43 +85,79 @@ func ScoreRSIDivergence(ctx context.Context, logger *slog.Logger, symbol string,
 	return signals
 }
 
-// revDir checks the last up to 3 candles for a reversal pattern and returns "UP", "DOWN", or "".
-func revDir(ctx context.Context, logger *slog.Logger, c []ports.Candle) string {
+// divergenceSwings returns the highest high and the lowest low of c, excluding
+// its last reversalBars bars, and whether either lies past the first bar.
+func divergenceSwings(c []ports.Candle, reversalBars int) (highIdx, lowIdx int, highFound, lowFound bool) {
+	for i := 1; i < len(c)-reversalBars; i++ {
+		if c[i].High > c[highIdx].High {
+			highIdx = i
+			highFound = true
+		}
+		if c[i].Low < c[lowIdx].Low {
+			lowIdx = i
+			lowFound = true
+		}
+	}
+	return highIdx, lowIdx, highFound, lowFound
+}
+
+// revDir checks c, the reversal bars, for a reversal pattern and returns "UP", "DOWN", or "".
+func revDir(ctx context.Context, logger *slog.Logger, c []ports.Candle, pin entity.PinBarParams) string {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	n := len(c)
-	for i := n - 1; i >= 0; i-- {
-		if isBullishEngulfing(c, i) || isBullishPinBar(c[i]) {
-			return "UP"
-		}
-		if isBearishEngulfing(c, i) || isBearishPinBar(c[i]) {
-			return "DOWN"
-		}
+	if _, dir, _ := reversal(c, pin); dir != "" {
+		return dir
 	}
 	logger.DebugContext(ctx, "no reversal pattern found")
 	return ""
 }
 
+// reversal finds the latest engulfing or pin bar in c and returns its index,
+// direction and marker kind. The index is -1 when there is none.
+func reversal(c []ports.Candle, pin entity.PinBarParams) (int, string, string) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if dir, kind := reversalAt(c, i, pin); dir != "" {
+			return i, dir, kind
+		}
+	}
+	return -1, "", ""
+}
+
+// reversalAt reports the reversal pattern completed by c[i], if any.
+func reversalAt(c []ports.Candle, i int, pin entity.PinBarParams) (dir, kind string) {
+	switch {
+	case isBullishEngulfing(c, i):
+		return "UP", MarkerEngulfing
+	case isBullishPinBar(c[i], pin):
+		return "UP", MarkerPinBar
+	case isBearishEngulfing(c, i):
+		return "DOWN", MarkerEngulfing
+	case isBearishPinBar(c[i], pin):
+		return "DOWN", MarkerPinBar
+	}
+	return "", ""
+}
+
 func body(c ports.Candle) float64      { return math.Abs(c.Close - c.Open) }
 func rangeSize(c ports.Candle) float64 { return c.High - c.Low }
 
-func isBullishPinBar(c ports.Candle) bool {
+func isBullishPinBar(c ports.Candle, pin entity.PinBarParams) bool {
 	r := rangeSize(c)
 	if r == 0 {
 		return false
 	}
 	lowerWick := math.Min(c.Open, c.Close) - c.Low
-	return body(c) <= r/3 && lowerWick >= r*2/3
+	return body(c) <= r*pin.MaxBody && lowerWick >= r*pin.MinWick
 }
 
-func isBearishPinBar(c ports.Candle) bool {
+func isBearishPinBar(c ports.Candle, pin entity.PinBarParams) bool {
 	r := rangeSize(c)
 	if r == 0 {
 		return false
 	}
 	upperWick := c.High - math.Max(c.Open, c.Close)
-	return body(c) <= r/3 && upperWick >= r*2/3
+	return body(c) <= r*pin.MaxBody && upperWick >= r*pin.MinWick
 }
 
 func isBullishEngulfing(c []ports.Candle, i int) bool {
@@ -4,49 +4,105 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 )
 
+// Scorer names reported to metrics.
+const (
+	ScorerRSIDivergence  = "rsi_divergence"
+	ScorerEMAInteraction = "ema_interaction"
+	ScorerCandlestick    = "candlestick"
+)
+
 // ScanSignalPatterns aggregates various scoring algorithms over recent market data.
 // It returns unique signals or an error if the input is invalid.
 func ScanSignalPatterns(ctx context.Context, logger *slog.Logger, symbol string, candles []ports.Candle, rsi, ema8, ema21 []float64) ([]entity.Signal, error) {
+	return ScanSignalPatternsWithMetrics(ctx, logger, nil, symbol, candles, rsi, ema8, ema21)
+}
+
+// ScanSignalPatternsWithMetrics behaves like ScanSignalPatterns and records
+// scan latency and the signals produced by each scorer on metrics.
+func ScanSignalPatternsWithMetrics(ctx context.Context, logger *slog.Logger, metrics ports.MetricsRecorder, symbol string, candles []ports.Candle, rsi, ema8, ema21 []float64) ([]entity.Signal, error) {
+	return ScanSignalPatternsWithParams(ctx, logger, metrics, symbol, candles, rsi, ema8, ema21, entity.ScorerParams{})
+}
+
+// ScanSignalPatternsWithParams behaves like ScanSignalPatternsWithMetrics
+// with the scorers configured by p. ema8 and ema21 are the EMAs of p's fast
+// and slow periods, as computed by CalcIndicators. It needs the divergence
+// lookback in candles, and p.MinBars() for warmed-up indicators. It returns
+// an error when p is invalid.
+func ScanSignalPatternsWithParams(ctx context.Context, logger *slog.Logger, metrics ports.MetricsRecorder, symbol string, candles []ports.Candle, rsi, ema8, ema21 []float64, p entity.ScorerParams) ([]entity.Signal, error) {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	if metrics == nil {
+		metrics = ports.NopMetrics{}
+	}
+	start := time.Now()
+	defer func() { metrics.ScanLatency(time.Since(start)) }()
 	logger.InfoContext(ctx, "scan signal patterns", "symbol", symbol)
 
+	if err := p.Validate(); err != nil {
+		logger.ErrorContext(ctx, "scan patterns", "error", err)
+		return nil, err
+	}
+	p = p.WithDefaults()
+
 	n := len(candles)
-	if n < 20 || n != len(rsi) || n != len(ema8) || n != len(ema21) {
+	if n < p.RSIDivergence.Lookback || n != len(rsi) || n != len(ema8) || n != len(ema21) {
 		err := fmt.Errorf("invalid input lengths")
 		logger.ErrorContext(ctx, "scan patterns", "error", err, "candles", n, "rsi_len", len(rsi), "ema8_len", len(ema8), "ema21_len", len(ema21))
 		return nil, err
 	}
 
-	rsiSigs := ScoreRSIDivergence(ctx, logger, symbol, candles, rsi)
-	emaSigs := ScoreEMAInteractions(ctx, logger, symbol, candles, ema8, ema21)
-	candleSigs := ScoreCandlestickPatterns(ctx, logger, symbol, candles)
+	rsiSigs := ScoreRSIDivergenceWithParams(ctx, logger, symbol, candles, rsi, p.RSIDivergence)
+	emaSigs := ScoreEMAInteractionsWithParams(ctx, logger, symbol, candles, ema8, ema21, p.EMAInteraction)
+	candleSigs := ScoreCandlestickPatternsWithParams(ctx, logger, symbol, candles, p.Candlestick)
+
+	for scorer, sigs := range map[string][]entity.Signal{
+		ScorerRSIDivergence:  rsiSigs,
+		ScorerEMAInteraction: emaSigs,
+		ScorerCandlestick:    candleSigs,
+	} {
+		for _, s := range sigs {
+			metrics.SignalProduced(scorer, s.Direction)
+		}
+	}
 
 	merged := make([]entity.Signal, 0, len(rsiSigs)+len(emaSigs)+len(candleSigs))
 	seen := map[string]struct{}{}
-	add := func(s entity.Signal) {
-		key := fmt.Sprintf("%s|%s|%d", s.Symbol, s.Direction, s.TTL)
-		if _, ok := seen[key]; ok {
-			return
+	add := func(scorer string, sigs []entity.Signal) {
+		for _, s := range sigs {
+			key := fmt.Sprintf("%s|%s|%d", s.Symbol, s.Direction, s.TTL)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			s.Source = scorer
+			merged = append(merged, s)
 		}
-		seen[key] = struct{}{}
-		merged = append(merged, s)
-	}
-	for _, s := range rsiSigs {
-		add(s)
 	}
-	for _, s := range emaSigs {
-		add(s)
-	}
-	for _, s := range candleSigs {
-		add(s)
+	add(ScorerRSIDivergence, rsiSigs)
+	add(ScorerEMAInteraction, emaSigs)
+	add(ScorerCandlestick, candleSigs)
+	for i := range merged {
+		merged[i].Price = candles[n-1].Close
+		merged[i].Time = candles[n-1].Time
 	}
 
 	return merged, nil
 }
+
+// CalcIndicators computes the RSI and the fast and slow EMAs that p
+// configures over the closes of candles.
+func CalcIndicators(candles []ports.Candle, p entity.ScorerParams) (rsi, emaFast, emaSlow []float64) {
+	p = p.WithDefaults()
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	return CalcRSI(closes, p.RSIDivergence.RSIPeriod), CalcEMA(closes, p.EMAInteraction.FastPeriod), CalcEMA(closes, p.EMAInteraction.SlowPeriod)
+}
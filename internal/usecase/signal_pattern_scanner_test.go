@@ -2,8 +2,11 @@ package usecase
 
 import (
 	"context"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/nomenarkt/signalengine/internal/entity"
 	"github.com/nomenarkt/signalengine/internal/ports"
 	"github.com/nomenarkt/signalengine/internal/testutils"
 )
@@ -15,17 +18,20 @@ func TestScanSignalPatterns(t *testing.T) {
 		name    string
 		data    func() ([]ports.Candle, []float64, []float64, []float64)
 		want    int
+		sources []string
 		wantErr bool
 	}{
 		{
-			name: "distinct",
-			data: testutils.MakeScannerDistinctData,
-			want: 3,
+			name:    "distinct",
+			data:    testutils.MakeScannerDistinctData,
+			want:    3,
+			sources: []string{ScorerRSIDivergence, ScorerEMAInteraction, ScorerCandlestick},
 		},
 		{
-			name: "duplicates",
-			data: testutils.MakeScannerDuplicateData,
-			want: 1,
+			name:    "duplicates",
+			data:    testutils.MakeScannerDuplicateData,
+			want:    1,
+			sources: []string{ScorerEMAInteraction},
 		},
 		{
 			name: "empty",
@@ -63,6 +69,88 @@ func TestScanSignalPatterns(t *testing.T) {
 			if len(sigs) != tt.want {
 				t.Fatalf("expected %d signals, got %d", tt.want, len(sigs))
 			}
+			for i, s := range sigs {
+				if s.Source != tt.sources[i] {
+					t.Errorf("signal %d source = %q, want %q", i, s.Source, tt.sources[i])
+				}
+			}
 		})
 	}
 }
+
+func TestScanSignalPatternsWithMetrics(t *testing.T) {
+	candles, rsi, ema8, ema21 := testutils.MakeScannerDuplicateData()
+	metrics := testutils.NewMockMetrics()
+
+	signals, err := ScanSignalPatternsWithMetrics(context.Background(), nil, metrics, "EURUSD", candles, rsi, ema8, ema21)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(signals) != 1 {
+		t.Fatalf("expected 1 signal, got %d", len(signals))
+	}
+	if metrics.ScanObserved != 1 {
+		t.Fatalf("expected 1 scan observation, got %d", metrics.ScanObserved)
+	}
+	want := map[string]int{
+		ScorerEMAInteraction + "|UP": 1,
+		ScorerCandlestick + "|UP":    1,
+	}
+	for k, v := range want {
+		if metrics.Signals[k] != v {
+			t.Errorf("expected %d signals for %s, got %d", v, k, metrics.Signals[k])
+		}
+	}
+}
+
+func TestScanSignalPatternsWithParams(t *testing.T) {
+	ctx := context.Background()
+	candles, rsi, ema8, ema21 := testutils.MakeScannerDistinctData()
+	p := entity.ScorerParams{
+		RSIDivergence:  entity.RSIDivergenceParams{Confidence: 0.9, TTL: 5 * time.Minute},
+		EMAInteraction: entity.EMAInteractionParams{Confidence: 0.7, TTL: 3 * time.Minute},
+		Candlestick:    entity.CandlestickParams{Confidence: 0.4, TTL: 4 * time.Minute},
+	}
+	sigs, err := ScanSignalPatternsWithParams(ctx, nil, nil, "EURUSD", candles, rsi, ema8, ema21, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct {
+		confidence float64
+		ttl        time.Duration
+	}{
+		ScorerRSIDivergence:  {0.9, 5 * time.Minute},
+		ScorerEMAInteraction: {0.7, 3 * time.Minute},
+		ScorerCandlestick:    {0.4, 4 * time.Minute},
+	}
+	if len(sigs) != len(want) {
+		t.Fatalf("expected %d signals, got %+v", len(want), sigs)
+	}
+	for _, s := range sigs {
+		if w := want[s.Source]; s.Confidence != w.confidence || s.TTL != w.ttl {
+			t.Errorf("%s: expected confidence %v and TTL %v, got %v and %v", s.Source, w.confidence, w.ttl, s.Confidence, s.TTL)
+		}
+	}
+
+	for name, p := range map[string]entity.ScorerParams{
+		"invalid":        {EMAInteraction: entity.EMAInteractionParams{FastPeriod: 30}},
+		"short lookback": {RSIDivergence: entity.RSIDivergenceParams{Lookback: 30}},
+	} {
+		if _, err := ScanSignalPatternsWithParams(ctx, nil, nil, "EURUSD", candles, rsi, ema8, ema21, p); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCalcIndicators(t *testing.T) {
+	candles, _, _, _ := testutils.MakeScannerDistinctData()
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	p := entity.ScorerParams{RSIDivergence: entity.RSIDivergenceParams{RSIPeriod: 7}, EMAInteraction: entity.EMAInteractionParams{FastPeriod: 5, SlowPeriod: 13}}
+	rsi, fast, slow := CalcIndicators(candles, p)
+	if !slices.Equal(rsi, CalcRSI(closes, 7)) || !slices.Equal(fast, CalcEMA(closes, 5)) || !slices.Equal(slow, CalcEMA(closes, 13)) {
+		t.Fatal("expected indicators with the configured periods")
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// Setup marker kinds.
+const (
+	MarkerSwingHigh = "swing_high"
+	MarkerSwingLow  = "swing_low"
+	MarkerEngulfing = "engulfing"
+	MarkerPinBar    = "pin_bar"
+	MarkerEMACross  = "ema_cross"
+)
+
+// SetupMarker points at a bar behind a signal.
+type SetupMarker struct {
+	// Kind is one of the Marker constants.
+	Kind string
+	// Index is the bar's position in the candles passed to SignalSetup.
+	Index int
+	// Price places the marker on the price axis: the swing or the pattern
+	// bar's extreme, or the EMA8 at a crossover.
+	Price float64
+	// RSI is the RSI at swing markers.
+	RSI float64
+}
+
+// SignalSetup locates the bars behind s, a signal raised by
+// ScanSignalPatterns on the last of candles with the given indicators. RSI
+// divergences yield both swing points and the reversal bar, candlestick
+// signals the pattern bar and EMA signals the crossover. It returns nil when
+// the setup cannot be found, e.g. for a signal with another Source.
+func SignalSetup(s entity.Signal, candles []ports.Candle, rsi, ema8, ema21 []float64) []SetupMarker {
+	return SignalSetupWithParams(s, candles, rsi, ema8, ema21, entity.ScorerParams{})
+}
+
+// SignalSetupWithParams behaves like SignalSetup for a signal raised by
+// ScanSignalPatternsWithParams with p.
+func SignalSetupWithParams(s entity.Signal, candles []ports.Candle, rsi, ema8, ema21 []float64, p entity.ScorerParams) []SetupMarker {
+	p = p.WithDefaults()
+	n := len(candles)
+	if n < 2 {
+		return nil
+	}
+	last := n - 1
+	switch s.Source {
+	case ScorerRSIDivergence:
+		start := n - p.RSIDivergence.Lookback
+		if start < 0 || len(rsi) != n {
+			return nil
+		}
+		return divergenceSetup(s.Direction, candles[start:], rsi[start:], start, p.RSIDivergence)
+	case ScorerCandlestick:
+		dir, kind := reversalAt(candles, last, p.Candlestick.PinBar)
+		if dir != s.Direction {
+			return nil
+		}
+		return []SetupMarker{patternMarker(candles[last], last, dir, kind)}
+	case ScorerEMAInteraction:
+		if len(ema8) != n || len(ema21) != n {
+			return nil
+		}
+		up := ema8[last-1] <= ema21[last-1] && ema8[last] > ema21[last]
+		down := ema8[last-1] >= ema21[last-1] && ema8[last] < ema21[last]
+		if (s.Direction == "UP" && !up) || (s.Direction == "DOWN" && !down) {
+			return nil
+		}
+		return []SetupMarker{{Kind: MarkerEMACross, Index: last, Price: ema8[last]}}
+	}
+	return nil
+}
+
+// divergenceSetup mirrors ScoreRSIDivergenceWithParams on the window c, which
+// starts at offset in the caller's candles.
+func divergenceSetup(direction string, c []ports.Candle, r []float64, offset int, p entity.RSIDivergenceParams) []SetupMarker {
+	highIdx, lowIdx, highFound, lowFound := divergenceSwings(c, p.ReversalBars)
+	if !highFound && !lowFound {
+		return nil
+	}
+	latest := len(c) - 1
+	var markers []SetupMarker
+	switch {
+	case direction == "DOWN" && c[latest].High > c[highIdx].High && r[latest] < r[highIdx]:
+		markers = []SetupMarker{
+			{Kind: MarkerSwingHigh, Index: offset + highIdx, Price: c[highIdx].High, RSI: r[highIdx]},
+			{Kind: MarkerSwingHigh, Index: offset + latest, Price: c[latest].High, RSI: r[latest]},
+		}
+	case direction == "UP" && c[latest].Low < c[lowIdx].Low && r[latest] > r[lowIdx]:
+		markers = []SetupMarker{
+			{Kind: MarkerSwingLow, Index: offset + lowIdx, Price: c[lowIdx].Low, RSI: r[lowIdx]},
+			{Kind: MarkerSwingLow, Index: offset + latest, Price: c[latest].Low, RSI: r[latest]},
+		}
+	default:
+		return nil
+	}
+	tail := len(c) - p.ReversalBars
+	if i, dir, kind := reversal(c[tail:], p.PinBar); dir == direction {
+		markers = append(markers, patternMarker(c[tail+i], offset+tail+i, dir, kind))
+	}
+	return markers
+}
+
+// patternMarker marks a reversal bar below its low for UP and above its high
+// for DOWN.
+func patternMarker(c ports.Candle, index int, direction, kind string) SetupMarker {
+	price := c.High
+	if direction == "UP" {
+		price = c.Low
+	}
+	return SetupMarker{Kind: kind, Index: index, Price: price}
+}
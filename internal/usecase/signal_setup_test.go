@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestSignalSetup(t *testing.T) {
+	candles, rsi, ema8, ema21 := testutils.MakeScannerDistinctData()
+	pin := candles[len(candles)-1]
+	pin.Open, pin.Close, pin.High, pin.Low = 0.88, 0.9, 0.9, 0.4
+	pinCandles := append(candles[:len(candles)-1:len(candles)-1], pin)
+
+	tests := []struct {
+		name    string
+		signal  entity.Signal
+		candles bool
+		want    []SetupMarker
+	}{
+		{
+			name:   "bullish divergence",
+			signal: entity.Signal{Direction: "UP", Source: ScorerRSIDivergence},
+			want: []SetupMarker{
+				{Kind: MarkerSwingLow, Index: 16, Price: 0.5, RSI: 30},
+				{Kind: MarkerSwingLow, Index: 19, Price: 0.4, RSI: 40},
+				{Kind: MarkerEngulfing, Index: 19, Price: 0.4},
+			},
+		},
+		{
+			name:   "bullish engulfing",
+			signal: entity.Signal{Direction: "UP", Source: ScorerCandlestick},
+			want:   []SetupMarker{{Kind: MarkerEngulfing, Index: 19, Price: 0.4}},
+		},
+		{
+			name:    "bullish pin bar",
+			signal:  entity.Signal{Direction: "UP", Source: ScorerCandlestick},
+			candles: true,
+			want:    []SetupMarker{{Kind: MarkerPinBar, Index: 19, Price: 0.4}},
+		},
+		{
+			name:   "bearish ema cross",
+			signal: entity.Signal{Direction: "DOWN", Source: ScorerEMAInteraction},
+			want:   []SetupMarker{{Kind: MarkerEMACross, Index: 19, Price: 0.85}},
+		},
+		{name: "no bearish divergence", signal: entity.Signal{Direction: "DOWN", Source: ScorerRSIDivergence}},
+		{name: "no bullish cross", signal: entity.Signal{Direction: "UP", Source: ScorerEMAInteraction}},
+		{name: "unknown source", signal: entity.Signal{Direction: "UP"}},
+	}
+	for _, tt := range tests {
+		c := candles
+		if tt.candles {
+			c = pinCandles
+		}
+		if got := SignalSetup(tt.signal, c, rsi, ema8, ema21); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSignalSetup_ScannedSignals(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	candles, rsi, ema8, ema21 := testutils.MakeScannerDistinctData()
+	signals, err := ScanSignalPatterns(context.Background(), logger, "EURUSD", candles, rsi, ema8, ema21)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range signals {
+		if SignalSetup(s, candles, rsi, ema8, ema21) == nil {
+			t.Errorf("no setup found for %s %s", s.Source, s.Direction)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+// Reasons reported for signals dropped by a SignalThrottle.
+const (
+	SuppressCooldown  = "cooldown"
+	SuppressActiveTTL = "active_ttl"
+	SuppressRateLimit = "rate_limit"
+)
+
+// ThrottleConfig configures a SignalThrottle. Zero values disable the
+// corresponding check.
+type ThrottleConfig struct {
+	// Cooldown is the minimum time between signals for the same symbol and
+	// direction.
+	Cooldown time.Duration
+	// MaxSignals caps the signals published across all symbols within
+	// Window.
+	MaxSignals int
+	// Window is the span MaxSignals applies to. Defaults to one hour.
+	Window time.Duration
+	// SuppressWithinTTL drops signals for a symbol while a previously
+	// published signal on that symbol has not expired.
+	SuppressWithinTTL bool
+}
+
+func (c ThrottleConfig) withDefaults() ThrottleConfig {
+	if c.Window <= 0 {
+		c.Window = time.Hour
+	}
+	return c
+}
+
+// SuppressedSignal is a signal dropped by a SignalThrottle.
+type SuppressedSignal struct {
+	Signal entity.Signal
+	Reason string
+}
+
+type throttleKey struct {
+	symbol    string
+	direction string
+}
+
+// SignalThrottle rate-limits published signals. Times passed to it are bar
+// times, so replays and live runs behave the same. It is safe for concurrent
+// use.
+type SignalThrottle struct {
+	cfg ThrottleConfig
+
+	mu     sync.Mutex
+	last   map[throttleKey]time.Time
+	expiry map[string]time.Time
+	sent   []time.Time
+}
+
+// NewSignalThrottle returns a SignalThrottle configured by cfg.
+func NewSignalThrottle(cfg ThrottleConfig) *SignalThrottle {
+	return &SignalThrottle{
+		cfg:    cfg.withDefaults(),
+		last:   make(map[throttleKey]time.Time),
+		expiry: make(map[string]time.Time),
+	}
+}
+
+// Filter splits signals raised at t into those that may be published and
+// those suppressed by a cooldown, an unexpired signal or the global cap. It
+// does not record anything; call Record once the allowed signals are
+// published.
+func (t *SignalThrottle) Filter(at time.Time, signals []entity.Signal) (allowed []entity.Signal, suppressed []SuppressedSignal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(at)
+
+	budget := t.cfg.MaxSignals - len(t.sent)
+	for _, s := range signals {
+		symbol := entity.SymbolKey(s.Symbol)
+		last, seen := t.last[throttleKey{symbol, s.Direction}]
+		var reason string
+		switch {
+		case t.cfg.Cooldown > 0 && seen && at.Sub(last) < t.cfg.Cooldown:
+			reason = SuppressCooldown
+		case t.cfg.SuppressWithinTTL && at.Before(t.expiry[symbol]):
+			reason = SuppressActiveTTL
+		case t.cfg.MaxSignals > 0 && budget <= 0:
+			reason = SuppressRateLimit
+		}
+		if reason != "" {
+			suppressed = append(suppressed, SuppressedSignal{Signal: s, Reason: reason})
+			continue
+		}
+		budget--
+		allowed = append(allowed, s)
+	}
+	return allowed, suppressed
+}
+
+// Record notes signals published at t.
+func (t *SignalThrottle) Record(at time.Time, signals []entity.Signal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, s := range signals {
+		symbol := entity.SymbolKey(s.Symbol)
+		t.last[throttleKey{symbol, s.Direction}] = at
+		if exp := at.Add(s.TTL); exp.After(t.expiry[symbol]) {
+			t.expiry[symbol] = exp
+		}
+		t.sent = append(t.sent, at)
+	}
+}
+
+// prune forgets publish times that fell out of the window ending at at.
+func (t *SignalThrottle) prune(at time.Time) {
+	cutoff := at.Add(-t.cfg.Window)
+	kept := t.sent[:0]
+	for _, ts := range t.sent {
+		if ts.After(cutoff) {
+			kept = append(kept, ts)
+		}
+	}
+	t.sent = kept
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/entity"
+)
+
+func TestSignalThrottle_Filter(t *testing.T) {
+	t0 := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	up := entity.Signal{Symbol: "EURUSD", Direction: "UP", TTL: 5 * time.Minute}
+	down := entity.Signal{Symbol: "EURUSD", Direction: "DOWN", TTL: 5 * time.Minute}
+	gbp := entity.Signal{Symbol: "GBPUSD", Direction: "UP", TTL: 5 * time.Minute}
+
+	tests := []struct {
+		name    string
+		cfg     ThrottleConfig
+		at      time.Time
+		signals []entity.Signal
+		allowed []entity.Signal
+		reasons []string
+	}{
+		{name: "disabled", at: t0.Add(time.Minute), signals: []entity.Signal{up}, allowed: []entity.Signal{up}},
+		{
+			name:    "cooldown same direction",
+			cfg:     ThrottleConfig{Cooldown: 10 * time.Minute},
+			at:      t0.Add(9 * time.Minute),
+			signals: []entity.Signal{up, down},
+			allowed: []entity.Signal{down},
+			reasons: []string{SuppressCooldown},
+		},
+		{
+			name:    "cooldown elapsed",
+			cfg:     ThrottleConfig{Cooldown: 10 * time.Minute},
+			at:      t0.Add(10 * time.Minute),
+			signals: []entity.Signal{up},
+			allowed: []entity.Signal{up},
+		},
+		{
+			name:    "cooldown matches symbol spelling",
+			cfg:     ThrottleConfig{Cooldown: 10 * time.Minute},
+			at:      t0.Add(time.Minute),
+			signals: []entity.Signal{{Symbol: "EUR/USD", Direction: "UP"}},
+			reasons: []string{SuppressCooldown},
+		},
+		{
+			name:    "within ttl",
+			cfg:     ThrottleConfig{SuppressWithinTTL: true},
+			at:      t0.Add(4 * time.Minute),
+			signals: []entity.Signal{down, gbp},
+			allowed: []entity.Signal{gbp},
+			reasons: []string{SuppressActiveTTL},
+		},
+		{
+			name:    "ttl expired",
+			cfg:     ThrottleConfig{SuppressWithinTTL: true},
+			at:      t0.Add(5 * time.Minute),
+			signals: []entity.Signal{down},
+			allowed: []entity.Signal{down},
+		},
+		{
+			name:    "global cap",
+			cfg:     ThrottleConfig{MaxSignals: 2, Window: time.Hour},
+			at:      t0.Add(30 * time.Minute),
+			signals: []entity.Signal{down, gbp},
+			allowed: []entity.Signal{down},
+			reasons: []string{SuppressRateLimit},
+		},
+		{
+			name:    "global cap window elapsed",
+			cfg:     ThrottleConfig{MaxSignals: 2, Window: time.Hour},
+			at:      t0.Add(time.Hour),
+			signals: []entity.Signal{down, gbp},
+			allowed: []entity.Signal{down, gbp},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			th := NewSignalThrottle(tt.cfg)
+			th.Record(t0, []entity.Signal{up})
+			allowed, suppressed := th.Filter(tt.at, tt.signals)
+			if !reflect.DeepEqual(allowed, tt.allowed) {
+				t.Fatalf("expected allowed %+v, got %+v", tt.allowed, allowed)
+			}
+			var reasons []string
+			for _, s := range suppressed {
+				reasons = append(reasons, s.Reason)
+			}
+			if !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Fatalf("expected reasons %v, got %v", tt.reasons, reasons)
+			}
+		})
+	}
+}
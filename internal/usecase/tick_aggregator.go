@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+)
+
+// LateTickPolicy controls how ticks for an already closed bar are handled.
+type LateTickPolicy int
+
+const (
+	// LateTickDrop discards ticks for closed bars.
+	LateTickDrop LateTickPolicy = iota
+	// LateTickMerge folds ticks for closed bars into the earliest open bar,
+	// widening its high, low and volume but keeping its open and close.
+	LateTickMerge
+)
+
+// Errors returned by TickAggregator.Add.
+var (
+	ErrInvalidTick = errors.New("invalid tick")
+	ErrLateTick    = ports.ErrLateTick
+)
+
+// TickAggregatorConfig configures bar boundaries and late-tick handling.
+type TickAggregatorConfig struct {
+	// Interval is the bar length. Defaults to one minute.
+	Interval time.Duration
+	// Offset shifts bar boundaries, e.g. 30s for bars starting at :30.
+	Offset time.Duration
+	// Grace keeps a bar open for ticks arriving this long after its end.
+	Grace time.Duration
+	// Late selects what happens to ticks for bars that are already closed.
+	Late LateTickPolicy
+	// FlushInterval is how often TickCandleFeed closes bars using the wall
+	// clock when no new ticks arrive. Defaults to Interval/4.
+	FlushInterval time.Duration
+	// EmitPartial makes TickCandleFeed emit the forming bar as a Partial
+	// candle after every accepted tick.
+	EmitPartial bool
+}
+
+func (c TickAggregatorConfig) withDefaults() (TickAggregatorConfig, error) {
+	if c.Interval == 0 {
+		c.Interval = time.Minute
+	}
+	if c.Interval < 0 || c.Offset < 0 || c.Grace < 0 || c.FlushInterval < 0 {
+		return c, errors.New("tick aggregator: negative duration")
+	}
+	if c.Offset >= c.Interval {
+		return c, fmt.Errorf("tick aggregator: offset %s must be shorter than interval %s", c.Offset, c.Interval)
+	}
+	if c.Grace >= c.Interval {
+		return c, fmt.Errorf("tick aggregator: grace %s must be shorter than interval %s", c.Grace, c.Interval)
+	}
+	if c.Late != LateTickDrop && c.Late != LateTickMerge {
+		return c, fmt.Errorf("tick aggregator: unknown late tick policy %d", c.Late)
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = c.Interval / 4
+	}
+	return c, nil
+}
+
+type symbolBars struct {
+	open          []*ports.Candle
+	closedThrough time.Time
+	watermark     time.Time
+}
+
+// TickAggregator builds OHLCV candles from ticks. Candle volume sums tick
+// sizes, counting ticks without a size as one, and Spread keeps the last
+// quoted spread. It is not safe for concurrent
+// use.
+type TickAggregator struct {
+	cfg  TickAggregatorConfig
+	bars map[string]*symbolBars
+}
+
+// NewTickAggregator validates cfg and returns a TickAggregator.
+func NewTickAggregator(cfg TickAggregatorConfig) (*TickAggregator, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &TickAggregator{cfg: cfg, bars: make(map[string]*symbolBars)}, nil
+}
+
+// boundary returns the start of the bar containing t.
+func (a *TickAggregator) boundary(t time.Time) time.Time {
+	return t.Add(-a.cfg.Offset).Truncate(a.cfg.Interval).Add(a.cfg.Offset)
+}
+
+// Add applies t and returns the candles it closed. A bar closes once a tick
+// at or beyond its end plus Grace has been seen. It returns ErrInvalidTick for
+// ticks without symbol, time or price and ErrLateTick for dropped late ticks.
+func (a *TickAggregator) Add(t ports.Tick) ([]ports.Candle, error) {
+	price := t.Price()
+	if t.Symbol == "" || t.Time.IsZero() || price <= 0 {
+		return nil, ErrInvalidTick
+	}
+	sb, ok := a.bars[t.Symbol]
+	if !ok {
+		sb = &symbolBars{}
+		a.bars[t.Symbol] = sb
+	}
+
+	var bar *ports.Candle
+	late := t.Time.Before(sb.closedThrough)
+	if late {
+		if a.cfg.Late == LateTickDrop || len(sb.open) == 0 {
+			return nil, ErrLateTick
+		}
+		bar = sb.open[0]
+	} else {
+		bar = sb.barAt(t.Symbol, a.boundary(t.Time))
+	}
+
+	// Open bars always hold a tick, so a merged late tick, which predates
+	// them, only widens the range and never sets Open or Close.
+	if bar.Volume == 0 {
+		bar.Open, bar.High, bar.Low = price, price, price
+	}
+	bar.High = max(bar.High, price)
+	bar.Low = min(bar.Low, price)
+	if !late {
+		bar.Close = price
+	}
+	if s := t.Spread(); s > 0 {
+		bar.Spread = s
+	}
+	if t.Volume > 0 {
+		bar.Volume += t.Volume
+	} else {
+		bar.Volume++
+	}
+
+	if t.Time.After(sb.watermark) {
+		sb.watermark = t.Time
+	}
+	return a.closeDue(sb, sb.watermark), nil
+}
+
+// Forming returns the most recent open bar for symbol, marked Partial.
+func (a *TickAggregator) Forming(symbol string) (ports.Candle, bool) {
+	sb, ok := a.bars[symbol]
+	if !ok || len(sb.open) == 0 {
+		return ports.Candle{}, false
+	}
+	c := *sb.open[len(sb.open)-1]
+	c.Partial = true
+	return c, true
+}
+
+// barAt returns the open bar starting at start, creating it if needed.
+func (sb *symbolBars) barAt(symbol string, start time.Time) *ports.Candle {
+	i := sort.Search(len(sb.open), func(i int) bool { return !sb.open[i].Time.Before(start) })
+	if i < len(sb.open) && sb.open[i].Time.Equal(start) {
+		return sb.open[i]
+	}
+	bar := &ports.Candle{Symbol: symbol, Time: start}
+	sb.open = append(sb.open, nil)
+	copy(sb.open[i+1:], sb.open[i:])
+	sb.open[i] = bar
+	return bar
+}
+
+func (a *TickAggregator) closeDue(sb *symbolBars, now time.Time) []ports.Candle {
+	var closed []ports.Candle
+	for len(sb.open) > 0 {
+		end := sb.open[0].Time.Add(a.cfg.Interval)
+		if end.Add(a.cfg.Grace).After(now) {
+			break
+		}
+		closed = append(closed, *sb.open[0])
+		sb.closedThrough = end
+		sb.open = sb.open[1:]
+	}
+	return closed
+}
+
+// Flush closes every bar whose end plus Grace is at or before now, ordered by
+// symbol and time.
+func (a *TickAggregator) Flush(now time.Time) []ports.Candle {
+	var closed []ports.Candle
+	for _, sym := range a.symbols() {
+		closed = append(closed, a.closeDue(a.bars[sym], now)...)
+	}
+	return closed
+}
+
+// FlushAll closes every open bar regardless of time, ordered by symbol and
+// time.
+func (a *TickAggregator) FlushAll() []ports.Candle {
+	var closed []ports.Candle
+	for _, sym := range a.symbols() {
+		sb := a.bars[sym]
+		for _, bar := range sb.open {
+			closed = append(closed, *bar)
+			sb.closedThrough = bar.Time.Add(a.cfg.Interval)
+		}
+		sb.open = nil
+	}
+	return closed
+}
+
+func (a *TickAggregator) symbols() []string {
+	syms := make([]string, 0, len(a.bars))
+	for s := range a.bars {
+		syms = append(syms, s)
+	}
+	sort.Strings(syms)
+	return syms
+}
+
+// TickCandleFeed implements ports.MarketFeedPort by aggregating a tick feed
+// into candles.
+type TickCandleFeed struct {
+	ticks  ports.TickFeedPort
+	cfg    TickAggregatorConfig
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewTickCandleFeed returns a TickCandleFeed over ticks using cfg.
+func NewTickCandleFeed(ticks ports.TickFeedPort, cfg TickAggregatorConfig, logger *slog.Logger) (*TickCandleFeed, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &TickCandleFeed{ticks: ticks, cfg: cfg, logger: logger, now: time.Now}, nil
+}
+
+// StreamCandles streams ticks for symbols and emits each candle once it
+// closes, preceded by Partial updates when EmitPartial is set. Open bars are
+// flushed when the tick stream ends.
+func (f *TickCandleFeed) StreamCandles(ctx context.Context, symbols []string) (<-chan ports.Candle, error) {
+	agg, err := NewTickAggregator(f.cfg)
+	if err != nil {
+		return nil, err
+	}
+	in, err := f.ticks.StreamTicks(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan ports.Candle)
+	go func() {
+		defer close(out)
+		flush := time.NewTicker(f.cfg.FlushInterval)
+		defer flush.Stop()
+
+		emit := func(candles []ports.Candle) bool {
+			for _, c := range candles {
+				select {
+				case out <- c:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-flush.C:
+				if !emit(agg.Flush(f.now())) {
+					return
+				}
+			case t, ok := <-in:
+				if !ok {
+					emit(agg.FlushAll())
+					return
+				}
+				closed, err := agg.Add(t)
+				if err != nil {
+					f.logger.DebugContext(ctx, "tick skipped", "symbol", t.Symbol, "time", t.Time, "error", err)
+					continue
+				}
+				if !emit(closed) {
+					return
+				}
+				if !f.cfg.EmitPartial {
+					continue
+				}
+				if c, ok := agg.Forming(t.Symbol); ok && !emit([]ports.Candle{c}) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+var (
+	_ ports.CandleBuilder  = (*TickAggregator)(nil)
+	_ ports.MarketFeedPort = (*TickCandleFeed)(nil)
+)
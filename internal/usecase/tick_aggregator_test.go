@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nomenarkt/signalengine/internal/ports"
+	"github.com/nomenarkt/signalengine/internal/testutils"
+)
+
+func TestTickAggregator(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	tick := func(sec int, price float64) ports.Tick {
+		return ports.Tick{Symbol: "EURUSD", Time: base.Add(time.Duration(sec) * time.Second), Last: price}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     TickAggregatorConfig
+		ticks   []ports.Tick
+		want    []ports.Candle
+		lateErr int
+	}{
+		{
+			name:  "minute bars",
+			ticks: []ports.Tick{tick(0, 1.0), tick(20, 1.3), tick(40, 0.9), tick(59, 1.1), tick(60, 1.2)},
+			want:  []ports.Candle{{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.3, Low: 0.9, Close: 1.1, Volume: 4}},
+		},
+		{
+			name:  "offset boundary",
+			cfg:   TickAggregatorConfig{Offset: 30 * time.Second},
+			ticks: []ports.Tick{tick(0, 1.0), tick(29, 1.1), tick(30, 1.2), tick(90, 1.3)},
+			want: []ports.Candle{
+				{Symbol: "EURUSD", Time: base.Add(-30 * time.Second), Open: 1.0, High: 1.1, Low: 1.0, Close: 1.1, Volume: 2},
+				{Symbol: "EURUSD", Time: base.Add(30 * time.Second), Open: 1.2, High: 1.2, Low: 1.2, Close: 1.2, Volume: 1},
+			},
+		},
+		{
+			name:  "grace accepts late tick",
+			cfg:   TickAggregatorConfig{Grace: 5 * time.Second},
+			ticks: []ports.Tick{tick(0, 1.0), tick(61, 1.2), tick(59, 1.4), tick(65, 1.3)},
+			want:  []ports.Candle{{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.4, Low: 1.0, Close: 1.4, Volume: 2}},
+		},
+		{
+			name:    "drop late tick",
+			ticks:   []ports.Tick{tick(0, 1.0), tick(61, 1.2), tick(59, 1.4), tick(120, 1.3)},
+			lateErr: 1,
+			want: []ports.Candle{
+				{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0, Volume: 1},
+				{Symbol: "EURUSD", Time: base.Add(time.Minute), Open: 1.2, High: 1.2, Low: 1.2, Close: 1.2, Volume: 1},
+			},
+		},
+		{
+			name:  "merge late tick",
+			cfg:   TickAggregatorConfig{Late: LateTickMerge},
+			ticks: []ports.Tick{tick(0, 1.0), tick(61, 1.2), tick(59, 1.4), tick(120, 1.3)},
+			want: []ports.Candle{
+				{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0, Volume: 1},
+				{Symbol: "EURUSD", Time: base.Add(time.Minute), Open: 1.2, High: 1.4, Low: 1.2, Close: 1.2, Volume: 2},
+			},
+		},
+		{
+			name:  "merge late tick after newer ticks",
+			cfg:   TickAggregatorConfig{Late: LateTickMerge},
+			ticks: []ports.Tick{tick(0, 1.0), tick(61, 1.2), tick(70, 1.25), tick(59, 1.1), tick(120, 1.3)},
+			want: []ports.Candle{
+				{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0, Volume: 1},
+				{Symbol: "EURUSD", Time: base.Add(time.Minute), Open: 1.2, High: 1.25, Low: 1.1, Close: 1.25, Volume: 3},
+			},
+		},
+		{
+			name:  "tick volume and spread",
+			ticks: []ports.Tick{{Symbol: "EURUSD", Time: base, Bid: 1.0, Ask: 1.25, Volume: 3}, {Symbol: "EURUSD", Time: base.Add(time.Minute), Bid: 1, Ask: 1}},
+			want:  []ports.Candle{{Symbol: "EURUSD", Time: base, Open: 1.125, High: 1.125, Low: 1.125, Close: 1.125, Volume: 3, Spread: 0.25}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			agg, err := NewTickAggregator(tt.cfg)
+			if err != nil {
+				t.Fatalf("new aggregator: %v", err)
+			}
+			var got []ports.Candle
+			late := 0
+			for _, tk := range tt.ticks {
+				closed, err := agg.Add(tk)
+				if errors.Is(err, ErrLateTick) {
+					late++
+					continue
+				}
+				if err != nil {
+					t.Fatalf("add: %v", err)
+				}
+				got = append(got, closed...)
+			}
+			if late != tt.lateErr {
+				t.Fatalf("expected %d late ticks, got %d", tt.lateErr, late)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTickAggregator_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  TickAggregatorConfig
+	}{
+		{name: "negative interval", cfg: TickAggregatorConfig{Interval: -time.Minute}},
+		{name: "offset too large", cfg: TickAggregatorConfig{Offset: time.Minute}},
+		{name: "grace too large", cfg: TickAggregatorConfig{Grace: 2 * time.Minute}},
+		{name: "unknown policy", cfg: TickAggregatorConfig{Late: LateTickPolicy(9)}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTickAggregator(tt.cfg); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+
+	agg, _ := NewTickAggregator(TickAggregatorConfig{})
+	if _, err := agg.Add(ports.Tick{Symbol: "EURUSD", Time: time.Now()}); !errors.Is(err, ErrInvalidTick) {
+		t.Fatalf("expected invalid tick error, got %v", err)
+	}
+}
+
+func TestTickCandleFeed(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	feed := &testutils.MockTickFeed{Ticks: []ports.Tick{
+		{Symbol: "EURUSD", Time: base, Last: 1.0},
+		{Symbol: "GBPUSD", Time: base.Add(10 * time.Second), Last: 1.3},
+		{Symbol: "EURUSD", Time: base.Add(70 * time.Second), Last: 1.1},
+	}}
+
+	f, err := NewTickCandleFeed(feed, TickAggregatorConfig{}, nil)
+	if err != nil {
+		t.Fatalf("new feed: %v", err)
+	}
+	f.now = func() time.Time { return base }
+
+	ch, err := f.StreamCandles(context.Background(), []string{"EURUSD", "GBPUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var got []string
+	for c := range ch {
+		got = append(got, c.Symbol+"@"+c.Time.Format("15:04"))
+	}
+	want := []string{"EURUSD@03:04", "EURUSD@03:05", "GBPUSD@03:04"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTickCandleFeed_EmitPartial(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	feed := &testutils.MockTickFeed{Ticks: []ports.Tick{
+		{Symbol: "EURUSD", Time: base, Last: 1.0},
+		{Symbol: "EURUSD", Time: base.Add(20 * time.Second), Last: 1.2},
+		{Symbol: "EURUSD", Time: base.Add(60 * time.Second), Last: 1.1},
+	}}
+
+	f, err := NewTickCandleFeed(feed, TickAggregatorConfig{EmitPartial: true}, nil)
+	if err != nil {
+		t.Fatalf("new feed: %v", err)
+	}
+	f.now = func() time.Time { return base }
+
+	ch, err := f.StreamCandles(context.Background(), []string{"EURUSD"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var got []ports.Candle
+	for c := range ch {
+		got = append(got, c)
+	}
+	want := []ports.Candle{
+		{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0, Volume: 1, Partial: true},
+		{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.2, Low: 1.0, Close: 1.2, Volume: 2, Partial: true},
+		{Symbol: "EURUSD", Time: base, Open: 1.0, High: 1.2, Low: 1.0, Close: 1.2, Volume: 2},
+		{Symbol: "EURUSD", Time: base.Add(time.Minute), Open: 1.1, High: 1.1, Low: 1.1, Close: 1.1, Volume: 1, Partial: true},
+		{Symbol: "EURUSD", Time: base.Add(time.Minute), Open: 1.1, High: 1.1, Low: 1.1, Close: 1.1, Volume: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}